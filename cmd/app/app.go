@@ -1,8 +1,10 @@
 package main
 
 import (
+	"github.com/aerosystems/checkmail-service/internal/adapters"
 	GRPCServer "github.com/aerosystems/checkmail-service/internal/ports/grpc"
 	HTTPServer "github.com/aerosystems/checkmail-service/internal/ports/http"
+	"github.com/aerosystems/checkmail-service/internal/usecases"
 	"github.com/sirupsen/logrus"
 )
 
@@ -11,6 +13,11 @@ type App struct {
 	cfg        *Config
 	httpServer *HTTPServer.Server
 	grpcServer *GRPCServer.Server
+	domainRepo *adapters.DomainMemRepo
+
+	disposableRepo *adapters.DisposableRepo
+	expirySweeper  *usecases.ExpirySweeper
+	trashPurger    *usecases.TrashPurger
 }
 
 func NewApp(
@@ -18,11 +25,20 @@ func NewApp(
 	cfg *Config,
 	httpServer *HTTPServer.Server,
 	grpcServer *GRPCServer.Server,
+	domainRepo *adapters.DomainMemRepo,
+	disposableRepo *adapters.DisposableRepo,
+	expirySweeper *usecases.ExpirySweeper,
+	trashPurger *usecases.TrashPurger,
 ) *App {
 	return &App{
 		log:        log,
 		cfg:        cfg,
 		httpServer: httpServer,
 		grpcServer: grpcServer,
+		domainRepo: domainRepo,
+
+		disposableRepo: disposableRepo,
+		expirySweeper:  expirySweeper,
+		trashPurger:    trashPurger,
 	}
 }
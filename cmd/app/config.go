@@ -2,12 +2,41 @@ package main
 
 import (
 	"github.com/spf13/viper"
+	"time"
 )
 
 const (
 	defaultMode  = "prod"
 	defaultPort  = "8080"
 	defaultProto = "http"
+
+	defaultMatchTieBreak             = "blacklist"
+	defaultDomainIndexReloadInterval = 5 * time.Minute
+	defaultProviderCacheTTL          = 5 * time.Minute
+	defaultPatternMatchBudget        = 50 * time.Millisecond
+	defaultVerdictCacheSize          = 100000
+	defaultVerdictCacheTTL           = 5 * time.Minute
+	defaultDomainCountCacheTTL       = time.Minute
+	defaultRuleExpirySweepInterval   = time.Minute
+	defaultDomainTrashRetention      = 30 * 24 * time.Hour
+	defaultDomainTrashPurgeInterval  = time.Hour
+	defaultImportBatchSize           = 1000
+	defaultImportMaxRows             = 100000
+	defaultInspectBatchMaxSize       = 1000
+	defaultInspectBatchConcurrency   = 16
+	defaultInspectStreamQuotaLease   = 100
+	defaultInspectStreamConcurrency  = 16
+	defaultDisposableReloadInterval  = time.Hour
+	defaultDisposableMXCheckEnabled  = false
+	defaultDNSCheckEnabled           = false
+	defaultDNSTimeout                = 2 * time.Second
+	defaultDNSCacheMaxTTL            = time.Hour
+	defaultDNSCacheSize              = 100000
+	defaultLookupTimeout             = 500 * time.Millisecond
+	defaultLookupRetries             = 2
+	defaultLookupRetryBackoff        = 50 * time.Millisecond
+	defaultLookupBreakerThreshold    = 5
+	defaultLookupBreakerCooldown     = 30 * time.Second
 )
 
 type Config struct {
@@ -18,6 +47,37 @@ type Config struct {
 	GcpProjectId                 string
 	GoogleApplicationCredentials string
 	PostgresDSN                  string
+	MatchTieBreak                string
+	DomainIndexReloadInterval    time.Duration
+	ProviderCacheTTL             time.Duration
+	PatternMatchBudget           time.Duration
+	VerdictCacheSize             int
+	VerdictCacheTTL              time.Duration
+	DomainCountCacheTTL          time.Duration
+	RuleExpirySweepInterval      time.Duration
+	DomainTrashRetention         time.Duration
+	DomainTrashPurgeInterval     time.Duration
+	ImportBatchSize              int
+	ImportMaxRows                int
+	InspectBatchMaxSize          int
+	InspectBatchConcurrency      int
+	InspectStreamQuotaLease      int
+	InspectStreamConcurrency     int
+	DisposableDomainsPath        string
+	DisposableMXHostsPath        string
+	DisposableReloadInterval     time.Duration
+	DisposableMXCheckEnabled     bool
+	DNSCheckEnabled              bool
+	DNSServer                    string
+	DNSTimeout                   time.Duration
+	DNSCacheMaxTTL               time.Duration
+	DNSCacheSize                 int
+	LookupServiceAddr            string
+	LookupTimeout                time.Duration
+	LookupRetries                int
+	LookupRetryBackoff           time.Duration
+	LookupBreakerThreshold       int
+	LookupBreakerCooldown        time.Duration
 }
 
 func NewConfig() *Config {
@@ -25,6 +85,33 @@ func NewConfig() *Config {
 	viper.SetDefault("MODE", defaultMode)
 	viper.SetDefault("PORT", defaultPort)
 	viper.SetDefault("PROTO", defaultProto)
+	viper.SetDefault("MATCH_TIE_BREAK", defaultMatchTieBreak)
+	viper.SetDefault("DOMAIN_INDEX_RELOAD_INTERVAL", defaultDomainIndexReloadInterval)
+	viper.SetDefault("PROVIDER_CACHE_TTL", defaultProviderCacheTTL)
+	viper.SetDefault("PATTERN_MATCH_BUDGET", defaultPatternMatchBudget)
+	viper.SetDefault("VERDICT_CACHE_SIZE", defaultVerdictCacheSize)
+	viper.SetDefault("VERDICT_CACHE_TTL", defaultVerdictCacheTTL)
+	viper.SetDefault("DOMAIN_COUNT_CACHE_TTL", defaultDomainCountCacheTTL)
+	viper.SetDefault("RULE_EXPIRY_SWEEP_INTERVAL", defaultRuleExpirySweepInterval)
+	viper.SetDefault("DOMAIN_TRASH_RETENTION", defaultDomainTrashRetention)
+	viper.SetDefault("DOMAIN_TRASH_PURGE_INTERVAL", defaultDomainTrashPurgeInterval)
+	viper.SetDefault("IMPORT_BATCH_SIZE", defaultImportBatchSize)
+	viper.SetDefault("IMPORT_MAX_ROWS", defaultImportMaxRows)
+	viper.SetDefault("INSPECT_BATCH_MAX_SIZE", defaultInspectBatchMaxSize)
+	viper.SetDefault("INSPECT_BATCH_CONCURRENCY", defaultInspectBatchConcurrency)
+	viper.SetDefault("INSPECT_STREAM_QUOTA_LEASE", defaultInspectStreamQuotaLease)
+	viper.SetDefault("INSPECT_STREAM_CONCURRENCY", defaultInspectStreamConcurrency)
+	viper.SetDefault("DISPOSABLE_RELOAD_INTERVAL", defaultDisposableReloadInterval)
+	viper.SetDefault("DISPOSABLE_MX_CHECK_ENABLED", defaultDisposableMXCheckEnabled)
+	viper.SetDefault("DNS_CHECK_ENABLED", defaultDNSCheckEnabled)
+	viper.SetDefault("DNS_TIMEOUT", defaultDNSTimeout)
+	viper.SetDefault("DNS_CACHE_MAX_TTL", defaultDNSCacheMaxTTL)
+	viper.SetDefault("DNS_CACHE_SIZE", defaultDNSCacheSize)
+	viper.SetDefault("LOOKUP_TIMEOUT", defaultLookupTimeout)
+	viper.SetDefault("LOOKUP_RETRIES", defaultLookupRetries)
+	viper.SetDefault("LOOKUP_RETRY_BACKOFF", defaultLookupRetryBackoff)
+	viper.SetDefault("LOOKUP_BREAKER_THRESHOLD", defaultLookupBreakerThreshold)
+	viper.SetDefault("LOOKUP_BREAKER_COOLDOWN", defaultLookupBreakerCooldown)
 
 	return &Config{
 		Mode:                         viper.GetString("MODE"),
@@ -34,5 +121,36 @@ func NewConfig() *Config {
 		GcpProjectId:                 viper.GetString("GCP_PROJECT_ID"),
 		GoogleApplicationCredentials: viper.GetString("GOOGLE_APPLICATION_CREDENTIALS"),
 		PostgresDSN:                  viper.GetString("POSTGRES_DSN"),
+		MatchTieBreak:                viper.GetString("MATCH_TIE_BREAK"),
+		DomainIndexReloadInterval:    viper.GetDuration("DOMAIN_INDEX_RELOAD_INTERVAL"),
+		ProviderCacheTTL:             viper.GetDuration("PROVIDER_CACHE_TTL"),
+		PatternMatchBudget:           viper.GetDuration("PATTERN_MATCH_BUDGET"),
+		VerdictCacheSize:             viper.GetInt("VERDICT_CACHE_SIZE"),
+		VerdictCacheTTL:              viper.GetDuration("VERDICT_CACHE_TTL"),
+		DomainCountCacheTTL:          viper.GetDuration("DOMAIN_COUNT_CACHE_TTL"),
+		RuleExpirySweepInterval:      viper.GetDuration("RULE_EXPIRY_SWEEP_INTERVAL"),
+		DomainTrashRetention:         viper.GetDuration("DOMAIN_TRASH_RETENTION"),
+		DomainTrashPurgeInterval:     viper.GetDuration("DOMAIN_TRASH_PURGE_INTERVAL"),
+		ImportBatchSize:              viper.GetInt("IMPORT_BATCH_SIZE"),
+		ImportMaxRows:                viper.GetInt("IMPORT_MAX_ROWS"),
+		InspectBatchMaxSize:          viper.GetInt("INSPECT_BATCH_MAX_SIZE"),
+		InspectBatchConcurrency:      viper.GetInt("INSPECT_BATCH_CONCURRENCY"),
+		InspectStreamQuotaLease:      viper.GetInt("INSPECT_STREAM_QUOTA_LEASE"),
+		InspectStreamConcurrency:     viper.GetInt("INSPECT_STREAM_CONCURRENCY"),
+		DisposableDomainsPath:        viper.GetString("DISPOSABLE_DOMAINS_PATH"),
+		DisposableMXHostsPath:        viper.GetString("DISPOSABLE_MX_HOSTS_PATH"),
+		DisposableReloadInterval:     viper.GetDuration("DISPOSABLE_RELOAD_INTERVAL"),
+		DisposableMXCheckEnabled:     viper.GetBool("DISPOSABLE_MX_CHECK_ENABLED"),
+		DNSCheckEnabled:              viper.GetBool("DNS_CHECK_ENABLED"),
+		DNSServer:                    viper.GetString("DNS_SERVER"),
+		DNSTimeout:                   viper.GetDuration("DNS_TIMEOUT"),
+		DNSCacheMaxTTL:               viper.GetDuration("DNS_CACHE_MAX_TTL"),
+		DNSCacheSize:                 viper.GetInt("DNS_CACHE_SIZE"),
+		LookupServiceAddr:            viper.GetString("LOOKUP_SERVICE_ADDR"),
+		LookupTimeout:                viper.GetDuration("LOOKUP_TIMEOUT"),
+		LookupRetries:                viper.GetInt("LOOKUP_RETRIES"),
+		LookupRetryBackoff:           viper.GetDuration("LOOKUP_RETRY_BACKOFF"),
+		LookupBreakerThreshold:       viper.GetInt("LOOKUP_BREAKER_THRESHOLD"),
+		LookupBreakerCooldown:        viper.GetDuration("LOOKUP_BREAKER_COOLDOWN"),
 	}
 }
@@ -26,7 +26,8 @@ import (
 // @schemes https
 // @BasePath /
 func main() {
-	app := InitApp()
+	app, cleanup := InitApp()
+	defer cleanup()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -46,6 +47,22 @@ func main() {
 		app.log.Fatalf("unknown protocol: %s", app.cfg.Proto)
 	}
 
+	group.Go(func() error {
+		return app.domainRepo.Run(ctx)
+	})
+
+	group.Go(func() error {
+		return app.disposableRepo.Run(ctx)
+	})
+
+	group.Go(func() error {
+		return app.expirySweeper.Run(ctx)
+	})
+
+	group.Go(func() error {
+		return app.trashPurger.Run(ctx)
+	})
+
 	group.Go(func() error {
 		return app.handleSignals(ctx, cancel)
 	})
@@ -5,6 +5,7 @@ package main
 
 import (
 	"github.com/aerosystems/checkmail-service/internal/adapters"
+	"github.com/aerosystems/checkmail-service/internal/models"
 	GRPCServer "github.com/aerosystems/checkmail-service/internal/ports/grpc"
 	HTTPServer "github.com/aerosystems/checkmail-service/internal/ports/http"
 	"github.com/aerosystems/checkmail-service/internal/usecases"
@@ -22,17 +23,22 @@ import (
 )
 
 //go:generate wire
-func InitApp() *App {
+func InitApp() (*App, func()) {
 	panic(wire.Build(
 		wire.Bind(new(GRPCServer.InspectUsecase), new(*usecases.InspectUsecase)),
+		wire.Bind(new(GRPCServer.ManageUsecase), new(*usecases.ManageUsecase)),
 		wire.Bind(new(HTTPServer.AccessUsecase), new(*usecases.AccessUsecase)),
 		wire.Bind(new(HTTPServer.ManageUsecase), new(*usecases.ManageUsecase)),
 		wire.Bind(new(HTTPServer.InspectUsecase), new(*usecases.InspectUsecase)),
 		wire.Bind(new(HTTPServer.ReviewUsecase), new(*usecases.ReviewUsecase)),
-		wire.Bind(new(usecases.DomainRepository), new(*adapters.DomainRepo)),
+		wire.Bind(new(usecases.DomainRepository), new(*adapters.DomainMemRepo)),
 		wire.Bind(new(usecases.FilterRepository), new(*adapters.FilterRepo)),
+		wire.Bind(new(usecases.ProviderRepository), new(*adapters.ProviderRepo)),
+		wire.Bind(new(usecases.DisposableRepository), new(*adapters.DisposableRepo)),
 		wire.Bind(new(usecases.AccessRepository), new(*adapters.AccessRepo)),
 		wire.Bind(new(usecases.ReviewRepository), new(*adapters.ReviewRepo)),
+		wire.Bind(new(usecases.AuditRepository), new(*adapters.AuditRepo)),
+		wire.Bind(new(usecases.Transactor), new(*adapters.Transactor)),
 		ProvideApp,
 		ProvideLogger,
 		ProvideConfig,
@@ -43,7 +49,13 @@ func InitApp() *App {
 		ProvideManageUsecase,
 		ProvideInspectUsecase,
 		ProvideDomainRepo,
+		ProvideDomainMemRepo,
 		ProvideFilterRepo,
+		ProvideProviderRepo,
+		ProvideDisposableRepo,
+		ProvideDNSResolver,
+		ProvideLookupService,
+		ProvideVerdictCache,
 		ProvideAccessUsecase,
 		ProvideAccessRepo,
 		ProvideFirebaseAuthClient,
@@ -52,10 +64,14 @@ func InitApp() *App {
 		ProvideGRPCServer,
 		ProvideReviewUsecase,
 		ProvideReviewRepo,
+		ProvideAuditRepo,
+		ProvideTransactor,
+		ProvideExpirySweeper,
+		ProvideTrashPurger,
 	))
 }
 
-func ProvideApp(log *logrus.Logger, cfg *Config, httpServer *HTTPServer.Server, grpcServer *GRPCServer.Server) *App {
+func ProvideApp(log *logrus.Logger, cfg *Config, httpServer *HTTPServer.Server, grpcServer *GRPCServer.Server, domainRepo *adapters.DomainMemRepo, disposableRepo *adapters.DisposableRepo, expirySweeper *usecases.ExpirySweeper, trashPurger *usecases.TrashPurger) *App {
 	panic(wire.Build(NewApp))
 }
 
@@ -109,32 +125,94 @@ func ProvideGRPCServer(log *logrus.Logger, cfg *Config, checkHandler *GRPCServer
 	return GRPCServer.NewGRPCServer(&grpcserver.Config{Host: cfg.Host, Port: cfg.Port}, log, checkHandler)
 }
 
-func ProvideGRPCCheckService(inspectUsecase GRPCServer.InspectUsecase) *GRPCServer.CheckService {
-	panic(wire.Build(GRPCServer.NewCheckService))
+func ProvideGRPCCheckService(cfg *Config, inspectUsecase GRPCServer.InspectUsecase, manageUsecase GRPCServer.ManageUsecase, client *auth.Client) *GRPCServer.CheckService {
+	return GRPCServer.NewCheckService(inspectUsecase, manageUsecase, client, cfg.InspectStreamConcurrency)
 }
 
 func ProvideDomainRepo(db *gorm.DB) *adapters.DomainRepo {
 	panic(wire.Build(adapters.NewDomainRepo))
 }
 
+func ProvideDomainMemRepo(log *logrus.Logger, cfg *Config, domainRepo *adapters.DomainRepo) *adapters.DomainMemRepo {
+	return adapters.NewDomainMemRepo(log, domainRepo, cfg.DomainIndexReloadInterval)
+}
+
 func ProvideFilterRepo(db *gorm.DB) *adapters.FilterRepo {
 	panic(wire.Build(adapters.NewFilterRepo))
 }
 
+func ProvideProviderRepo(db *gorm.DB, cfg *Config) *adapters.ProviderRepo {
+	return adapters.NewProviderRepo(db, cfg.ProviderCacheTTL)
+}
+
+func ProvideDisposableRepo(log *logrus.Logger, cfg *Config) *adapters.DisposableRepo {
+	return adapters.NewDisposableRepo(log, cfg.DisposableDomainsPath, cfg.DisposableMXHostsPath, cfg.DisposableReloadInterval)
+}
+
+func ProvideDNSResolver(cfg *Config) *adapters.DNSCache {
+	return adapters.NewDNSCache(adapters.NewDNSResolver(cfg.DNSServer, cfg.DNSTimeout), cfg.DNSCacheMaxTTL, cfg.DNSCacheSize)
+}
+
+func ProvideLookupService(log *logrus.Logger, cfg *Config) (usecases.LookupService, func()) {
+	if cfg.LookupServiceAddr == "" {
+		return nil, func() {}
+	}
+	conn, err := adapters.NewLookupClientConn(cfg.LookupServiceAddr)
+	if err != nil {
+		panic(err)
+	}
+	cleanup := func() {
+		if err := conn.Close(); err != nil {
+			log.Errorf("could not close lookup service connection: %v", err)
+		}
+	}
+	return adapters.NewLookupAdapter(conn, cfg.LookupTimeout, cfg.LookupRetries, cfg.LookupRetryBackoff, cfg.LookupBreakerThreshold, cfg.LookupBreakerCooldown), cleanup
+}
+
+func ProvideVerdictCache(cfg *Config) usecases.VerdictCache {
+	if cfg.VerdictCacheSize <= 0 || cfg.VerdictCacheTTL <= 0 {
+		return nil
+	}
+	return adapters.NewVerdictLRU(cfg.VerdictCacheTTL, cfg.VerdictCacheSize)
+}
+
 func ProvideReviewRepo(db *gorm.DB) *adapters.ReviewRepo {
 	panic(wire.Build(adapters.NewReviewRepo))
 }
 
+func ProvideAuditRepo(db *gorm.DB) *adapters.AuditRepo {
+	panic(wire.Build(adapters.NewAuditRepo))
+}
+
+func ProvideTransactor(db *gorm.DB) *adapters.Transactor {
+	panic(wire.Build(adapters.NewTransactor))
+}
+
 func ProvideAccessRepo(db *gorm.DB) *adapters.AccessRepo {
 	panic(wire.Build(adapters.NewAccessRepo))
 }
 
-func ProvideManageUsecase(domainRepo usecases.DomainRepository, filterRepo usecases.FilterRepository) *usecases.ManageUsecase {
-	panic(wire.Build(usecases.NewManageUsecase))
+func ProvideManageUsecase(cfg *Config, accessRepo usecases.AccessRepository, domainRepo usecases.DomainRepository, filterRepo usecases.FilterRepository, providerRepo usecases.ProviderRepository, auditRepo usecases.AuditRepository, transactor usecases.Transactor, verdictCache usecases.VerdictCache) *usecases.ManageUsecase {
+	return usecases.NewManageUsecase(accessRepo, domainRepo, filterRepo, providerRepo, auditRepo, transactor, verdictCache, cfg.DomainCountCacheTTL, cfg.ImportBatchSize, cfg.ImportMaxRows)
+}
+
+func ProvideExpirySweeper(log *logrus.Logger, cfg *Config, manageUsecase *usecases.ManageUsecase) *usecases.ExpirySweeper {
+	return usecases.NewExpirySweeper(log, manageUsecase, cfg.RuleExpirySweepInterval)
 }
 
-func ProvideInspectUsecase(log *logrus.Logger, accessRepo usecases.AccessRepository, domainRepo usecases.DomainRepository, filterRepo usecases.FilterRepository) *usecases.InspectUsecase {
-	panic(wire.Build(usecases.NewInspectUsecase))
+func ProvideTrashPurger(log *logrus.Logger, cfg *Config, manageUsecase *usecases.ManageUsecase) *usecases.TrashPurger {
+	return usecases.NewTrashPurger(log, manageUsecase, cfg.DomainTrashRetention, cfg.DomainTrashPurgeInterval)
+}
+
+func ProvideInspectUsecase(log *logrus.Logger, cfg *Config, accessRepo usecases.AccessRepository, domainRepo usecases.DomainRepository, filterRepo usecases.FilterRepository, providerRepo usecases.ProviderRepository, disposableRepo usecases.DisposableRepository, dnsResolver *adapters.DNSCache, lookupService usecases.LookupService, verdictCache usecases.VerdictCache) *usecases.InspectUsecase {
+	var dnsCheckResolver, mxCheckResolver usecases.DNSResolver
+	if cfg.DNSCheckEnabled {
+		dnsCheckResolver = dnsResolver
+	}
+	if cfg.DisposableMXCheckEnabled {
+		mxCheckResolver = dnsResolver
+	}
+	return usecases.NewInspectUsecase(log, accessRepo, domainRepo, filterRepo, providerRepo, disposableRepo, dnsCheckResolver, mxCheckResolver, lookupService, verdictCache, models.TieBreakFromString(cfg.MatchTieBreak), cfg.PatternMatchBudget, cfg.InspectBatchMaxSize, cfg.InspectBatchConcurrency, cfg.InspectStreamQuotaLease)
 }
 
 func ProvideReviewUsecase(domainReviewRepo usecases.ReviewRepository) *usecases.ReviewUsecase {
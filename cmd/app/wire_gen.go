@@ -9,6 +9,7 @@ package main
 import (
 	"firebase.google.com/go/v4/auth"
 	"github.com/aerosystems/checkmail-service/internal/adapters"
+	"github.com/aerosystems/checkmail-service/internal/models"
 	"github.com/aerosystems/checkmail-service/internal/ports/grpc"
 	"github.com/aerosystems/checkmail-service/internal/ports/http"
 	"github.com/aerosystems/checkmail-service/internal/usecases"
@@ -24,7 +25,7 @@ import (
 // Injectors from wire.go:
 
 //go:generate wire
-func InitApp() *App {
+func InitApp() (*App, func()) {
 	logger := ProvideLogger()
 	logrusLogger := ProvideLogrusLogger(logger)
 	config := ProvideConfig()
@@ -34,21 +35,33 @@ func InitApp() *App {
 	accessRepo := ProvideAccessRepo(db)
 	accessUsecase := ProvideAccessUsecase(accessRepo)
 	domainRepo := ProvideDomainRepo(db)
+	domainMemRepo := ProvideDomainMemRepo(logrusLogger, config, domainRepo)
 	filterRepo := ProvideFilterRepo(db)
-	manageUsecase := ProvideManageUsecase(domainRepo, filterRepo)
-	inspectUsecase := ProvideInspectUsecase(logrusLogger, accessRepo, domainRepo, filterRepo)
+	providerRepo := ProvideProviderRepo(db, config)
+	auditRepo := ProvideAuditRepo(db)
+	transactor := ProvideTransactor(db)
+	verdictCache := ProvideVerdictCache(config)
+	manageUsecase := ProvideManageUsecase(config, accessRepo, domainMemRepo, filterRepo, providerRepo, auditRepo, transactor, verdictCache)
+	disposableRepo := ProvideDisposableRepo(logrusLogger, config)
+	dnsCache := ProvideDNSResolver(config)
+	lookupService, cleanup := ProvideLookupService(logrusLogger, config)
+	inspectUsecase := ProvideInspectUsecase(logrusLogger, config, accessRepo, domainMemRepo, filterRepo, providerRepo, disposableRepo, dnsCache, lookupService, verdictCache)
 	reviewRepo := ProvideReviewRepo(db)
 	reviewUsecase := ProvideReviewUsecase(reviewRepo)
 	handler := ProvideHandler(accessUsecase, manageUsecase, inspectUsecase, reviewUsecase)
 	server := ProvideHTTPServer(config, logrusLogger, firebaseAuth, handler)
-	checkService := ProvideGRPCCheckService(inspectUsecase)
+	checkService := ProvideGRPCCheckService(config, inspectUsecase, manageUsecase, client)
 	grpcServerServer := ProvideGRPCServer(logrusLogger, config, checkService)
-	app := ProvideApp(logrusLogger, config, server, grpcServerServer)
-	return app
+	expirySweeper := ProvideExpirySweeper(logrusLogger, config, manageUsecase)
+	trashPurger := ProvideTrashPurger(logrusLogger, config, manageUsecase)
+	app := ProvideApp(logrusLogger, config, server, grpcServerServer, domainMemRepo, disposableRepo, expirySweeper, trashPurger)
+	return app, func() {
+		cleanup()
+	}
 }
 
-func ProvideApp(log *logrus.Logger, cfg *Config, httpServer *HTTPServer.Server, grpcServer *GRPCServer.Server) *App {
-	app := NewApp(log, cfg, httpServer, grpcServer)
+func ProvideApp(log *logrus.Logger, cfg *Config, httpServer *HTTPServer.Server, grpcServer *GRPCServer.Server, domainRepo *adapters.DomainMemRepo, disposableRepo *adapters.DisposableRepo, expirySweeper *usecases.ExpirySweeper, trashPurger *usecases.TrashPurger) *App {
+	app := NewApp(log, cfg, httpServer, grpcServer, domainRepo, disposableRepo, expirySweeper, trashPurger)
 	return app
 }
 
@@ -67,11 +80,6 @@ func ProvideHandler(accessUsecase HTTPServer.AccessUsecase, domainUsecase HTTPSe
 	return handler
 }
 
-func ProvideGRPCCheckService(inspectUsecase GRPCServer.InspectUsecase) *GRPCServer.CheckService {
-	checkService := GRPCServer.NewCheckService(inspectUsecase)
-	return checkService
-}
-
 func ProvideDomainRepo(db *gorm.DB) *adapters.DomainRepo {
 	domainRepo := adapters.NewDomainRepo(db)
 	return domainRepo
@@ -87,19 +95,19 @@ func ProvideReviewRepo(db *gorm.DB) *adapters.ReviewRepo {
 	return reviewRepo
 }
 
-func ProvideAccessRepo(db *gorm.DB) *adapters.AccessRepo {
-	accessRepo := adapters.NewAccessRepo(db)
-	return accessRepo
+func ProvideAuditRepo(db *gorm.DB) *adapters.AuditRepo {
+	auditRepo := adapters.NewAuditRepo(db)
+	return auditRepo
 }
 
-func ProvideManageUsecase(domainRepo usecases.DomainRepository, filterRepo usecases.FilterRepository) *usecases.ManageUsecase {
-	manageUsecase := usecases.NewManageUsecase(domainRepo, filterRepo)
-	return manageUsecase
+func ProvideTransactor(db *gorm.DB) *adapters.Transactor {
+	transactor := adapters.NewTransactor(db)
+	return transactor
 }
 
-func ProvideInspectUsecase(log *logrus.Logger, accessRepo usecases.AccessRepository, domainRepo usecases.DomainRepository, filterRepo usecases.FilterRepository) *usecases.InspectUsecase {
-	inspectUsecase := usecases.NewInspectUsecase(log, accessRepo, domainRepo, filterRepo)
-	return inspectUsecase
+func ProvideAccessRepo(db *gorm.DB) *adapters.AccessRepo {
+	accessRepo := adapters.NewAccessRepo(db)
+	return accessRepo
 }
 
 func ProvideReviewUsecase(domainReviewRepo usecases.ReviewRepository) *usecases.ReviewUsecase {
@@ -151,3 +159,69 @@ func ProvideHTTPServer(cfg *Config, log *logrus.Logger, firebaseAuth *HTTPServer
 func ProvideGRPCServer(log *logrus.Logger, cfg *Config, checkHandler *GRPCServer.CheckService) *GRPCServer.Server {
 	return GRPCServer.NewGRPCServer(&grpcserver.Config{Host: cfg.Host, Port: cfg.Port}, log, checkHandler)
 }
+
+func ProvideGRPCCheckService(cfg *Config, inspectUsecase GRPCServer.InspectUsecase, manageUsecase GRPCServer.ManageUsecase, client *auth.Client) *GRPCServer.CheckService {
+	return GRPCServer.NewCheckService(inspectUsecase, manageUsecase, client, cfg.InspectStreamConcurrency)
+}
+
+func ProvideDomainMemRepo(log *logrus.Logger, cfg *Config, domainRepo *adapters.DomainRepo) *adapters.DomainMemRepo {
+	return adapters.NewDomainMemRepo(log, domainRepo, cfg.DomainIndexReloadInterval)
+}
+
+func ProvideProviderRepo(db *gorm.DB, cfg *Config) *adapters.ProviderRepo {
+	return adapters.NewProviderRepo(db, cfg.ProviderCacheTTL)
+}
+
+func ProvideDisposableRepo(log *logrus.Logger, cfg *Config) *adapters.DisposableRepo {
+	return adapters.NewDisposableRepo(log, cfg.DisposableDomainsPath, cfg.DisposableMXHostsPath, cfg.DisposableReloadInterval)
+}
+
+func ProvideDNSResolver(cfg *Config) *adapters.DNSCache {
+	return adapters.NewDNSCache(adapters.NewDNSResolver(cfg.DNSServer, cfg.DNSTimeout), cfg.DNSCacheMaxTTL, cfg.DNSCacheSize)
+}
+
+func ProvideLookupService(log *logrus.Logger, cfg *Config) (usecases.LookupService, func()) {
+	if cfg.LookupServiceAddr == "" {
+		return nil, func() {}
+	}
+	conn, err := adapters.NewLookupClientConn(cfg.LookupServiceAddr)
+	if err != nil {
+		panic(err)
+	}
+	cleanup := func() {
+		if err := conn.Close(); err != nil {
+			log.Errorf("could not close lookup service connection: %v", err)
+		}
+	}
+	return adapters.NewLookupAdapter(conn, cfg.LookupTimeout, cfg.LookupRetries, cfg.LookupRetryBackoff, cfg.LookupBreakerThreshold, cfg.LookupBreakerCooldown), cleanup
+}
+
+func ProvideVerdictCache(cfg *Config) usecases.VerdictCache {
+	if cfg.VerdictCacheSize <= 0 || cfg.VerdictCacheTTL <= 0 {
+		return nil
+	}
+	return adapters.NewVerdictLRU(cfg.VerdictCacheTTL, cfg.VerdictCacheSize)
+}
+
+func ProvideManageUsecase(cfg *Config, accessRepo usecases.AccessRepository, domainRepo usecases.DomainRepository, filterRepo usecases.FilterRepository, providerRepo usecases.ProviderRepository, auditRepo usecases.AuditRepository, transactor usecases.Transactor, verdictCache usecases.VerdictCache) *usecases.ManageUsecase {
+	return usecases.NewManageUsecase(accessRepo, domainRepo, filterRepo, providerRepo, auditRepo, transactor, verdictCache, cfg.DomainCountCacheTTL, cfg.ImportBatchSize, cfg.ImportMaxRows)
+}
+
+func ProvideExpirySweeper(log *logrus.Logger, cfg *Config, manageUsecase *usecases.ManageUsecase) *usecases.ExpirySweeper {
+	return usecases.NewExpirySweeper(log, manageUsecase, cfg.RuleExpirySweepInterval)
+}
+
+func ProvideTrashPurger(log *logrus.Logger, cfg *Config, manageUsecase *usecases.ManageUsecase) *usecases.TrashPurger {
+	return usecases.NewTrashPurger(log, manageUsecase, cfg.DomainTrashRetention, cfg.DomainTrashPurgeInterval)
+}
+
+func ProvideInspectUsecase(log *logrus.Logger, cfg *Config, accessRepo usecases.AccessRepository, domainRepo usecases.DomainRepository, filterRepo usecases.FilterRepository, providerRepo usecases.ProviderRepository, disposableRepo usecases.DisposableRepository, dnsResolver *adapters.DNSCache, lookupService usecases.LookupService, verdictCache usecases.VerdictCache) *usecases.InspectUsecase {
+	var dnsCheckResolver, mxCheckResolver usecases.DNSResolver
+	if cfg.DNSCheckEnabled {
+		dnsCheckResolver = dnsResolver
+	}
+	if cfg.DisposableMXCheckEnabled {
+		mxCheckResolver = dnsResolver
+	}
+	return usecases.NewInspectUsecase(log, accessRepo, domainRepo, filterRepo, providerRepo, disposableRepo, dnsCheckResolver, mxCheckResolver, lookupService, verdictCache, models.TieBreakFromString(cfg.MatchTieBreak), cfg.PatternMatchBudget, cfg.InspectBatchMaxSize, cfg.InspectBatchConcurrency, cfg.InspectStreamQuotaLease)
+}
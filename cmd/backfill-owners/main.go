@@ -0,0 +1,115 @@
+// Command backfill-owners records the owners of projects whose access rows predate CustomerUUID.
+// It reads token,customer_uuid rows, as exported from the project service, and leaves recorded owners untouched.
+//
+//	POSTGRES_DSN=... backfill-owners -file owners.csv -dry-run
+//
+// Customers cannot manage the filters of a project until its owner is recorded.
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/adapters"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/aerosystems/common-service/logger"
+	"github.com/aerosystems/common-service/pkg/gormclient"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	file := flag.String("file", "", "path of the token,customer_uuid CSV file, - reads standard input")
+	dryRun := flag.Bool("dry-run", false, "only check the file and report the projects without an owner")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	viper.AutomaticEnv()
+	log := logger.NewLogger().Logger
+	log.SetLevel(logrus.WarnLevel)
+
+	input := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("could not open %s: %v", *file, err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	db := gormclient.NewPostgresDB(log, viper.GetString("POSTGRES_DSN"))
+	if err := adapters.AutoMigrateGORM(db); err != nil {
+		log.Fatal(err)
+	}
+	accessRepo := adapters.NewAccessRepo(db)
+	ctx := context.Background()
+
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = 2
+	var updated, skipped, invalid int
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			fmt.Printf("line %d: %v\n", line, err)
+			invalid++
+			continue
+		}
+		token := strings.TrimSpace(record[0])
+		customerUUID, err := uuid.Parse(strings.TrimSpace(record[1]))
+		if err != nil || customerUUID == uuid.Nil {
+			if line == 1 {
+				continue // header
+			}
+			fmt.Printf("line %d: invalid customer uuid %q\n", line, record[1])
+			invalid++
+			continue
+		}
+		if *dryRun {
+			access, err := accessRepo.Get(ctx, token)
+			if err != nil {
+				if !errors.Is(err, models.ErrApiKeyNotFound) {
+					log.Fatalf("could not read project %s: %v", token, err)
+				}
+				skipped++
+				continue
+			}
+			if access.CustomerUUID == uuid.Nil {
+				updated++
+			} else {
+				skipped++
+			}
+			continue
+		}
+		ok, err := accessRepo.SetMissingOwner(ctx, token, customerUUID)
+		if err != nil {
+			log.Fatalf("could not record the owner of project %s: %v", token, err)
+		}
+		if ok {
+			updated++
+		} else {
+			skipped++
+		}
+	}
+
+	if *dryRun {
+		fmt.Println("dry run, nothing was written")
+	}
+	fmt.Printf("updated: %d\nskipped: %d\ninvalid: %d\n", updated, skipped, invalid)
+	if invalid > 0 {
+		os.Exit(1)
+	}
+}
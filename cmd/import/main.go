@@ -0,0 +1,114 @@
+// Command import loads domain rules from a CSV, NDJSON or plain text file into the global domain list.
+//
+//	POSTGRES_DSN=... import -file blocklist.txt -format text -type blacklist -coverage suffix -dry-run
+//
+// Running instances pick the rules up on their next domain index reload.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/adapters"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/aerosystems/checkmail-service/internal/usecases"
+	"github.com/aerosystems/common-service/logger"
+	"github.com/aerosystems/common-service/pkg/gormclient"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultBatchSize = 1000
+	defaultMaxRows   = 1000000
+)
+
+func main() {
+	file := flag.String("file", "", "path of the file to import, - reads standard input")
+	format := flag.String("format", "", "csv, ndjson or text, taken from the file extension when omitted")
+	domainType := flag.String("type", "", "type of rows without one: blacklist, whitelist or disposable")
+	coverage := flag.String("coverage", "", "coverage of rows without one, equals by default")
+	dryRun := flag.Bool("dry-run", false, "only report what the import would do")
+	batchSize := flag.Int("batch-size", defaultBatchSize, "rows upserted per statement")
+	maxRows := flag.Int("max-rows", defaultMaxRows, "rows the file may have at most")
+	actor := flag.String("actor", "", "UUID of the staff member the audit log records the import for")
+	reason := flag.String("reason", "", "reason the audit log records for the import")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = formatFromExtension(*file)
+	}
+
+	viper.AutomaticEnv()
+	log := logger.NewLogger().Logger
+	log.SetLevel(logrus.WarnLevel)
+
+	auditMeta := models.AuditMeta{ActorRole: models.UnknownRole, Reason: *reason, RequestID: uuid.NewString()}
+	if *actor != "" {
+		actorUUID, err := uuid.Parse(*actor)
+		if err != nil {
+			log.Fatalf("could not parse actor %s: %v", *actor, err)
+		}
+		auditMeta.ActorUUID, auditMeta.ActorRole = actorUUID, models.StaffRole
+	}
+
+	input := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("could not open %s: %v", *file, err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	db := gormclient.NewPostgresDB(log, viper.GetString("POSTGRES_DSN"))
+	if err := adapters.AutoMigrateGORM(db); err != nil {
+		log.Fatal(err)
+	}
+	manageUsecase := usecases.NewManageUsecase(nil, adapters.NewDomainRepo(db), nil, nil, adapters.NewAuditRepo(db), adapters.NewTransactor(db), nil, 0, *batchSize, *maxRows)
+
+	report, err := manageUsecase.ImportDomains(models.WithAuditMeta(context.Background(), auditMeta), input, *format, *domainType, *coverage, *dryRun)
+	if err != nil {
+		log.Fatalf("could not import %s: %v", *file, err)
+	}
+	printReport(report)
+	if !report.DryRun {
+		fmt.Printf("audit request id: %s\n", auditMeta.RequestID)
+	}
+	if report.Invalid > 0 {
+		os.Exit(1)
+	}
+}
+
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return models.CSVImportFormat.String()
+	case ".ndjson", ".jsonl":
+		return models.NDJSONImportFormat.String()
+	default:
+		return models.TextImportFormat.String()
+	}
+}
+
+func printReport(report *models.ImportReport) {
+	if report.DryRun {
+		fmt.Println("dry run, nothing was written")
+	}
+	fmt.Printf("inserted: %d\nupdated:  %d\nskipped:  %d\ninvalid:  %d\n", report.Inserted, report.Updated, report.Skipped, report.Invalid)
+	for _, importError := range report.Errors {
+		fmt.Printf("line %d: %q: %s\n", importError.Line, importError.Data, importError.Reason)
+	}
+	if hidden := report.Invalid - len(report.Errors); hidden > 0 {
+		fmt.Printf("... and %d more invalid rows\n", hidden)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
@@ -18,6 +20,7 @@ func NewAccessRepo(db *gorm.DB) *AccessRepo {
 
 type Access struct {
 	Token            string    `gorm:"uniqueIndex:idx_token_access"`
+	CustomerUUID     uuid.UUID `gorm:"type:uuid;index:idx_customer_uuid"`
 	SubscriptionType string    `gorm:"uniqueIndex:idx_token_access;type:subscription_type"`
 	AccessCount      int       `gorm:"uniqueIndex:idx_token_access"`
 	AccessTime       time.Time `gorm:"<-"`
@@ -26,6 +29,7 @@ type Access struct {
 func (a *Access) ToModel() *models.Access {
 	return &models.Access{
 		Token:            a.Token,
+		CustomerUUID:     a.CustomerUUID,
 		SubscriptionType: models.SubscriptionTypeFromString(a.SubscriptionType),
 		AccessCount:      a.AccessCount,
 		AccessTime:       a.AccessTime,
@@ -35,6 +39,7 @@ func (a *Access) ToModel() *models.Access {
 func ModelToAccess(access *models.Access) *Access {
 	return &Access{
 		Token:            access.Token,
+		CustomerUUID:     access.CustomerUUID,
 		SubscriptionType: access.SubscriptionType.String(),
 		AccessCount:      access.AccessCount,
 		AccessTime:       access.AccessTime,
@@ -45,11 +50,27 @@ func (ar *AccessRepo) Get(ctx context.Context, token string) (*models.Access, er
 	var access Access
 	result := ar.db.WithContext(ctx).Where("token = ?", token).First(&access)
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, models.ErrApiKeyNotFound
+		}
 		return nil, result.Error
 	}
 	return access.ToModel(), nil
 }
 
+func (ar *AccessRepo) FindByCustomerUUID(ctx context.Context, customerUUID uuid.UUID) ([]models.Access, error) {
+	var accesses []Access
+	result := ar.db.WithContext(ctx).Where("customer_uuid = ?", customerUUID).Find(&accesses)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	accessList := make([]models.Access, 0, len(accesses))
+	for _, access := range accesses {
+		accessList = append(accessList, *access.ToModel())
+	}
+	return accessList, nil
+}
+
 func (ar *AccessRepo) CreateOrUpdate(ctx context.Context, access *models.Access) error {
 	accessModel := ModelToAccess(access)
 	result := ar.db.WithContext(ctx).Where("token = ?", accessModel.Token).First(&Access{})
@@ -70,13 +91,24 @@ func (ar *AccessRepo) CreateOrUpdate(ctx context.Context, access *models.Access)
 	return nil
 }
 
+// SetMissingOwner records the owner of a project created before owners were recorded, it never replaces a recorded one.
+func (ar *AccessRepo) SetMissingOwner(ctx context.Context, token string, customerUUID uuid.UUID) (bool, error) {
+	result := ar.db.WithContext(ctx).Model(&Access{}).
+		Where("token = ? AND (customer_uuid IS NULL OR customer_uuid = ?)", token, uuid.Nil).
+		Update("customer_uuid", customerUUID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (ar *AccessRepo) Tx(ctx context.Context, token string, fn func(a *models.Access) (any, error)) (any, error) {
 	tx := ar.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
 	var access Access
-	err := tx.Model(&Access{}).Where("token = ?", token).First(&access).Error
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&Access{}).Where("token = ?", token).First(&access).Error
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -0,0 +1,96 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type ArchivedRule struct {
+	Id           int        `gorm:"primaryKey;autoIncrement"`
+	ProjectToken string     `gorm:"index:idx_archived_rule_name,priority:1"`
+	Name         string     `gorm:"index:idx_archived_rule_name,priority:2"`
+	Type         string     `gorm:"type:domain_type"`
+	Match        string     `gorm:"type:match_type"`
+	CreatedAt    time.Time  `gorm:"<-"`
+	UpdatedAt    time.Time  `gorm:"<-"`
+	ExpiresAt    *time.Time `gorm:"<-"`
+	ArchivedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+// ArchiveExpired leaves the rows locked by another instance sweeping at the same time to it.
+func (r *DomainRepo) ArchiveExpired(ctx context.Context, now time.Time, limit int) ([]models.Domain, error) {
+	var domains []Domain
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("expires_at <= ?", now).Order("expires_at").Limit(limit).Find(&domains).Error; err != nil {
+			return err
+		}
+		if len(domains) == 0 {
+			return nil
+		}
+		archived := make([]ArchivedRule, 0, len(domains))
+		keys := make([][]any, 0, len(domains))
+		for _, domain := range domains {
+			archived = append(archived, ArchivedRule{
+				Name:      domain.Name,
+				Type:      domain.Type,
+				Match:     domain.Match,
+				CreatedAt: domain.CreatedAt,
+				UpdatedAt: domain.UpdatedAt,
+				ExpiresAt: domain.ExpiresAt,
+			})
+			keys = append(keys, []any{domain.Name, domain.Type, domain.Match})
+		}
+		if err := tx.Create(&archived).Error; err != nil {
+			return err
+		}
+		// Archived rules leave the table for good, rules sharing their name and those in the trash stay.
+		return tx.Unscoped().Where("(name, type, match) IN ? AND deleted_at IS NULL", keys).Delete(&Domain{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error archiving expired domains: %w", err)
+	}
+	return DomainListToModelList(domains), nil
+}
+
+func (r *FilterRepo) ArchiveExpired(ctx context.Context, now time.Time, limit int) ([]models.Filter, error) {
+	var filters []Filter
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("expires_at <= ?", now).Order("expires_at").Limit(limit).Find(&filters).Error; err != nil {
+			return err
+		}
+		if len(filters) == 0 {
+			return nil
+		}
+		archived := make([]ArchivedRule, 0, len(filters))
+		keys := make([][]any, 0, len(filters))
+		for _, filter := range filters {
+			archived = append(archived, ArchivedRule{
+				ProjectToken: filter.ProjectToken,
+				Name:         filter.Name,
+				Type:         filter.Type,
+				Match:        filter.Match,
+				CreatedAt:    filter.CreatedAt,
+				UpdatedAt:    filter.UpdatedAt,
+				ExpiresAt:    filter.ExpiresAt,
+			})
+			keys = append(keys, []any{filter.ProjectToken, filter.Name, filter.Type, filter.Match})
+		}
+		if err := tx.Create(&archived).Error; err != nil {
+			return err
+		}
+		return tx.Where("(project_token, name, type, match) IN ?", keys).Delete(&Filter{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error archiving expired filters: %w", err)
+	}
+	for _, filter := range filters {
+		r.patterns.evict(filter.Name)
+	}
+	return FilterListToModelList(filters), nil
+}
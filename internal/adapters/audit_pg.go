@@ -0,0 +1,144 @@
+package adapters
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const auditBatchSize = 1000
+
+// AuditLog is append-only, the migration rejects updates and deletes of its rows.
+type AuditLog struct {
+	Id           int        `gorm:"primaryKey;autoIncrement;index:idx_audit_domain,priority:3;index:idx_audit_actor,priority:2"`
+	Action       string     `gorm:"<-"`
+	ProjectToken string     `gorm:"index:idx_audit_domain,priority:2"`
+	DomainName   string     `gorm:"index:idx_audit_domain,priority:1"`
+	ActorUUID    uuid.UUID  `gorm:"type:uuid;index:idx_audit_actor,priority:1"`
+	ActorRole    string     `gorm:"<-"`
+	Reason       string     `gorm:"<-"`
+	RequestID    string     `gorm:"index:idx_audit_request"`
+	Before       *AuditRule `gorm:"type:jsonb;serializer:json"`
+	After        *AuditRule `gorm:"type:jsonb;serializer:json"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
+
+type AuditRule struct {
+	Name      string     `json:"name"`
+	Type      string     `json:"type"`
+	Match     string     `json:"match"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type AuditRepo struct {
+	db *gorm.DB
+}
+
+func NewAuditRepo(db *gorm.DB) *AuditRepo {
+	return &AuditRepo{
+		db: db,
+	}
+}
+
+func ModelToAuditLog(model *models.AuditEntry) *AuditLog {
+	return &AuditLog{
+		Id:           model.Id,
+		Action:       model.Action.String(),
+		ProjectToken: model.ProjectToken,
+		DomainName:   model.DomainName,
+		ActorUUID:    model.ActorUUID,
+		ActorRole:    model.ActorRole.String(),
+		Reason:       model.Reason,
+		RequestID:    model.RequestID,
+		Before:       modelToAuditRule(model.Before),
+		After:        modelToAuditRule(model.After),
+		CreatedAt:    model.CreatedAt,
+	}
+}
+
+func AuditLogToModel(log *AuditLog) *models.AuditEntry {
+	return &models.AuditEntry{
+		Id:           log.Id,
+		Action:       models.AuditActionFromString(log.Action),
+		ProjectToken: log.ProjectToken,
+		DomainName:   log.DomainName,
+		ActorUUID:    log.ActorUUID,
+		ActorRole:    models.RoleFromString(log.ActorRole),
+		Reason:       log.Reason,
+		RequestID:    log.RequestID,
+		Before:       auditRuleToModel(log.Before),
+		After:        auditRuleToModel(log.After),
+		CreatedAt:    log.CreatedAt,
+	}
+}
+
+func modelToAuditRule(model *models.Domain) *AuditRule {
+	if model == nil {
+		return nil
+	}
+	return &AuditRule{
+		Name:      model.Name,
+		Type:      model.Type.String(),
+		Match:     model.Match.String(),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+		ExpiresAt: model.ExpiresAt,
+	}
+}
+
+func auditRuleToModel(rule *AuditRule) *models.Domain {
+	if rule == nil {
+		return nil
+	}
+	return &models.Domain{
+		Name:      rule.Name,
+		Type:      models.DomainTypeFromString(rule.Type),
+		Match:     models.DomainMatchFromString(rule.Match),
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+		ExpiresAt: rule.ExpiresAt,
+	}
+}
+
+func (r *AuditRepo) Create(ctx context.Context, entries ...models.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	logs := make([]AuditLog, 0, len(entries))
+	for i := range entries {
+		logs = append(logs, *ModelToAuditLog(&entries[i]))
+	}
+	return conn(ctx, r.db).CreateInBatches(logs, auditBatchSize).Error
+}
+
+func (r *AuditRepo) Find(ctx context.Context, query models.AuditQuery) (*models.AuditPage, error) {
+	db := conn(ctx, r.db)
+	if query.DomainName != "" {
+		db = db.Where("domain_name IN ? AND project_token = ?", models.RuleNames(query.DomainName), query.ProjectToken)
+	} else if query.ProjectToken != "" {
+		db = db.Where("project_token = ?", query.ProjectToken)
+	}
+	if query.ActorUUID != uuid.Nil {
+		db = db.Where("actor_uuid = ?", query.ActorUUID)
+	}
+	if query.BeforeId > 0 {
+		db = db.Where("id < ?", query.BeforeId)
+	}
+	var logs []AuditLog
+	if err := db.Order("id DESC").Limit(query.Limit + 1).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	page := &models.AuditPage{Entries: make([]models.AuditEntry, 0, len(logs))}
+	for i := range logs {
+		page.Entries = append(page.Entries, *AuditLogToModel(&logs[i]))
+	}
+	if len(page.Entries) > query.Limit {
+		page.Entries = page.Entries[:query.Limit]
+		page.NextBefore = page.Entries[query.Limit-1].Id
+	}
+	return page, nil
+}
@@ -0,0 +1,37 @@
+package adapters
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"testing"
+	"time"
+)
+
+func TestAuditLogKeepsExpiry(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+	before := models.Domain{Name: "expiry.example", Type: models.BlacklistType, Match: models.EqualsMatch}
+	after := before
+	after.ExpiresAt = &expiresAt
+
+	entry := AuditLogToModel(ModelToAuditLog(&models.AuditEntry{Before: &before, After: &after}))
+	if entry.Before.ExpiresAt != nil {
+		t.Fatalf("got expiry %v before, want none", entry.Before.ExpiresAt)
+	}
+	if entry.After.ExpiresAt == nil || !entry.After.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("got expiry %v after, want %v", entry.After.ExpiresAt, expiresAt)
+	}
+
+	// The audit log is append-only, the entry stays in the test database.
+	repo := NewAuditRepo(testDB(t))
+	ctx := context.Background()
+	if err := repo.Create(ctx, models.NewAuditEntry(ctx, "", &before, &after)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	page, err := repo.Find(ctx, models.AuditQuery{DomainName: before.Name, Limit: 1})
+	if err != nil || len(page.Entries) != 1 {
+		t.Fatalf("got %v, %v, want the entry", page, err)
+	}
+	if got := page.Entries[0].After.ExpiresAt; got == nil || !got.Equal(expiresAt) {
+		t.Fatalf("got expiry %v after, want %v", got, expiresAt)
+	}
+}
@@ -0,0 +1,61 @@
+package adapters
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var errCircuitOpen = errors.New("circuit breaker is open")
+
+// circuitBreaker opens for cooldown after threshold consecutive failures, then lets a single probe call through.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+	}
+}
+
+// allow has to be followed by done or cancel for every allowed call.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return errCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+func (b *circuitBreaker) done(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
@@ -0,0 +1,143 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	//go:embed data/disposable_domains.txt
+	bundledDisposableDomains string
+	//go:embed data/disposable_mx_hosts.txt
+	bundledDisposableMXHosts string
+)
+
+// DisposableRepo always loads the bundled lists, the optional files extend them and are reloaded every reloadInterval.
+type DisposableRepo struct {
+	log            *logrus.Logger
+	domainsPath    string
+	mxHostsPath    string
+	reloadInterval time.Duration
+
+	mu      sync.RWMutex
+	domains *labelTrie
+	mxHosts *labelTrie
+}
+
+func NewDisposableRepo(log *logrus.Logger, domainsPath, mxHostsPath string, reloadInterval time.Duration) *DisposableRepo {
+	r := &DisposableRepo{
+		log:            log,
+		domainsPath:    domainsPath,
+		mxHostsPath:    mxHostsPath,
+		reloadInterval: reloadInterval,
+		domains:        newLabelTrie(),
+		mxHosts:        newLabelTrie(),
+	}
+	if err := r.Reload(); err != nil {
+		log.Errorf("could not load disposable lists, using the bundled ones: %v", err)
+		domains, _ := loadDisposableList(bundledDisposableDomains, "")
+		mxHosts, _ := loadDisposableList(bundledDisposableMXHosts, "")
+		r.domains, r.mxHosts = newDisposableTrie(domains), newDisposableTrie(mxHosts)
+	}
+	return r
+}
+
+func (r *DisposableRepo) Run(ctx context.Context) error {
+	if r.reloadInterval <= 0 || (r.domainsPath == "" && r.mxHostsPath == "") {
+		return nil
+	}
+
+	ticker := time.NewTicker(r.reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Reload(); err != nil {
+				r.log.Errorf("could not reload disposable lists: %v", err)
+			}
+		}
+	}
+}
+
+// Reload keeps the current lists when a file can not be read.
+func (r *DisposableRepo) Reload() error {
+	domains, err := loadDisposableList(bundledDisposableDomains, r.domainsPath)
+	if err != nil {
+		return err
+	}
+	mxHosts, err := loadDisposableList(bundledDisposableMXHosts, r.mxHostsPath)
+	if err != nil {
+		return err
+	}
+
+	domainTrie, mxHostTrie := newDisposableTrie(domains), newDisposableTrie(mxHosts)
+	r.mu.Lock()
+	r.domains, r.mxHosts = domainTrie, mxHostTrie
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *DisposableRepo) MatchDomain(_ context.Context, name string) ([]models.Domain, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.domains.match(name), nil
+}
+
+func (r *DisposableRepo) MatchMXHost(_ context.Context, host string) ([]models.Domain, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mxHosts.match(host), nil
+}
+
+func loadDisposableList(bundled, path string) ([]string, error) {
+	names, err := readDisposableList(strings.NewReader(bundled))
+	if err != nil || path == "" {
+		return names, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open disposable list %s: %w", path, err)
+	}
+	defer file.Close()
+	extra, err := readDisposableList(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read disposable list %s: %w", path, err)
+	}
+	return append(names, extra...), nil
+}
+
+func readDisposableList(reader io.Reader) ([]string, error) {
+	var names []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(line, "."))
+	}
+	return names, scanner.Err()
+}
+
+func newDisposableTrie(names []string) *labelTrie {
+	trie := newLabelTrie()
+	for _, name := range names {
+		trie.insert(models.Domain{
+			Name:  name,
+			Type:  models.DisposableType,
+			Match: models.SuffixMatch,
+		})
+	}
+	return trie
+}
@@ -0,0 +1,101 @@
+package adapters
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestDisposableRepo(t *testing.T, domainsPath, mxHostsPath string) *DisposableRepo {
+	t.Helper()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewDisposableRepo(log, domainsPath, mxHostsPath, 0)
+}
+
+func TestDisposableRepoMatch(t *testing.T) {
+	domainsPath := filepath.Join(t.TempDir(), "domains.txt")
+	if err := os.WriteFile(domainsPath, []byte("# own list\nTempInbox.Example.\n\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	repo := newTestDisposableRepo(t, domainsPath, "")
+
+	tests := []struct {
+		name       string
+		match      func(ctx context.Context, name string) ([]models.Domain, error)
+		wantName   string
+		wantNoRule bool
+	}{
+		{"mailinator.com", repo.MatchDomain, "mailinator.com", false},
+		{"inbox.mailinator.com", repo.MatchDomain, "mailinator.com", false},
+		{"notmailinator.com", repo.MatchDomain, "", true},
+		{"mailinator.com.example.org", repo.MatchDomain, "", true},
+		{"tempinbox.example", repo.MatchDomain, "tempinbox.example", false},
+		{"mx1.mailinator.com", repo.MatchMXHost, "mailinator.com", false},
+		{"mx.yopmail.com", repo.MatchMXHost, "yopmail.com", false},
+		{"mx.gmail.com", repo.MatchMXHost, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := tt.match(context.Background(), tt.name)
+			if err != nil {
+				t.Fatalf("match: %v", err)
+			}
+			if tt.wantNoRule {
+				if len(rules) != 0 {
+					t.Fatalf("got %v, want no rule", rules)
+				}
+				return
+			}
+			if len(rules) != 1 || rules[0].Name != tt.wantName || rules[0].Type != models.DisposableType || rules[0].Match != models.SuffixMatch {
+				t.Fatalf("got %v, want a disposable suffix rule %q", rules, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestDisposableRepoReload(t *testing.T) {
+	ctx := context.Background()
+	mxHostsPath := filepath.Join(t.TempDir(), "mx_hosts.txt")
+	if err := os.WriteFile(mxHostsPath, []byte("mx.burner.example\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	repo := newTestDisposableRepo(t, "", mxHostsPath)
+	if rules, _ := repo.MatchMXHost(ctx, "mx.burner.example"); len(rules) != 1 {
+		t.Fatalf("got %v, want the host of the file", rules)
+	}
+
+	if err := os.WriteFile(mxHostsPath, []byte("mx.other.example\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := repo.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if rules, _ := repo.MatchMXHost(ctx, "mx.burner.example"); len(rules) != 0 {
+		t.Fatalf("got %v, a host removed from the file must not match after a reload", rules)
+	}
+	if rules, _ := repo.MatchMXHost(ctx, "mx.other.example"); len(rules) != 1 {
+		t.Fatalf("got %v, want the host added to the file", rules)
+	}
+
+	if err := os.Remove(mxHostsPath); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := repo.Reload(); err == nil {
+		t.Fatal("got no error for a missing file")
+	}
+	if rules, _ := repo.MatchMXHost(ctx, "mx.other.example"); len(rules) != 1 {
+		t.Fatalf("got %v, a failed reload must keep the current lists", rules)
+	}
+}
+
+func TestDisposableRepoFallsBackToBundledLists(t *testing.T) {
+	repo := newTestDisposableRepo(t, filepath.Join(t.TempDir(), "missing.txt"), "")
+	if rules, _ := repo.MatchDomain(context.Background(), "yopmail.com"); len(rules) != 1 {
+		t.Fatalf("got %v, want the bundled list", rules)
+	}
+}
@@ -0,0 +1,82 @@
+package adapters
+
+import (
+	"container/list"
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"sync"
+	"time"
+)
+
+// DNSCache does not cache lookup errors. When it is full, the least recently used entry is evicted.
+type DNSCache struct {
+	resolver   MailRouteResolver
+	maxTTL     time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type MailRouteResolver interface {
+	LookupMailRoute(ctx context.Context, name string) (*models.MailRoute, error)
+}
+
+type dnsCacheEntry struct {
+	name      string
+	route     models.MailRoute
+	expiresAt time.Time
+}
+
+func NewDNSCache(resolver MailRouteResolver, maxTTL time.Duration, maxEntries int) *DNSCache {
+	return &DNSCache{
+		resolver:   resolver,
+		maxTTL:     maxTTL,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *DNSCache) LookupMailRoute(ctx context.Context, name string) (*models.MailRoute, error) {
+	now := time.Now()
+	c.mu.Lock()
+	if element, ok := c.entries[name]; ok {
+		entry := element.Value.(*dnsCacheEntry)
+		if now.Before(entry.expiresAt) {
+			c.order.MoveToFront(element)
+			route := entry.route
+			c.mu.Unlock()
+			route.TTL = entry.expiresAt.Sub(now)
+			return &route, nil
+		}
+		c.removeElement(element)
+	}
+	c.mu.Unlock()
+
+	route, err := c.resolver.LookupMailRoute(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ttl := min(route.TTL, c.maxTTL)
+	if ttl <= 0 || c.maxEntries <= 0 {
+		return route, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[name]; ok {
+		c.removeElement(element)
+	}
+	c.entries[name] = c.order.PushFront(&dnsCacheEntry{name: name, route: *route, expiresAt: now.Add(ttl)})
+	for c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+	return route, nil
+}
+
+func (c *DNSCache) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*dnsCacheEntry).name)
+}
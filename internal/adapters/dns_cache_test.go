@@ -0,0 +1,35 @@
+package adapters
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"testing"
+	"time"
+)
+
+// countingMailRouteResolver answers every name with an MX route and counts the lookups of each.
+type countingMailRouteResolver map[string]int
+
+func (r countingMailRouteResolver) LookupMailRoute(_ context.Context, name string) (*models.MailRoute, error) {
+	r[name]++
+	return &models.MailRoute{Status: models.MXRouteStatus, MXHosts: []string{"mx." + name}, TTL: time.Hour}, nil
+}
+
+func TestDNSCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	resolver := countingMailRouteResolver{}
+	cache := NewDNSCache(resolver, time.Hour, 2)
+
+	for _, name := range []string{"a.test", "b.test", "a.test", "c.test", "a.test", "b.test"} {
+		route, err := cache.LookupMailRoute(ctx, name)
+		if err != nil || route.MXHosts[0] != "mx."+name {
+			t.Fatalf("got %+v, %v for %s", route, err, name)
+		}
+	}
+	want := countingMailRouteResolver{"a.test": 1, "b.test": 2, "c.test": 1}
+	for name, lookups := range want {
+		if resolver[name] != lookups {
+			t.Fatalf("got %v lookups, want %v, c.test has to evict b.test, the least recently used", resolver, want)
+		}
+	}
+}
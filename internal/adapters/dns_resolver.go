@@ -0,0 +1,243 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"golang.org/x/net/dns/dnsmessage"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultNameserver  = "127.0.0.1:53"
+	defaultNegativeTTL = 5 * time.Minute
+	maxUDPMessageSize  = 1232
+)
+
+// DNSResolver queries the nameserver directly, unlike net.Resolver it exposes the TTLs and tells NXDOMAIN from an empty answer.
+type DNSResolver struct {
+	server  string
+	timeout time.Duration
+}
+
+func NewDNSResolver(server string, timeout time.Duration) *DNSResolver {
+	if server == "" {
+		server = systemNameserver()
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &DNSResolver{
+		server:  server,
+		timeout: timeout,
+	}
+}
+
+func (r *DNSResolver) LookupMailRoute(ctx context.Context, name string) (*models.MailRoute, error) {
+	answer, err := r.query(ctx, name, dnsmessage.TypeMX)
+	if err != nil {
+		return nil, err
+	}
+	if answer.Header.RCode == dnsmessage.RCodeNameError {
+		return &models.MailRoute{Status: models.NotExistRouteStatus, TTL: negativeTTL(answer)}, nil
+	}
+
+	var records []*dnsmessage.MXResource
+	var ttl uint32
+	for _, resource := range answer.Answers {
+		if mx, ok := resource.Body.(*dnsmessage.MXResource); ok {
+			records = append(records, mx)
+			ttl = minTTL(ttl, resource.Header.TTL)
+		}
+	}
+	if len(records) > 0 {
+		if len(records) == 1 && records[0].Pref == 0 && records[0].MX.String() == "." {
+			return &models.MailRoute{Status: models.NullMXRouteStatus, TTL: time.Duration(ttl) * time.Second}, nil
+		}
+		sort.SliceStable(records, func(i, j int) bool {
+			return records[i].Pref < records[j].Pref
+		})
+		hosts := make([]string, 0, len(records))
+		for _, record := range records {
+			hosts = append(hosts, strings.ToLower(strings.TrimSuffix(record.MX.String(), ".")))
+		}
+		return &models.MailRoute{Status: models.MXRouteStatus, MXHosts: hosts, TTL: time.Duration(ttl) * time.Second}, nil
+	}
+
+	for _, addressType := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		answer, err := r.query(ctx, name, addressType)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		ttl = 0
+		for _, resource := range answer.Answers {
+			if resource.Header.Type == addressType {
+				found = true
+				ttl = minTTL(ttl, resource.Header.TTL)
+			}
+		}
+		if found {
+			return &models.MailRoute{Status: models.AddressRouteStatus, TTL: time.Duration(ttl) * time.Second}, nil
+		}
+	}
+	return &models.MailRoute{Status: models.NoRouteStatus, TTL: negativeTTL(answer)}, nil
+}
+
+func (r *DNSResolver) query(ctx context.Context, name string, queryType dnsmessage.Type) (*dnsmessage.Message, error) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	questionName, err := dnsmessage.NewName(strings.TrimSuffix(name, ".") + ".")
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS name %s: %w", name, err)
+	}
+	request := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: uint16(rand.UintN(1 << 16)), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  questionName,
+			Type:  queryType,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+
+	answer, err := r.exchange(ctx, "udp", request)
+	if err != nil {
+		return nil, exchangeError(ctx, err)
+	}
+	if answer.Header.Truncated {
+		answer, err = r.exchange(ctx, "tcp", request)
+		if err != nil {
+			return nil, exchangeError(ctx, err)
+		}
+	}
+	switch answer.Header.RCode {
+	case dnsmessage.RCodeSuccess, dnsmessage.RCodeNameError:
+		return answer, nil
+	default:
+		return nil, fmt.Errorf("DNS query %s %s failed: %s", queryType, name, answer.Header.RCode)
+	}
+}
+
+// exchange drops UDP replies that do not answer the request, as a spoofed or late one may arrive first.
+func (r *DNSResolver) exchange(ctx context.Context, network string, request dnsmessage.Message) (*dnsmessage.Message, error) {
+	packed, err := request.Pack()
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, r.server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	// without a deadline only the cancellation of ctx ends a read
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if network == "tcp" {
+		framed := binary.BigEndian.AppendUint16(make([]byte, 0, len(packed)+2), uint16(len(packed)))
+		if _, err := conn.Write(append(framed, packed...)); err != nil {
+			return nil, err
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		response := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, response); err != nil {
+			return nil, err
+		}
+		var answer dnsmessage.Message
+		if err := answer.Unpack(response); err != nil {
+			return nil, err
+		}
+		if !answers(&answer, request) {
+			return nil, errors.New("unexpected DNS response")
+		}
+		return &answer, nil
+	}
+
+	if _, err := conn.Write(packed); err != nil {
+		return nil, err
+	}
+	response := make([]byte, maxUDPMessageSize)
+	for {
+		n, err := conn.Read(response)
+		if err != nil {
+			return nil, err
+		}
+		var answer dnsmessage.Message
+		if err := answer.Unpack(response[:n]); err != nil || !answers(&answer, request) {
+			continue
+		}
+		return &answer, nil
+	}
+}
+
+// exchangeError reports the cancellation of ctx rather than the error of the connection it closed.
+func exchangeError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// answers checks the ID and that the question is echoed, names compare case-insensitively as resolvers may randomize the case.
+func answers(answer *dnsmessage.Message, request dnsmessage.Message) bool {
+	if answer.Header.ID != request.Header.ID || !answer.Header.Response || len(answer.Questions) != 1 {
+		return false
+	}
+	question, asked := answer.Questions[0], request.Questions[0]
+	return question.Type == asked.Type && question.Class == asked.Class && strings.EqualFold(question.Name.String(), asked.Name.String())
+}
+
+// negativeTTL follows RFC 2308: the minimum of the SOA record TTL and its MINIMUM field.
+func negativeTTL(answer *dnsmessage.Message) time.Duration {
+	for _, resource := range answer.Authorities {
+		if soa, ok := resource.Body.(*dnsmessage.SOAResource); ok {
+			return time.Duration(min(resource.Header.TTL, soa.MinTTL)) * time.Second
+		}
+	}
+	return defaultNegativeTTL
+}
+
+func minTTL(current, ttl uint32) uint32 {
+	if current == 0 {
+		return ttl
+	}
+	return min(current, ttl)
+}
+
+func systemNameserver() string {
+	file, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return defaultNameserver
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53")
+		}
+	}
+	return defaultNameserver
+}
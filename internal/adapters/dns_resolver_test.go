@@ -0,0 +1,290 @@
+package adapters
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"golang.org/x/net/dns/dnsmessage"
+	"io"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+type fakeZone struct {
+	rcode     dnsmessage.RCode
+	records   map[dnsmessage.Type][]dnsmessage.Resource
+	soa       *dnsmessage.Resource
+	truncated bool
+	// spoofed zones are first answered over UDP for another question, as by an off-path attacker guessing the ID
+	spoofed bool
+}
+
+// fakeNameserver answers over UDP and TCP on the same port, a truncated zone only answers over TCP.
+type fakeNameserver struct {
+	zones map[string]fakeZone
+	udp   net.PacketConn
+	tcp   net.Listener
+}
+
+func newFakeNameserver(t *testing.T, zones map[string]fakeZone) *fakeNameserver {
+	t.Helper()
+	var s *fakeNameserver
+	for attempt := 0; s == nil; attempt++ {
+		tcp, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen tcp: %v", err)
+		}
+		udp, err := net.ListenPacket("udp", tcp.Addr().String())
+		if err != nil {
+			tcp.Close()
+			if attempt == 10 {
+				t.Fatalf("listen udp: %v", err)
+			}
+			continue
+		}
+		s = &fakeNameserver{zones: zones, udp: udp, tcp: tcp}
+	}
+	t.Cleanup(func() {
+		s.udp.Close()
+		s.tcp.Close()
+	})
+	go s.serveUDP()
+	go s.serveTCP()
+	return s
+}
+
+func (s *fakeNameserver) addr() string {
+	return s.tcp.Addr().String()
+}
+
+func (s *fakeNameserver) serveUDP() {
+	buf := make([]byte, maxUDPMessageSize)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if forged, err := s.forge(buf[:n]); err == nil && forged != nil {
+			s.udp.WriteTo(forged, addr)
+		}
+		if response, err := s.answer(buf[:n], false); err == nil {
+			s.udp.WriteTo(response, addr)
+		}
+	}
+}
+
+func (s *fakeNameserver) serveTCP() {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err != nil {
+				return
+			}
+			request := make([]byte, binary.BigEndian.Uint16(length[:]))
+			if _, err := io.ReadFull(conn, request); err != nil {
+				return
+			}
+			response, err := s.answer(request, true)
+			if err != nil {
+				return
+			}
+			conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(response))), response...))
+		}()
+	}
+}
+
+func (s *fakeNameserver) answer(request []byte, overTCP bool) ([]byte, error) {
+	var query dnsmessage.Message
+	if err := query.Unpack(request); err != nil {
+		return nil, err
+	}
+	question := query.Questions[0]
+	response := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: query.Header.ID, Response: true, RecursionAvailable: true},
+		Questions: query.Questions,
+	}
+	zone, ok := s.zones[question.Name.String()]
+	if !ok {
+		response.Header.RCode = dnsmessage.RCodeRefused
+		return response.Pack()
+	}
+	response.Header.RCode = zone.rcode
+	if zone.truncated && !overTCP {
+		response.Header.Truncated = true
+		return response.Pack()
+	}
+	response.Answers = zone.records[question.Type]
+	if len(response.Answers) == 0 && zone.soa != nil {
+		response.Authorities = []dnsmessage.Resource{*zone.soa}
+	}
+	return response.Pack()
+}
+
+func (s *fakeNameserver) forge(request []byte) ([]byte, error) {
+	var query dnsmessage.Message
+	if err := query.Unpack(request); err != nil {
+		return nil, err
+	}
+	question := query.Questions[0]
+	if !s.zones[question.Name.String()].spoofed {
+		return nil, nil
+	}
+	question.Name = dnsName("attacker.test.")
+	response := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: query.Header.ID, Response: true},
+		Questions: []dnsmessage.Question{question},
+		Answers:   []dnsmessage.Resource{mxRecord("attacker.test.", 86400, 10, "mx.attacker.test.")},
+	}
+	return response.Pack()
+}
+
+func dnsName(name string) dnsmessage.Name {
+	return dnsmessage.MustNewName(name)
+}
+
+func mxRecord(name string, ttl uint32, pref uint16, host string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsName(name), Type: dnsmessage.TypeMX, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.MXResource{Pref: pref, MX: dnsName(host)},
+	}
+}
+
+func aRecord(name string, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsName(name), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+	}
+}
+
+func aaaaRecord(name string, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsName(name), Type: dnsmessage.TypeAAAA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}},
+	}
+}
+
+func soaRecord(ttl, minTTL uint32) *dnsmessage.Resource {
+	return &dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsName("test."), Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body: &dnsmessage.SOAResource{
+			NS:      dnsName("ns.test."),
+			MBox:    dnsName("hostmaster.test."),
+			Serial:  1,
+			Refresh: 3600,
+			Retry:   600,
+			Expire:  86400,
+			MinTTL:  minTTL,
+		},
+	}
+}
+
+func TestDNSResolverLookupMailRoute(t *testing.T) {
+	server := newFakeNameserver(t, map[string]fakeZone{
+		"mx.test.": {records: map[dnsmessage.Type][]dnsmessage.Resource{dnsmessage.TypeMX: {
+			mxRecord("mx.test.", 300, 20, "backup.mx.test."),
+			mxRecord("mx.test.", 60, 10, "Primary.MX.test."),
+		}}},
+		"null-mx.test.": {records: map[dnsmessage.Type][]dnsmessage.Resource{dnsmessage.TypeMX: {
+			mxRecord("null-mx.test.", 3600, 0, "."),
+		}}},
+		"a.test.": {
+			records: map[dnsmessage.Type][]dnsmessage.Resource{dnsmessage.TypeA: {aRecord("a.test.", 120), aRecord("a.test.", 240)}},
+			soa:     soaRecord(3600, 3600),
+		},
+		"aaaa.test.": {
+			records: map[dnsmessage.Type][]dnsmessage.Resource{dnsmessage.TypeAAAA: {aaaaRecord("aaaa.test.", 90)}},
+			soa:     soaRecord(3600, 3600),
+		},
+		"no-route.test.":  {soa: soaRecord(60, 600)},
+		"not-exist.test.": {rcode: dnsmessage.RCodeNameError, soa: soaRecord(900, 300)},
+		"no-soa.test.":    {rcode: dnsmessage.RCodeNameError},
+		"truncated.test.": {truncated: true, records: map[dnsmessage.Type][]dnsmessage.Resource{dnsmessage.TypeMX: {
+			mxRecord("truncated.test.", 30, 10, "mx.truncated.test."),
+		}}},
+		"server-failure.test.": {rcode: dnsmessage.RCodeServerFailure},
+		"spoofed.test.": {spoofed: true, records: map[dnsmessage.Type][]dnsmessage.Resource{dnsmessage.TypeMX: {
+			mxRecord("spoofed.test.", 30, 10, "mx.spoofed.test."),
+		}}},
+	})
+	resolver := NewDNSResolver(server.addr(), time.Second)
+
+	tests := []struct {
+		name string
+		want models.MailRoute
+	}{
+		{"mx.test", models.MailRoute{Status: models.MXRouteStatus, MXHosts: []string{"primary.mx.test", "backup.mx.test"}, TTL: time.Minute}},
+		{"null-mx.test", models.MailRoute{Status: models.NullMXRouteStatus, TTL: time.Hour}},
+		{"a.test", models.MailRoute{Status: models.AddressRouteStatus, TTL: 2 * time.Minute}},
+		{"aaaa.test", models.MailRoute{Status: models.AddressRouteStatus, TTL: 90 * time.Second}},
+		{"no-route.test", models.MailRoute{Status: models.NoRouteStatus, TTL: time.Minute}},
+		{"not-exist.test", models.MailRoute{Status: models.NotExistRouteStatus, TTL: 5 * time.Minute}},
+		{"no-soa.test", models.MailRoute{Status: models.NotExistRouteStatus, TTL: defaultNegativeTTL}},
+		{"truncated.test", models.MailRoute{Status: models.MXRouteStatus, MXHosts: []string{"mx.truncated.test"}, TTL: 30 * time.Second}},
+		{"spoofed.test", models.MailRoute{Status: models.MXRouteStatus, MXHosts: []string{"mx.spoofed.test"}, TTL: 30 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.LookupMailRoute(context.Background(), tt.name)
+			if err != nil {
+				t.Fatalf("LookupMailRoute: %v", err)
+			}
+			if got.Status != tt.want.Status || got.TTL != tt.want.TTL || !slices.Equal(got.MXHosts, tt.want.MXHosts) {
+				t.Fatalf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	t.Run("server-failure.test", func(t *testing.T) {
+		if route, err := resolver.LookupMailRoute(context.Background(), "server-failure.test"); err == nil {
+			t.Fatalf("got %+v, want an error", *route)
+		}
+	})
+}
+
+func TestDNSResolverTimeout(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	defer conn.Close()
+	resolver := NewDNSResolver(conn.LocalAddr().String(), 50*time.Millisecond)
+
+	_, err = resolver.LookupMailRoute(context.Background(), "silent.test")
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("got error %v, want a timeout", err)
+	}
+}
+
+func TestDNSResolverCancelWithoutTimeout(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	defer conn.Close()
+	resolver := NewDNSResolver(conn.LocalAddr().String(), 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	done := make(chan error, 1)
+	go func() {
+		_, err := resolver.LookupMailRoute(ctx, "silent.test")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got error %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the lookup still waits for the silent nameserver after the request was canceled")
+	}
+}
@@ -0,0 +1,420 @@
+package adapters
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// domainIndex answers which rules match an input without scanning the rule list, only glob and regex rules are evaluated one by one.
+type domainIndex struct {
+	// byName keys the rules by their name as stored, which removal goes by.
+	byName      map[string][]models.Domain
+	equals      map[string][]models.Domain
+	registrable map[string][]models.Domain
+	suffix      *labelTrie
+	prefix      *byteTrie
+	contains    *ahoCorasick
+	glob        *patternSet
+	regex       *patternSet
+	// suggestions is replaced rather than modified, so a list handed out stays valid.
+	suggestions []string
+	// suggested holds the rules counted in suggestionRefs, a rule expiring later is still counted until it is removed.
+	suggested      map[indexKey]bool
+	suggestionRefs map[string]int
+}
+
+type indexKey struct {
+	name       string
+	domainType models.Type
+	match      models.Match
+}
+
+// indexChanges collects what a batch of mutations leaves to refresh.
+type indexChanges struct {
+	contains    bool
+	suggestions map[string]bool
+}
+
+func newDomainIndex(domains []models.Domain) *domainIndex {
+	idx := &domainIndex{
+		byName:         make(map[string][]models.Domain),
+		equals:         make(map[string][]models.Domain),
+		registrable:    make(map[string][]models.Domain),
+		suffix:         newLabelTrie(),
+		prefix:         newByteTrie(),
+		contains:       newAhoCorasick(),
+		glob:           newPatternSet(nil),
+		regex:          newPatternSet(nil),
+		suggested:      make(map[indexKey]bool),
+		suggestionRefs: make(map[string]int),
+	}
+	idx.apply(nil, domains)
+	return idx
+}
+
+func (idx *domainIndex) add(domain models.Domain) {
+	idx.apply(nil, []models.Domain{domain})
+}
+
+func (idx *domainIndex) removeRule(rule models.Domain) {
+	idx.apply([]models.Domain{rule}, nil)
+}
+
+// apply removes and then adds rules, the contains automaton is rebuilt at most once and only when a contains pattern changed.
+// An added rule replaces the indexed one with its name, type and match, so a change can be applied again.
+func (idx *domainIndex) apply(removed, added []models.Domain) {
+	changes := indexChanges{suggestions: make(map[string]bool)}
+	for _, rule := range removed {
+		idx.remove(rule, &changes)
+	}
+	for _, domain := range added {
+		if _, ok := idx.byName[domain.Name]; ok {
+			idx.remove(domain, &changes)
+		}
+		idx.insert(domain, &changes)
+	}
+	if changes.contains {
+		idx.contains.build()
+	}
+	if len(changes.suggestions) > 0 {
+		idx.mergeSuggestions(changes.suggestions)
+	}
+}
+
+// insert keys the rule by its A-label form, so rules stored as U-labels match the converted input too.
+func (idx *domainIndex) insert(domain models.Domain, changes *indexChanges) {
+	stored := domain.Name
+	if name, err := models.DomainToASCII(domain.Name); err == nil && !domain.Match.IsPattern() {
+		domain.Name = name
+	}
+	idx.byName[stored] = append(idx.byName[stored], domain)
+	switch domain.Match {
+	case models.EqualsMatch:
+		idx.equals[domain.Name] = append(idx.equals[domain.Name], domain)
+	case models.RegistrableMatch:
+		idx.registrable[domain.Name] = append(idx.registrable[domain.Name], domain)
+	case models.SuffixMatch:
+		idx.suffix.insert(domain)
+	case models.PrefixMatch:
+		idx.prefix.insert(domain)
+	case models.ContainsMatch:
+		changes.contains = idx.contains.insert(domain) || changes.contains
+	case models.GlobMatch:
+		idx.glob.insert(domain)
+	case models.RegexMatch:
+		idx.regex.insert(domain)
+	}
+	if domain.SuggestionCandidate(time.Now()) {
+		idx.suggested[indexKey{stored, domain.Type, domain.Match}] = true
+		if idx.suggestionRefs[domain.Name]++; idx.suggestionRefs[domain.Name] == 1 {
+			changes.suggestions[domain.Name] = true
+		}
+	}
+}
+
+func (idx *domainIndex) remove(rule models.Domain, changes *indexChanges) {
+	var kept []models.Domain
+	for _, indexed := range idx.byName[rule.Name] {
+		if indexed.Type != rule.Type || indexed.Match != rule.Match {
+			kept = append(kept, indexed)
+			continue
+		}
+		switch indexed.Match {
+		case models.EqualsMatch:
+			idx.equals[indexed.Name] = slices.DeleteFunc(idx.equals[indexed.Name], indexed.Same)
+		case models.RegistrableMatch:
+			idx.registrable[indexed.Name] = slices.DeleteFunc(idx.registrable[indexed.Name], indexed.Same)
+		case models.SuffixMatch:
+			idx.suffix.remove(indexed)
+		case models.PrefixMatch:
+			idx.prefix.remove(indexed)
+		case models.ContainsMatch:
+			changes.contains = idx.contains.remove(indexed) || changes.contains
+		case models.GlobMatch:
+			idx.glob.remove(indexed)
+		case models.RegexMatch:
+			idx.regex.remove(indexed)
+		}
+		key := indexKey{rule.Name, indexed.Type, indexed.Match}
+		if idx.suggested[key] {
+			delete(idx.suggested, key)
+			if idx.suggestionRefs[indexed.Name]--; idx.suggestionRefs[indexed.Name] == 0 {
+				delete(idx.suggestionRefs, indexed.Name)
+				changes.suggestions[indexed.Name] = true
+			}
+		}
+	}
+	if len(kept) > 0 {
+		idx.byName[rule.Name] = kept
+	} else {
+		delete(idx.byName, rule.Name)
+	}
+}
+
+// mergeSuggestions merges the names that came or went into a new sorted list instead of sorting all candidates again.
+func (idx *domainIndex) mergeSuggestions(changed map[string]bool) {
+	touched := slices.Sorted(maps.Keys(changed))
+	merged := make([]string, 0, len(idx.suggestions)+len(touched))
+	i := 0
+	for _, name := range idx.suggestions {
+		for ; i < len(touched) && touched[i] <= name; i++ {
+			if touched[i] != name && idx.suggestionRefs[touched[i]] > 0 {
+				merged = append(merged, touched[i])
+			}
+		}
+		if idx.suggestionRefs[name] > 0 {
+			merged = append(merged, name)
+		}
+	}
+	for ; i < len(touched); i++ {
+		if idx.suggestionRefs[touched[i]] > 0 {
+			merged = append(merged, touched[i])
+		}
+	}
+	idx.suggestions = merged
+}
+
+func (idx *domainIndex) matchEquals(name string) []models.Domain {
+	return append([]models.Domain(nil), idx.equals[name]...)
+}
+
+func (idx *domainIndex) matchRegistrable(name string) []models.Domain {
+	var rules []models.Domain
+	for _, key := range models.DecomposeDomain(name).RegistrableKeys() {
+		rules = append(rules, idx.registrable[key]...)
+	}
+	return rules
+}
+
+func (idx *domainIndex) matchSuffix(name string) []models.Domain {
+	return idx.suffix.match(name)
+}
+
+func (idx *domainIndex) matchPrefix(name string) []models.Domain {
+	return idx.prefix.match(name)
+}
+
+func (idx *domainIndex) matchContains(name string) []models.Domain {
+	return idx.contains.match(name)
+}
+
+// labelTrie matches a suffix rule against the input and its subdomains, never against a partial label.
+type labelTrie struct {
+	root *labelNode
+}
+
+type labelNode struct {
+	children map[string]*labelNode
+	rules    []models.Domain
+}
+
+func newLabelTrie() *labelTrie {
+	return &labelTrie{root: &labelNode{children: make(map[string]*labelNode)}}
+}
+
+func reversedLabels(name string) []string {
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return labels
+}
+
+func (t *labelTrie) insert(domain models.Domain) {
+	node := t.root
+	for _, label := range reversedLabels(domain.Name) {
+		child, ok := node.children[label]
+		if !ok {
+			child = &labelNode{children: make(map[string]*labelNode)}
+			node.children[label] = child
+		}
+		node = child
+	}
+	node.rules = append(node.rules, domain)
+}
+
+func (t *labelTrie) remove(rule models.Domain) {
+	node := t.root
+	for _, label := range reversedLabels(rule.Name) {
+		child, ok := node.children[label]
+		if !ok {
+			return
+		}
+		node = child
+	}
+	node.rules = slices.DeleteFunc(node.rules, rule.Same)
+}
+
+func (t *labelTrie) match(name string) []models.Domain {
+	var rules []models.Domain
+	node := t.root
+	for _, label := range reversedLabels(name) {
+		child, ok := node.children[label]
+		if !ok {
+			break
+		}
+		node = child
+		rules = append(rules, node.rules...)
+	}
+	return rules
+}
+
+type byteTrie struct {
+	root *byteNode
+}
+
+type byteNode struct {
+	children map[byte]*byteNode
+	rules    []models.Domain
+}
+
+func newByteTrie() *byteTrie {
+	return &byteTrie{root: &byteNode{children: make(map[byte]*byteNode)}}
+}
+
+func (t *byteTrie) insert(domain models.Domain) {
+	node := t.root
+	for i := 0; i < len(domain.Name); i++ {
+		child, ok := node.children[domain.Name[i]]
+		if !ok {
+			child = &byteNode{children: make(map[byte]*byteNode)}
+			node.children[domain.Name[i]] = child
+		}
+		node = child
+	}
+	node.rules = append(node.rules, domain)
+}
+
+func (t *byteTrie) remove(rule models.Domain) {
+	node := t.root
+	for i := 0; i < len(rule.Name); i++ {
+		child, ok := node.children[rule.Name[i]]
+		if !ok {
+			return
+		}
+		node = child
+	}
+	node.rules = slices.DeleteFunc(node.rules, rule.Same)
+}
+
+func (t *byteTrie) match(name string) []models.Domain {
+	var rules []models.Domain
+	node := t.root
+	for i := 0; i < len(name); i++ {
+		child, ok := node.children[name[i]]
+		if !ok {
+			break
+		}
+		node = child
+		rules = append(rules, node.rules...)
+	}
+	return rules
+}
+
+// ahoCorasick is immutable once built, so insert and remove have to be followed by build.
+type ahoCorasick struct {
+	patterns map[string][]models.Domain
+	nodes    []acNode
+}
+
+type acNode struct {
+	next    map[byte]int
+	fail    int
+	outputs []string
+}
+
+func newAhoCorasick() *ahoCorasick {
+	return &ahoCorasick{patterns: make(map[string][]models.Domain)}
+}
+
+// insert reports whether the name is a new pattern, only then the automaton has to be rebuilt.
+func (ac *ahoCorasick) insert(domain models.Domain) bool {
+	rules, ok := ac.patterns[domain.Name]
+	ac.patterns[domain.Name] = append(rules, domain)
+	return !ok
+}
+
+// remove reports whether the last rule with the name is gone, only then the automaton has to be rebuilt.
+func (ac *ahoCorasick) remove(rule models.Domain) bool {
+	rules, ok := ac.patterns[rule.Name]
+	if !ok {
+		return false
+	}
+	if rules = slices.DeleteFunc(rules, rule.Same); len(rules) > 0 {
+		ac.patterns[rule.Name] = rules
+		return false
+	}
+	delete(ac.patterns, rule.Name)
+	return true
+}
+
+func (ac *ahoCorasick) build() {
+	nodes := []acNode{{next: make(map[byte]int)}}
+	for pattern := range ac.patterns {
+		state := 0
+		for i := 0; i < len(pattern); i++ {
+			next, ok := nodes[state].next[pattern[i]]
+			if !ok {
+				nodes = append(nodes, acNode{next: make(map[byte]int)})
+				next = len(nodes) - 1
+				nodes[state].next[pattern[i]] = next
+			}
+			state = next
+		}
+		nodes[state].outputs = append(nodes[state].outputs, pattern)
+	}
+
+	queue := make([]int, 0, len(nodes))
+	for _, child := range nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for c, child := range nodes[state].next {
+			fail := nodes[state].fail
+			for fail != 0 && !hasTransition(nodes[fail], c) {
+				fail = nodes[fail].fail
+			}
+			if next, ok := nodes[fail].next[c]; ok && next != child {
+				fail = next
+			}
+			nodes[child].fail = fail
+			nodes[child].outputs = append(nodes[child].outputs, nodes[fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+	ac.nodes = nodes
+}
+
+func hasTransition(node acNode, c byte) bool {
+	_, ok := node.next[c]
+	return ok
+}
+
+func (ac *ahoCorasick) match(text string) []models.Domain {
+	if len(ac.patterns) == 0 {
+		return nil
+	}
+	var rules []models.Domain
+	seen := make(map[string]bool)
+	state := 0
+	for i := 0; i < len(text); i++ {
+		for state != 0 && !hasTransition(ac.nodes[state], text[i]) {
+			state = ac.nodes[state].fail
+		}
+		if next, ok := ac.nodes[state].next[text[i]]; ok {
+			state = next
+		}
+		for _, pattern := range ac.nodes[state].outputs {
+			if !seen[pattern] {
+				seen[pattern] = true
+				rules = append(rules, ac.patterns[pattern]...)
+			}
+		}
+	}
+	return rules
+}
@@ -0,0 +1,228 @@
+package adapters
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDomainIndexSuggestions(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+	idx := newDomainIndex([]models.Domain{
+		{Name: "corp.example", Type: models.WhitelistType, Match: models.EqualsMatch},
+		{Name: "acme.io", Type: models.WhitelistType, Match: models.SuffixMatch},
+		{Name: "mail-", Type: models.WhitelistType, Match: models.PrefixMatch},
+		{Name: "localhost", Type: models.WhitelistType, Match: models.EqualsMatch},
+		{Name: "bad.com", Type: models.BlacklistType, Match: models.EqualsMatch},
+		{Name: "old.example", Type: models.WhitelistType, Match: models.EqualsMatch, ExpiresAt: &expired},
+	})
+	before := idx.suggestions
+	if want := []string{"acme.io", "corp.example"}; !slices.Equal(before, want) {
+		t.Fatalf("got %q, want %q", before, want)
+	}
+
+	idx.add(models.Domain{Name: "bank.example", Type: models.WhitelistType, Match: models.SuffixMatch})
+	if want := []string{"acme.io", "bank.example", "corp.example"}; !slices.Equal(idx.suggestions, want) {
+		t.Fatalf("got %q after add, want %q", idx.suggestions, want)
+	}
+	idx.removeRule(models.Domain{Name: "corp.example", Type: models.WhitelistType, Match: models.EqualsMatch})
+	if want := []string{"acme.io", "bank.example"}; !slices.Equal(idx.suggestions, want) {
+		t.Fatalf("got %q after remove, want %q", idx.suggestions, want)
+	}
+	if want := []string{"acme.io", "corp.example"}; !slices.Equal(before, want) {
+		t.Fatalf("a list handed out changed to %q", before)
+	}
+}
+
+func TestDomainIndexRefreshesOnlyWhatChanged(t *testing.T) {
+	spam := models.Domain{Name: "spam", Type: models.BlacklistType, Match: models.ContainsMatch}
+	corp := models.Domain{Name: "corp.example", Type: models.WhitelistType, Match: models.EqualsMatch}
+	idx := newDomainIndex([]models.Domain{spam, corp})
+	automaton := &idx.contains.nodes[0]
+
+	idx.add(models.Domain{Name: "mail.example", Type: models.BlacklistType, Match: models.EqualsMatch})
+	idx.add(models.Domain{Name: "spam", Type: models.WhitelistType, Match: models.ContainsMatch})
+	if &idx.contains.nodes[0] != automaton {
+		t.Fatal("the contains automaton was rebuilt without a new contains pattern")
+	}
+
+	shared := models.Domain{Name: "corp.example", Type: models.WhitelistType, Match: models.SuffixMatch}
+	idx.add(shared)
+	idx.removeRule(corp)
+	if want := []string{"corp.example"}; !slices.Equal(idx.suggestions, want) {
+		t.Fatalf("got %q, the name is still suggested by another rule", idx.suggestions)
+	}
+	idx.apply([]models.Domain{shared, spam, {Name: "spam", Type: models.WhitelistType, Match: models.ContainsMatch}}, []models.Domain{
+		{Name: "b.example", Type: models.WhitelistType, Match: models.EqualsMatch},
+		{Name: "a.example", Type: models.WhitelistType, Match: models.SuffixMatch},
+	})
+	if want := []string{"a.example", "b.example"}; !slices.Equal(idx.suggestions, want) {
+		t.Fatalf("got %q, want %q", idx.suggestions, want)
+	}
+	if rules := idx.matchContains("myspamsite.com"); len(rules) != 0 {
+		t.Fatalf("got %v, the batch did not rebuild the contains automaton", rules)
+	}
+}
+
+func TestDomainIndexRemove(t *testing.T) {
+	idx := newDomainIndex([]models.Domain{
+		{Name: "MAIL.EXAMPLE", Type: models.BlacklistType, Match: models.RegexMatch},
+		{Name: "mail.example", Type: models.WhitelistType, Match: models.EqualsMatch},
+		{Name: "bücher.de", Type: models.BlacklistType, Match: models.SuffixMatch},
+		{Name: "spam", Type: models.BlacklistType, Match: models.ContainsMatch},
+	})
+	idx.removeRule(models.Domain{Name: "MAIL.EXAMPLE", Type: models.BlacklistType, Match: models.RegexMatch})
+	if rules, _ := idx.regex.match(context.Background(), "MAIL-EXAMPLE"); len(rules) != 0 {
+		t.Fatalf("got %v, the regex rule was not removed", rules)
+	}
+	if rules := idx.matchEquals("mail.example"); len(rules) != 1 {
+		t.Fatalf("got %v, the equals rule has to stay", rules)
+	}
+	idx.removeRule(models.Domain{Name: "bücher.de", Type: models.BlacklistType, Match: models.SuffixMatch})
+	if rules := idx.matchSuffix("mail.xn--bcher-kva.de"); len(rules) != 0 {
+		t.Fatalf("got %v, the rule stored in U-labels was not removed", rules)
+	}
+	idx.removeRule(models.Domain{Name: "spam.example", Type: models.BlacklistType, Match: models.ContainsMatch})
+	if rules := idx.matchContains("myspamsite.com"); len(rules) != 1 {
+		t.Fatalf("got %v, removing an unknown name has to keep the rules", rules)
+	}
+}
+
+func TestDomainIndexRemoveRule(t *testing.T) {
+	expired := models.Domain{Name: "temp.example", Type: models.BlacklistType, Match: models.EqualsMatch}
+	permanent := models.Domain{Name: "temp.example", Type: models.WhitelistType, Match: models.EqualsMatch}
+	idx := newDomainIndex([]models.Domain{expired, permanent})
+
+	idx.removeRule(expired)
+	if rules := idx.matchEquals("temp.example"); len(rules) != 1 || !rules[0].Same(permanent) {
+		t.Fatalf("got %v, want only %v", rules, permanent)
+	}
+	if want := []string{"temp.example"}; !slices.Equal(idx.suggestions, want) {
+		t.Fatalf("got %q, want %q", idx.suggestions, want)
+	}
+}
+
+// indexMatches collects the rules of every tier matching name, sorted so that two indexes can be compared.
+func indexMatches(t *testing.T, idx *domainIndex, name string) []string {
+	t.Helper()
+	rules := slices.Concat(idx.matchEquals(name), idx.matchSuffix(name), idx.matchRegistrable(name), idx.matchPrefix(name), idx.matchContains(name))
+	for _, patterns := range []*patternSet{idx.glob, idx.regex} {
+		matched, err := patterns.match(context.Background(), name)
+		if err != nil {
+			t.Fatalf("match patterns: %v", err)
+		}
+		rules = append(rules, matched...)
+	}
+	keys := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		keys = append(keys, rule.Name+" "+rule.Type.String()+" "+rule.Match.String())
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestDomainIndexIncrementalApply(t *testing.T) {
+	probes := []string{"spam.com", "mail.spam.com", "spamalot.org", "mail-relay.net", "a.b.example.co.uk", "xn--bcher-kva.de", "shop.xn--bcher-kva.de", "temp.io"}
+	initial := []models.Domain{
+		{Name: "spam.com", Type: models.BlacklistType, Match: models.SuffixMatch},
+		{Name: "spam", Type: models.BlacklistType, Match: models.ContainsMatch},
+		{Name: "mail-", Type: models.WhitelistType, Match: models.PrefixMatch},
+	}
+	steps := []struct {
+		name   string
+		apply  func(idx *domainIndex)
+		update func(rules []models.Domain) []models.Domain
+	}{
+		{
+			name: "add a registrable rule",
+			apply: func(idx *domainIndex) {
+				idx.add(models.Domain{Name: "example.co.uk", Type: models.BlacklistType, Match: models.RegistrableMatch})
+			},
+			update: func(rules []models.Domain) []models.Domain {
+				return append(rules, models.Domain{Name: "example.co.uk", Type: models.BlacklistType, Match: models.RegistrableMatch})
+			},
+		},
+		{
+			name: "add a rule in U-labels",
+			apply: func(idx *domainIndex) {
+				idx.add(models.Domain{Name: "bücher.de", Type: models.DisposableType, Match: models.SuffixMatch})
+			},
+			update: func(rules []models.Domain) []models.Domain {
+				return append(rules, models.Domain{Name: "bücher.de", Type: models.DisposableType, Match: models.SuffixMatch})
+			},
+		},
+		{
+			name: "add a second contains rule",
+			apply: func(idx *domainIndex) {
+				idx.add(models.Domain{Name: "alot", Type: models.WhitelistType, Match: models.ContainsMatch})
+			},
+			update: func(rules []models.Domain) []models.Domain {
+				return append(rules, models.Domain{Name: "alot", Type: models.WhitelistType, Match: models.ContainsMatch})
+			},
+		},
+		{
+			name: "add patterns",
+			apply: func(idx *domainIndex) {
+				idx.add(models.Domain{Name: "*.spam.com", Type: models.WhitelistType, Match: models.GlobMatch})
+				idx.add(models.Domain{Name: `temp\.(io|net)`, Type: models.BlacklistType, Match: models.RegexMatch})
+			},
+			update: func(rules []models.Domain) []models.Domain {
+				return append(rules,
+					models.Domain{Name: "*.spam.com", Type: models.WhitelistType, Match: models.GlobMatch},
+					models.Domain{Name: `temp\.(io|net)`, Type: models.BlacklistType, Match: models.RegexMatch})
+			},
+		},
+		{
+			name: "remove a contains rule",
+			apply: func(idx *domainIndex) {
+				idx.removeRule(models.Domain{Name: "spam", Type: models.BlacklistType, Match: models.ContainsMatch})
+			},
+			update: func(rules []models.Domain) []models.Domain {
+				return slices.DeleteFunc(rules, func(rule models.Domain) bool { return rule.Name == "spam" })
+			},
+		},
+		{
+			name: "remove a rule of a shared name",
+			apply: func(idx *domainIndex) {
+				idx.add(models.Domain{Name: "spam.com", Type: models.WhitelistType, Match: models.EqualsMatch})
+				idx.removeRule(models.Domain{Name: "spam.com", Type: models.BlacklistType, Match: models.SuffixMatch})
+			},
+			update: func(rules []models.Domain) []models.Domain {
+				rules = slices.DeleteFunc(rules, func(rule models.Domain) bool { return rule.Name == "spam.com" })
+				return append(rules, models.Domain{Name: "spam.com", Type: models.WhitelistType, Match: models.EqualsMatch})
+			},
+		},
+		{
+			name: "remove patterns and a rule in U-labels",
+			apply: func(idx *domainIndex) {
+				idx.removeRule(models.Domain{Name: "*.spam.com", Type: models.WhitelistType, Match: models.GlobMatch})
+				idx.removeRule(models.Domain{Name: `temp\.(io|net)`, Type: models.BlacklistType, Match: models.RegexMatch})
+				idx.removeRule(models.Domain{Name: "bücher.de", Type: models.DisposableType, Match: models.SuffixMatch})
+			},
+			update: func(rules []models.Domain) []models.Domain {
+				return slices.DeleteFunc(rules, func(rule models.Domain) bool { return rule.Match.IsPattern() || rule.Name == "bücher.de" })
+			},
+		},
+	}
+
+	idx := newDomainIndex(slices.Clone(initial))
+	rules := slices.Clone(initial)
+	for _, step := range steps {
+		step.apply(idx)
+		rules = step.update(rules)
+		rebuilt := newDomainIndex(rules)
+		for _, name := range probes {
+			if got, want := indexMatches(t, idx, name), indexMatches(t, rebuilt, name); !slices.Equal(got, want) {
+				t.Fatalf("%s: %s matches %q, a rebuilt index %q", step.name, name, got, want)
+			}
+		}
+		if !slices.Equal(idx.suggestions, rebuilt.suggestions) {
+			t.Fatalf("%s: got suggestions %q, a rebuilt index %q", step.name, idx.suggestions, rebuilt.suggestions)
+		}
+	}
+	if got := indexMatches(t, idx, "spamalot.org"); !slices.Equal(got, []string{"alot whitelist contains"}) {
+		t.Fatalf("got %q after the last step", got)
+	}
+}
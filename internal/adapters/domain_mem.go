@@ -0,0 +1,235 @@
+package adapters
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/sirupsen/logrus"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DomainMemRepo applies its own mutations to the index incrementally, a periodic reload picks up changes made by other instances.
+// Until the first load succeeds, matching falls back to the database.
+type DomainMemRepo struct {
+	*DomainRepo
+	log            *logrus.Logger
+	reloadInterval time.Duration
+	findAll        func(ctx context.Context) ([]models.Domain, error)
+
+	mu    sync.RWMutex
+	index *domainIndex
+	// reloads counts the reloads in progress, the changes committed meanwhile are kept in pending to be replayed on the reloaded index.
+	reloads int
+	pending []func(index *domainIndex)
+}
+
+func NewDomainMemRepo(log *logrus.Logger, domainRepo *DomainRepo, reloadInterval time.Duration) *DomainMemRepo {
+	return &DomainMemRepo{
+		DomainRepo:     domainRepo,
+		log:            log,
+		reloadInterval: reloadInterval,
+		findAll:        domainRepo.FindAll,
+	}
+}
+
+func (r *DomainMemRepo) Run(ctx context.Context) error {
+	if err := r.Reload(ctx); err != nil {
+		r.log.Errorf("could not load domain index: %v", err)
+	}
+	if r.reloadInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(r.reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Reload(ctx); err != nil {
+				r.log.Errorf("could not reload domain index: %v", err)
+			}
+		}
+	}
+}
+
+// Reload replays the changes committed while it reads the rules, the snapshot may have been taken before them.
+func (r *DomainMemRepo) Reload(ctx context.Context) error {
+	r.mu.Lock()
+	r.reloads++
+	start := len(r.pending)
+	r.mu.Unlock()
+
+	domains, err := r.findAll(ctx)
+	var index *domainIndex
+	if err == nil {
+		index = newDomainIndex(domains)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if index != nil {
+		for _, update := range r.pending[start:] {
+			update(index)
+		}
+		r.index = index
+	}
+	if r.reloads--; r.reloads == 0 {
+		r.pending = nil
+	}
+	return err
+}
+
+func (r *DomainMemRepo) Create(ctx context.Context, domain *models.Domain) error {
+	if err := r.DomainRepo.Create(ctx, domain); err != nil {
+		return err
+	}
+	created := *domain
+	r.updateIndex(ctx, func(index *domainIndex) {
+		index.add(created)
+	})
+	return nil
+}
+
+func (r *DomainMemRepo) Update(ctx context.Context, previous, domain *models.Domain) error {
+	if err := r.DomainRepo.Update(ctx, previous, domain); err != nil {
+		return err
+	}
+	removed, added := []models.Domain{*previous}, []models.Domain{*domain}
+	r.updateIndex(ctx, func(index *domainIndex) {
+		index.apply(removed, added)
+	})
+	return nil
+}
+
+func (r *DomainMemRepo) Delete(ctx context.Context, domain *models.Domain) error {
+	if err := r.DomainRepo.Delete(ctx, domain); err != nil {
+		return err
+	}
+	deleted := *domain
+	r.updateIndex(ctx, func(index *domainIndex) {
+		index.removeRule(deleted)
+	})
+	return nil
+}
+
+func (r *DomainMemRepo) Restore(ctx context.Context, name string, domainType models.Type, domainMatch models.Match) (*models.Domain, error) {
+	domain, err := r.DomainRepo.Restore(ctx, name, domainType, domainMatch)
+	if err != nil {
+		return nil, err
+	}
+	restored := *domain
+	r.updateIndex(ctx, func(index *domainIndex) {
+		index.add(restored)
+	})
+	return domain, nil
+}
+
+func (r *DomainMemRepo) ArchiveExpired(ctx context.Context, now time.Time, limit int) ([]models.Domain, error) {
+	domains, err := r.DomainRepo.ArchiveExpired(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	r.updateIndex(ctx, func(index *domainIndex) {
+		index.apply(domains, nil)
+	})
+	return domains, nil
+}
+
+// Import applies the whole import to the index as one batch.
+func (r *DomainMemRepo) Import(ctx context.Context, domains []models.Domain, batchSize int, dryRun bool) (*models.ImportResult, error) {
+	result, err := r.DomainRepo.Import(ctx, domains, batchSize, dryRun)
+	if err != nil || dryRun {
+		return result, err
+	}
+	added := slices.Concat(result.Updated, result.Inserted)
+	r.updateIndex(ctx, func(index *domainIndex) {
+		index.apply(result.Replaced, added)
+	})
+	return result, nil
+}
+
+// updateIndex takes changes that can be applied again, a reload replays them on top of a snapshot that may already hold them.
+func (r *DomainMemRepo) updateIndex(ctx context.Context, update func(index *domainIndex)) {
+	afterCommit(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.index != nil {
+			update(r.index)
+		}
+		if r.reloads > 0 {
+			r.pending = append(r.pending, update)
+		}
+	})
+}
+
+// FindSuggestionCandidates returns the list the index keeps up to date, callers must not modify it.
+func (r *DomainMemRepo) FindSuggestionCandidates(ctx context.Context) ([]string, error) {
+	r.mu.RLock()
+	if r.index != nil {
+		defer r.mu.RUnlock()
+		return r.index.suggestions, nil
+	}
+	r.mu.RUnlock()
+	return r.DomainRepo.FindSuggestionCandidates(ctx)
+}
+
+func (r *DomainMemRepo) MatchEquals(ctx context.Context, name string) ([]models.Domain, error) {
+	return r.match(ctx, name, r.DomainRepo.MatchEquals, (*domainIndex).matchEquals)
+}
+
+func (r *DomainMemRepo) MatchSuffix(ctx context.Context, name string) ([]models.Domain, error) {
+	return r.match(ctx, name, r.DomainRepo.MatchSuffix, (*domainIndex).matchSuffix)
+}
+
+func (r *DomainMemRepo) MatchRegistrable(ctx context.Context, name string) ([]models.Domain, error) {
+	return r.match(ctx, name, r.DomainRepo.MatchRegistrable, (*domainIndex).matchRegistrable)
+}
+
+func (r *DomainMemRepo) MatchPrefix(ctx context.Context, name string) ([]models.Domain, error) {
+	return r.match(ctx, name, r.DomainRepo.MatchPrefix, (*domainIndex).matchPrefix)
+}
+
+func (r *DomainMemRepo) MatchContains(ctx context.Context, name string) ([]models.Domain, error) {
+	return r.match(ctx, name, r.DomainRepo.MatchContains, (*domainIndex).matchContains)
+}
+
+func (r *DomainMemRepo) MatchGlob(ctx context.Context, name string) ([]models.Domain, error) {
+	return r.matchPatterns(ctx, name, r.DomainRepo.MatchGlob, func(idx *domainIndex) *patternSet { return idx.glob })
+}
+
+func (r *DomainMemRepo) MatchRegex(ctx context.Context, name string) ([]models.Domain, error) {
+	return r.matchPatterns(ctx, name, r.DomainRepo.MatchRegex, func(idx *domainIndex) *patternSet { return idx.regex })
+}
+
+func (r *DomainMemRepo) matchPatterns(
+	ctx context.Context,
+	name string,
+	fallback func(ctx context.Context, name string) ([]models.Domain, error),
+	patterns func(idx *domainIndex) *patternSet,
+) ([]models.Domain, error) {
+	r.mu.RLock()
+	if r.index != nil {
+		defer r.mu.RUnlock()
+		return patterns(r.index).match(ctx, name)
+	}
+	r.mu.RUnlock()
+	return fallback(ctx, name)
+}
+
+func (r *DomainMemRepo) match(
+	ctx context.Context,
+	name string,
+	fallback func(ctx context.Context, name string) ([]models.Domain, error),
+	lookup func(idx *domainIndex, name string) []models.Domain,
+) ([]models.Domain, error) {
+	r.mu.RLock()
+	if r.index != nil {
+		defer r.mu.RUnlock()
+		return lookup(r.index, name), nil
+	}
+	r.mu.RUnlock()
+	return fallback(ctx, name)
+}
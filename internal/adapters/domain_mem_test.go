@@ -0,0 +1,58 @@
+package adapters
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/sirupsen/logrus"
+	"io"
+	"slices"
+	"testing"
+)
+
+func newTestDomainMemRepo(findAll func(ctx context.Context) ([]models.Domain, error)) *DomainMemRepo {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	repo := NewDomainMemRepo(log, nil, 0)
+	repo.findAll = findAll
+	return repo
+}
+
+func TestDomainMemRepoKeepsChangesCommittedDuringReload(t *testing.T) {
+	ctx := context.Background()
+	stored := models.Domain{Name: "stored.example", Type: models.BlacklistType, Match: models.EqualsMatch}
+	created := models.Domain{Name: "created.example", Type: models.BlacklistType, Match: models.EqualsMatch}
+	deleted := models.Domain{Name: "deleted.example", Type: models.BlacklistType, Match: models.EqualsMatch}
+
+	for _, loaded := range []bool{false, true} {
+		// the snapshot was read after the creation and before the deletion, the replayed changes must not add a rule twice
+		snapshot := []models.Domain{stored, created, deleted}
+		var repo *DomainMemRepo
+		repo = newTestDomainMemRepo(func(context.Context) ([]models.Domain, error) {
+			repo.updateIndex(ctx, func(index *domainIndex) { index.add(created) })
+			repo.updateIndex(ctx, func(index *domainIndex) { index.removeRule(deleted) })
+			repo.updateIndex(ctx, func(index *domainIndex) { index.add(stored) })
+			return snapshot, nil
+		})
+		if loaded {
+			repo.index = newDomainIndex([]models.Domain{stored, deleted})
+		}
+		if err := repo.Reload(ctx); err != nil {
+			t.Fatalf("reload: %v", err)
+		}
+
+		for _, rule := range []models.Domain{stored, created} {
+			if rules, _ := repo.MatchEquals(ctx, rule.Name); len(rules) != 1 || !rules[0].Same(rule) {
+				t.Fatalf("loaded %t: got %v for %s, want the rule indexed once", loaded, rules, rule.Name)
+			}
+		}
+		if rules, _ := repo.MatchEquals(ctx, deleted.Name); len(rules) != 0 {
+			t.Fatalf("loaded %t: got %v, the rule deleted during the reload came back with the snapshot", loaded, rules)
+		}
+		if repo.reloads != 0 || repo.pending != nil {
+			t.Fatalf("loaded %t: got %d reloads and %d pending changes left", loaded, repo.reloads, len(repo.pending))
+		}
+		if !slices.Equal(repo.index.byName[stored.Name], []models.Domain{stored}) {
+			t.Fatalf("loaded %t: got %v, want the rule stored once", loaded, repo.index.byName[stored.Name])
+		}
+	}
+}
@@ -11,18 +11,24 @@ import (
 )
 
 const (
-	PrefixMatch   = "prefix"
-	SuffixMatch   = "suffix"
-	EqualsMatch   = "equals"
-	ContainsMatch = "contains"
+	PrefixMatch      = "prefix"
+	SuffixMatch      = "suffix"
+	EqualsMatch      = "equals"
+	ContainsMatch    = "contains"
+	GlobMatch        = "glob"
+	RegexMatch       = "regex"
+	RegistrableMatch = "registrable"
 )
 
+// Domain has a unique index on the live rows only, so a rule in the trash does not block creating it again.
 type Domain struct {
-	Name      string    `gorm:"uniqueIndex:idx_name_type_match"`
-	Type      string    `gorm:"uniqueIndex:idx_name_type_match;type:domain_type"`
-	Match     string    `gorm:"uniqueIndex:idx_name_type_match;type:match_type"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	Name      string         `gorm:"uniqueIndex:idx_name_type_match_live,where:deleted_at IS NULL;index:idx_created_at_name,priority:2;index:idx_updated_at_name,priority:2;index:idx_deleted_at_name,priority:2"`
+	Type      string         `gorm:"uniqueIndex:idx_name_type_match_live;type:domain_type"`
+	Match     string         `gorm:"uniqueIndex:idx_name_type_match_live;type:match_type"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;index:idx_created_at_name,priority:1"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime;index:idx_updated_at_name,priority:1"`
+	ExpiresAt *time.Time     `gorm:"index"`
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_deleted_at_name,priority:1"`
 }
 
 type DomainRepo struct {
@@ -42,104 +48,354 @@ func ModelToDomain(model *models.Domain) *Domain {
 		Match:     model.Match.String(),
 		CreatedAt: model.CreatedAt,
 		UpdatedAt: model.UpdatedAt,
+		ExpiresAt: model.ExpiresAt,
 	}
 }
 
 func DomainToModel(domain *Domain) *models.Domain {
-	return &models.Domain{
+	model := &models.Domain{
 		Name:      domain.Name,
 		Type:      models.DomainTypeFromString(domain.Type),
 		Match:     models.DomainMatchFromString(domain.Match),
 		CreatedAt: domain.CreatedAt,
 		UpdatedAt: domain.UpdatedAt,
+		ExpiresAt: domain.ExpiresAt,
 	}
+	if domain.DeletedAt.Valid {
+		deletedAt := domain.DeletedAt.Time
+		model.DeletedAt = &deletedAt
+	}
+	return model
 }
 
-func (r *DomainRepo) FindByName(ctx context.Context, name string) (*models.Domain, error) {
-	var domain Domain
-	result := r.db.WithContext(ctx).First(&domain, "name = ?", name)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, models.ErrDomainNotFound
-		}
-		return nil, fmt.Errorf("error finding domain by name: %w", result.Error)
+func DomainListToModelList(domains []Domain) []models.Domain {
+	modelList := make([]models.Domain, 0, len(domains))
+	for i := range domains {
+		modelList = append(modelList, *DomainToModel(&domains[i]))
+	}
+	return modelList
+}
+
+// FindByName returns the rule with the name, zero domainType and domainMatch do not filter,
+// so they may only be left out when a single rule has the name.
+func (r *DomainRepo) FindByName(ctx context.Context, name string, domainType models.Type, domainMatch models.Match) (*models.Domain, error) {
+	db := conn(ctx, r.db).Where("name = ?", name)
+	if domainType != (models.Type{}) {
+		db = db.Where("type = ?", domainType.String())
+	}
+	if domainMatch != (models.Match{}) {
+		db = db.Where("match = ?", domainMatch.String())
+	}
+	var domains []Domain
+	if err := db.Limit(2).Find(&domains).Error; err != nil {
+		return nil, fmt.Errorf("error finding domain by name: %w", err)
+	}
+	switch len(domains) {
+	case 0:
+		return nil, models.ErrDomainNotFound
+	case 1:
+		return DomainToModel(&domains[0]), nil
+	default:
+		return nil, models.ErrDomainAmbiguous
 	}
-	return DomainToModel(&domain), nil
 }
 
 func (r *DomainRepo) Create(ctx context.Context, domain *models.Domain) error {
-	result := r.db.WithContext(ctx).Create(ModelToDomain(domain))
+	domainModel := ModelToDomain(domain)
+	result := conn(ctx, r.db).Create(domainModel)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) || strings.Contains(result.Error.Error(), "duplicate key value violates unique constraint") {
 			return models.ErrDomainAlreadyExists
 		}
 		return result.Error
 	}
+	domain.CreatedAt = domainModel.CreatedAt
+	domain.UpdatedAt = domainModel.UpdatedAt
 	return nil
 }
 
-func (r *DomainRepo) Update(ctx context.Context, domain *models.Domain) error {
-	result := r.db.WithContext(ctx).Save(&domain)
+func (r *DomainRepo) FindAll(ctx context.Context) ([]models.Domain, error) {
+	var domains []Domain
+	result := conn(ctx, r.db).Find(&domains)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return DomainListToModelList(domains), nil
+}
+
+func (r *DomainRepo) FindSuggestionCandidates(ctx context.Context) ([]string, error) {
+	var names []string
+	result := conn(ctx, r.db).Model(&Domain{}).Distinct("name").Order("name").
+		Where("type = ? AND match IN ? AND strpos(name, '.') > 0", models.WhitelistType.String(), []string{EqualsMatch, SuffixMatch}).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Pluck("name", &names)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return names, nil
+}
+
+type ruleKey struct {
+	name, domainType, match string
+}
+
+func (r *DomainRepo) Import(ctx context.Context, domains []models.Domain, batchSize int, dryRun bool) (*models.ImportResult, error) {
+	result := &models.ImportResult{}
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(domains); start += batchSize {
+			batch := domains[start:min(start+batchSize, len(domains))]
+			keys := make([][]any, 0, len(batch))
+			for _, domain := range batch {
+				keys = append(keys, []any{domain.Name, domain.Match.String()})
+			}
+			var existing []Domain
+			if err := tx.Find(&existing, "(name, match) IN ?", keys).Error; err != nil {
+				return err
+			}
+			current := make(map[ruleKey]Domain, len(existing))
+			taken := make(map[ruleKey]bool, len(existing))
+			for _, domain := range existing {
+				current[ruleKey{domain.Name, domain.Type, domain.Match}] = domain
+				taken[ruleKey{name: domain.Name, match: domain.Match}] = true
+			}
+
+			inserts := make([]Domain, 0, len(batch))
+			for _, domain := range batch {
+				previous, ok := current[ruleKey{domain.Name, domain.Type.String(), domain.Match.String()}]
+				switch {
+				case !ok && taken[ruleKey{name: domain.Name, match: domain.Match.String()}]:
+					result.Conflicts = append(result.Conflicts, domain)
+				case !ok:
+					inserts = append(inserts, *ModelToDomain(&domain))
+					result.Inserted = append(result.Inserted, domain)
+				case domain.ExpiresAt == nil || previous.ExpiresAt != nil && previous.ExpiresAt.Equal(*domain.ExpiresAt):
+					result.Skipped++
+				default:
+					if !dryRun {
+						if err := tx.Model(&Domain{}).Where("name = ? AND type = ? AND match = ?", previous.Name, previous.Type, previous.Match).Updates(map[string]any{
+							"expires_at": domain.ExpiresAt,
+							"updated_at": time.Now(),
+						}).Error; err != nil {
+							return err
+						}
+					}
+					result.Replaced = append(result.Replaced, *DomainToModel(&previous))
+					result.Updated = append(result.Updated, domain)
+				}
+			}
+			if !dryRun && len(inserts) > 0 {
+				if err := tx.Create(&inserts).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error importing domains: %w", err)
+	}
+	return result, nil
+}
+
+// Search uses a keyset on the sort field and the name, type and match, so a page costs the same however deep it is.
+func (r *DomainRepo) Search(ctx context.Context, query models.DomainQuery) (*models.DomainPage, error) {
+	return search(conn(ctx, r.db), query)
+}
+
+func (r *DomainRepo) SearchDeleted(ctx context.Context, query models.DomainQuery) (*models.DomainPage, error) {
+	return search(conn(ctx, r.db).Unscoped().Where("deleted_at IS NOT NULL"), query)
+}
+
+func search(db *gorm.DB, query models.DomainQuery) (*models.DomainPage, error) {
+	if query.Type != (models.Type{}) {
+		db = db.Where("type = ?", query.Type.String())
+	}
+	if query.Match != (models.Match{}) {
+		db = db.Where("match = ?", query.Match.String())
+	}
+	if query.NameContains != "" {
+		db = db.Where("strpos(name, ?) > 0", query.NameContains)
+	}
+	if !query.CreatedFrom.IsZero() {
+		db = db.Where("created_at >= ?", query.CreatedFrom)
+	}
+	if !query.CreatedTo.IsZero() {
+		db = db.Where("created_at < ?", query.CreatedTo)
+	}
+	if !query.UpdatedFrom.IsZero() {
+		db = db.Where("updated_at >= ?", query.UpdatedFrom)
+	}
+	if !query.UpdatedTo.IsZero() {
+		db = db.Where("updated_at < ?", query.UpdatedTo)
+	}
+
+	column, direction, operator := query.Sort.String(), "ASC", ">"
+	if query.Descending {
+		direction, operator = "DESC", "<"
+	}
+	if query.Cursor != nil {
+		cursor := query.Cursor
+		if query.Sort == models.NameSort {
+			db = db.Where("(name, type, match) "+operator+" (?, ?, ?)", cursor.Name, cursor.Type, cursor.Match)
+		} else {
+			db = db.Where("("+column+", name, type, match) "+operator+" (?, ?, ?, ?)", cursor.Time, cursor.Name, cursor.Type, cursor.Match)
+		}
+	}
+	if query.Sort != models.NameSort {
+		db = db.Order(column + " " + direction)
+	}
+
+	var domains []Domain
+	result := db.Order("name " + direction).Order("type " + direction).Order("match " + direction).Limit(query.Limit + 1).Find(&domains)
 	if result.Error != nil {
+		return nil, result.Error
+	}
+	page := &models.DomainPage{Domains: DomainListToModelList(domains)}
+	if len(page.Domains) > query.Limit {
+		page.Domains = page.Domains[:query.Limit]
+		page.NextCursor = models.NewDomainCursor(page.Domains[query.Limit-1], query.Sort, query.Descending).Encode()
+	}
+	return page, nil
+}
+
+// Update replaces the rule keyed by the name, type and match of previous with domain.
+func (r *DomainRepo) Update(ctx context.Context, previous, domain *models.Domain) error {
+	result := conn(ctx, r.db).Model(&Domain{}).Where("name = ? AND type = ? AND match = ?", previous.Name, previous.Type.String(), previous.Match.String()).Updates(map[string]any{
+		"type":       domain.Type.String(),
+		"match":      domain.Match.String(),
+		"expires_at": domain.ExpiresAt,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) || strings.Contains(result.Error.Error(), "duplicate key value violates unique constraint") {
+			return models.ErrDomainAlreadyExists
+		}
 		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		return models.ErrDomainNotFound
+	}
 	return nil
 }
 
 func (r *DomainRepo) Delete(ctx context.Context, domain *models.Domain) error {
-	result := r.db.WithContext(ctx).Delete(&domain)
+	result := conn(ctx, r.db).Where("name = ? AND type = ? AND match = ?", domain.Name, domain.Type.String(), domain.Match.String()).Delete(&Domain{})
 	if result.Error != nil {
 		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		return models.ErrDomainNotFound
+	}
 	return nil
 }
 
-func (r *DomainRepo) MatchEquals(ctx context.Context, name string) (*models.Domain, error) {
+// Restore brings back the most recently deleted domain with the name, zero domainType and domainMatch do not filter.
+func (r *DomainRepo) Restore(ctx context.Context, name string, domainType models.Type, domainMatch models.Match) (*models.Domain, error) {
 	var domain Domain
-	result := r.db.WithContext(ctx).First(&domain, "name = ? AND match = ?", name, EqualsMatch)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, models.ErrDomainNotFound
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		deleted := tx.Unscoped().Where("name = ? AND deleted_at IS NOT NULL", name)
+		if domainType != (models.Type{}) {
+			deleted = deleted.Where("type = ?", domainType.String())
+		}
+		if domainMatch != (models.Match{}) {
+			deleted = deleted.Where("match = ?", domainMatch.String())
+		}
+		if err := deleted.Order("deleted_at DESC").First(&domain).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.ErrDomainNotFound
+			}
+			return err
+		}
+		var live int64
+		if err := tx.Model(&Domain{}).Where("name = ? AND type = ? AND match = ?", domain.Name, domain.Type, domain.Match).Count(&live).Error; err != nil {
+			return err
+		}
+		if live > 0 {
+			return models.ErrDomainAlreadyExists
+		}
+		deletedAt := domain.DeletedAt.Time
+		domain.DeletedAt = gorm.DeletedAt{}
+		domain.UpdatedAt = time.Now()
+		return tx.Unscoped().Model(&Domain{}).Where("name = ? AND type = ? AND match = ? AND deleted_at = ?", domain.Name, domain.Type, domain.Match, deletedAt).Updates(map[string]any{
+			"deleted_at": nil,
+			"updated_at": domain.UpdatedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
 	}
+	return DomainToModel(&domain), nil
+}
+
+func (r *DomainRepo) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	result := conn(ctx, r.db).Unscoped().Where("deleted_at < ?", before).Delete(&Domain{})
 	if result.Error != nil {
-		return nil, result.Error
+		return 0, result.Error
 	}
-	return DomainToModel(&domain), nil
+	return int(result.RowsAffected), nil
 }
 
-func (r *DomainRepo) MatchContains(ctx context.Context, name string) (*models.Domain, error) {
-	var domain Domain
-	result := r.db.WithContext(ctx).First(&domain, "name LIKE ? AND match = ?", "%"+name+"%", ContainsMatch)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, models.ErrDomainNotFound
+func (r *DomainRepo) MatchEquals(ctx context.Context, name string) ([]models.Domain, error) {
+	var domains []Domain
+	result := conn(ctx, r.db).Find(&domains, "name = ? AND match = ?", name, EqualsMatch)
+	if result.Error != nil {
+		return nil, result.Error
 	}
+	return DomainListToModelList(domains), nil
+}
+
+func (r *DomainRepo) MatchContains(ctx context.Context, name string) ([]models.Domain, error) {
+	var domains []Domain
+	result := conn(ctx, r.db).Find(&domains, "match = ? AND strpos(?, name) > 0", ContainsMatch, name)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	return DomainToModel(&domain), nil
+	return DomainListToModelList(domains), nil
 }
 
-func (r *DomainRepo) MatchPrefix(ctx context.Context, name string) (*models.Domain, error) {
-	var domain Domain
-	result := r.db.WithContext(ctx).First(&domain, "name LIKE ? AND match = ?", name+"%", PrefixMatch)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, models.ErrDomainNotFound
+func (r *DomainRepo) MatchPrefix(ctx context.Context, name string) ([]models.Domain, error) {
+	var domains []Domain
+	result := conn(ctx, r.db).Find(&domains, "match = ? AND left(?, length(name)) = name", PrefixMatch, name)
+	if result.Error != nil {
+		return nil, result.Error
 	}
+	return DomainListToModelList(domains), nil
+}
+
+func (r *DomainRepo) MatchSuffix(ctx context.Context, name string) ([]models.Domain, error) {
+	var domains []Domain
+	result := conn(ctx, r.db).Find(&domains, "match = ? AND (name = ? OR right(?, length(name) + 1) = '.' || name)", SuffixMatch, name, name)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	return DomainToModel(&domain), nil
+	return DomainListToModelList(domains), nil
 }
 
-func (r *DomainRepo) MatchSuffix(ctx context.Context, name string) (*models.Domain, error) {
-	var domain Domain
-	result := r.db.WithContext(ctx).First(&domain, "name LIKE ? AND match = ?", "%"+name, SuffixMatch)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, models.ErrDomainNotFound
+func (r *DomainRepo) MatchRegistrable(ctx context.Context, name string) ([]models.Domain, error) {
+	var domains []Domain
+	result := conn(ctx, r.db).Find(&domains, "match = ? AND name IN ?", RegistrableMatch, models.DecomposeDomain(name).RegistrableKeys())
+	if result.Error != nil {
+		return nil, result.Error
 	}
+	return DomainListToModelList(domains), nil
+}
+
+func (r *DomainRepo) MatchGlob(ctx context.Context, name string) ([]models.Domain, error) {
+	return r.matchPatterns(ctx, name, GlobMatch)
+}
+
+// MatchRegex evaluates the regex rules in Go, Postgres regular expressions are not RE2.
+func (r *DomainRepo) MatchRegex(ctx context.Context, name string) ([]models.Domain, error) {
+	return r.matchPatterns(ctx, name, RegexMatch)
+}
+
+func (r *DomainRepo) matchPatterns(ctx context.Context, name, match string) ([]models.Domain, error) {
+	var domains []Domain
+	result := conn(ctx, r.db).Find(&domains, "match = ?", match)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	return DomainToModel(&domain), nil
+	return newPatternSet(DomainListToModelList(domains)).match(ctx, name)
 }
 
 func (r *DomainRepo) CountDomainTypes(ctx context.Context) (map[models.Type]int, error) {
@@ -148,7 +404,7 @@ func (r *DomainRepo) CountDomainTypes(ctx context.Context) (map[models.Type]int,
 		Count int
 	}
 
-	if err := r.db.WithContext(ctx).Model(&Domain{}).
+	if err := conn(ctx, r.db).Model(&Domain{}).
 		Select("type, COUNT(*) as count").
 		Group("type").
 		Scan(&typeCounts).Error; err != nil {
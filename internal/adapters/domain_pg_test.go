@@ -0,0 +1,214 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"gorm.io/gorm"
+	"slices"
+	"testing"
+	"time"
+)
+
+// keysetRules share names, so pages can only be told apart by the type and match of a rule.
+var keysetRules = []models.Domain{
+	{Name: "keyset-a.example", Type: models.BlacklistType, Match: models.EqualsMatch},
+	{Name: "keyset-a.example", Type: models.BlacklistType, Match: models.SuffixMatch},
+	{Name: "keyset-a.example", Type: models.WhitelistType, Match: models.EqualsMatch},
+	{Name: "keyset-b.example", Type: models.DisposableType, Match: models.EqualsMatch},
+}
+
+func seedDomains(t *testing.T, db *gorm.DB, rules []models.Domain) *DomainRepo {
+	t.Helper()
+	repo := NewDomainRepo(db)
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	cleanup := func() {
+		db.Unscoped().Where("name IN ?", names).Delete(&Domain{})
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+	for _, rule := range rules {
+		if err := repo.Create(context.Background(), &rule); err != nil {
+			t.Fatalf("create %q: %v", rule.Name, err)
+		}
+	}
+	return repo
+}
+
+func TestDomainRepoSearchSharedNames(t *testing.T) {
+	repo := seedDomains(t, testDB(t), keysetRules)
+	for _, descending := range []bool{false, true} {
+		var got []models.Domain
+		query := models.DomainQuery{NameContains: "keyset-", Sort: models.NameSort, Descending: descending, Limit: 1}
+		for {
+			page, err := repo.Search(context.Background(), query)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			got = append(got, page.Domains...)
+			if page.NextCursor == "" {
+				break
+			}
+			if query.Cursor, err = models.DecodeDomainCursor(page.NextCursor, query.Sort, descending); err != nil {
+				t.Fatalf("decode cursor: %v", err)
+			}
+		}
+		if len(got) != len(keysetRules) {
+			t.Fatalf("descending %t: got %d rules, want %d: %v", descending, len(got), len(keysetRules), got)
+		}
+		for _, rule := range keysetRules {
+			if !slices.ContainsFunc(got, rule.Same) {
+				t.Fatalf("descending %t: the page missed %v", descending, rule)
+			}
+		}
+	}
+}
+
+func TestDomainRepoArchiveExpiredSharedName(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	expired := models.Domain{Name: "archive.example", Type: models.BlacklistType, Match: models.EqualsMatch, ExpiresAt: &expiresAt}
+	permanent := models.Domain{Name: "archive.example", Type: models.WhitelistType, Match: models.SuffixMatch}
+	db := testDB(t)
+	repo := seedDomains(t, db, []models.Domain{expired, permanent})
+	t.Cleanup(func() {
+		db.Where("name = ?", expired.Name).Delete(&ArchivedRule{})
+	})
+
+	archived, err := repo.ArchiveExpired(context.Background(), expiresAt, 10)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if !slices.ContainsFunc(archived, expired.Same) || slices.ContainsFunc(archived, permanent.Same) {
+		t.Fatalf("got %v, want %v archived", archived, expired)
+	}
+	rules, err := repo.MatchSuffix(context.Background(), permanent.Name)
+	if err != nil || len(rules) != 1 || !rules[0].Same(permanent) {
+		t.Fatalf("got %v, %v, the permanent rule has to stay", rules, err)
+	}
+}
+
+func TestDomainRepoImportSharedNames(t *testing.T) {
+	db := testDB(t)
+	repo := seedDomains(t, db, keysetRules[:1])
+	t.Cleanup(func() {
+		db.Unscoped().Where("name LIKE ?", "keyset-%").Delete(&Domain{})
+	})
+	result, err := repo.Import(context.Background(), keysetRules, 2, false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(result.Inserted) != len(keysetRules)-2 || result.Skipped != 1 || len(result.Updated) != 0 {
+		t.Fatalf("got %d inserted, %d updated, %d skipped", len(result.Inserted), len(result.Updated), result.Skipped)
+	}
+	if len(result.Conflicts) != 1 || !result.Conflicts[0].Same(keysetRules[2]) {
+		t.Fatalf("got conflicts %v, want the whitelist rule sharing the name and match of the seeded blacklist one", result.Conflicts)
+	}
+	page, err := repo.Search(context.Background(), models.DomainQuery{NameContains: "keyset-", Sort: models.NameSort, Limit: 10})
+	if err != nil || len(page.Domains) != len(keysetRules)-1 {
+		t.Fatalf("got %v, %v, want every rule but the conflicting one", page, err)
+	}
+}
+
+func TestDomainRepoImportExpiry(t *testing.T) {
+	rule := models.Domain{Name: "import-expiry.example", Type: models.BlacklistType, Match: models.EqualsMatch}
+	repo := seedDomains(t, testDB(t), []models.Domain{rule})
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	temporary := rule
+	temporary.ExpiresAt = &expiresAt
+
+	result, err := repo.Import(context.Background(), []models.Domain{temporary}, 10, false)
+	if err != nil || len(result.Updated) != 1 || len(result.Replaced) != 1 || result.Replaced[0].ExpiresAt != nil {
+		t.Fatalf("got %+v, %v, want the rule updated", result, err)
+	}
+	if result, err = repo.Import(context.Background(), []models.Domain{rule}, 10, false); err != nil || result.Skipped != 1 {
+		t.Fatalf("got %+v, %v, a rule without an expiry has to keep the one stored", result, err)
+	}
+	stored, err := repo.MatchEquals(context.Background(), rule.Name)
+	if err != nil || len(stored) != 1 || stored[0].ExpiresAt == nil || !stored[0].ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("got %v, %v, want the expiry %v", stored, err, expiresAt)
+	}
+}
+
+func TestDomainRepoRestoreAfterRecreate(t *testing.T) {
+	ctx := context.Background()
+	blacklisted := models.Domain{Name: "restore.example", Type: models.BlacklistType, Match: models.EqualsMatch}
+	whitelisted := models.Domain{Name: "restore.example", Type: models.WhitelistType, Match: models.EqualsMatch}
+	repo := seedDomains(t, testDB(t), []models.Domain{blacklisted})
+	if err := repo.Delete(ctx, &blacklisted); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := repo.Create(ctx, &whitelisted); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	restored, err := repo.Restore(ctx, blacklisted.Name, models.Type{}, models.Match{})
+	if err != nil || !restored.Same(blacklisted) {
+		t.Fatalf("got %v, %v, a live rule of another type must not block the restore", restored, err)
+	}
+
+	if err := repo.Delete(ctx, &blacklisted); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := repo.Create(ctx, &blacklisted); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := repo.Restore(ctx, blacklisted.Name, blacklisted.Type, blacklisted.Match); !errors.Is(err, models.ErrDomainAlreadyExists) {
+		t.Fatalf("got %v, want %v for a rule created again", err, models.ErrDomainAlreadyExists)
+	}
+	if restored, err = repo.Restore(ctx, whitelisted.Name, whitelisted.Type, whitelisted.Match); err != nil || !restored.Same(whitelisted) {
+		t.Fatalf("got %v, %v, want %v restored", restored, err, whitelisted)
+	}
+	if _, err := repo.Restore(ctx, whitelisted.Name, models.DisposableType, models.Match{}); !errors.Is(err, models.ErrDomainNotFound) {
+		t.Fatalf("got %v, want %v", err, models.ErrDomainNotFound)
+	}
+}
+
+func TestDomainRepoTrashPagingAndPurge(t *testing.T) {
+	ctx := context.Background()
+	repo := seedDomains(t, testDB(t), keysetRules)
+	for _, name := range []string{"keyset-a.example", "keyset-b.example"} {
+		if err := repo.Delete(ctx, &models.Domain{Name: name}); err != nil {
+			t.Fatalf("delete %q: %v", name, err)
+		}
+	}
+
+	var got []models.Domain
+	query := models.DomainQuery{NameContains: "keyset-", Sort: models.DeletedAtSort, Limit: 1}
+	for {
+		page, err := repo.SearchDeleted(ctx, query)
+		if err != nil {
+			t.Fatalf("search deleted: %v", err)
+		}
+		got = append(got, page.Domains...)
+		if page.NextCursor == "" {
+			break
+		}
+		if query.Cursor, err = models.DecodeDomainCursor(page.NextCursor, query.Sort, false); err != nil {
+			t.Fatalf("decode cursor: %v", err)
+		}
+	}
+	if len(got) != len(keysetRules) {
+		t.Fatalf("got %d deleted rules, want %d: %v", len(got), len(keysetRules), got)
+	}
+	for _, rule := range keysetRules {
+		if !slices.ContainsFunc(got, rule.Same) {
+			t.Fatalf("the trash missed %v", rule)
+		}
+	}
+
+	if purged, err := repo.PurgeDeleted(ctx, got[0].DeletedAt.Add(-time.Second)); err != nil || purged != 0 {
+		t.Fatalf("got %d, %v, rules deleted after the cutoff have to stay", purged, err)
+	}
+	if purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Second)); err != nil || purged < len(keysetRules) {
+		t.Fatalf("got %d, %v, want at least %d purged", purged, err, len(keysetRules))
+	}
+	page, err := repo.SearchDeleted(ctx, models.DomainQuery{NameContains: "keyset-", Sort: models.NameSort, Limit: 10})
+	if err != nil || len(page.Domains) != 0 {
+		t.Fatalf("got %v, %v, want an empty trash", page, err)
+	}
+	if _, err := repo.Restore(ctx, "keyset-a.example", models.Type{}, models.Match{}); !errors.Is(err, models.ErrDomainNotFound) {
+		t.Fatalf("got %v, want %v after the purge", err, models.ErrDomainNotFound)
+	}
+}
@@ -1,20 +1,28 @@
 package adapters
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/aerosystems/checkmail-service/internal/models"
 	"gorm.io/gorm"
 	"strings"
+	"time"
 )
 
 type Filter struct {
-	ProjectToken string
-	Domain
+	ProjectToken string     `gorm:"uniqueIndex:idx_project_token_name_type_match"`
+	Name         string     `gorm:"uniqueIndex:idx_project_token_name_type_match"`
+	Type         string     `gorm:"uniqueIndex:idx_project_token_name_type_match;type:domain_type"`
+	Match        string     `gorm:"uniqueIndex:idx_project_token_name_type_match;type:match_type"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+	ExpiresAt    *time.Time `gorm:"index"`
 }
 
 type FilterRepo struct {
-	db *gorm.DB
+	db       *gorm.DB
+	patterns *patternCache
 }
 
 func NewFilterRepo(db *gorm.DB) *FilterRepo {
@@ -22,20 +30,20 @@ func NewFilterRepo(db *gorm.DB) *FilterRepo {
 		panic(fmt.Sprintf("failed to AutoMigrateGORM Filter model: %v", err))
 	}
 	return &FilterRepo{
-		db: db,
+		db:       db,
+		patterns: newPatternCache(),
 	}
 }
 
 func ModelToFilter(model *models.Filter) *Filter {
 	return &Filter{
 		ProjectToken: model.ProjectToken,
-		Domain: Domain{
-			Name:      model.Name,
-			Type:      model.Type.String(),
-			Match:     model.Match.String(),
-			CreatedAt: model.CreatedAt,
-			UpdatedAt: model.UpdatedAt,
-		},
+		Name:         model.Name,
+		Type:         model.Type.String(),
+		Match:        model.Match.String(),
+		CreatedAt:    model.CreatedAt,
+		UpdatedAt:    model.UpdatedAt,
+		ExpiresAt:    model.ExpiresAt,
 	}
 }
 
@@ -48,119 +56,197 @@ func FilterToModel(filter *Filter) *models.Filter {
 			Match:     models.DomainMatchFromString(filter.Match),
 			CreatedAt: filter.CreatedAt,
 			UpdatedAt: filter.UpdatedAt,
+			ExpiresAt: filter.ExpiresAt,
 		},
 	}
 }
 
-func (r *FilterRepo) FindAll() ([]models.Filter, error) {
+func FilterListToModelList(filters []Filter) []models.Filter {
+	modelList := make([]models.Filter, 0, len(filters))
+	for i := range filters {
+		modelList = append(modelList, *FilterToModel(&filters[i]))
+	}
+	return modelList
+}
+
+func (r *FilterRepo) FindAll(ctx context.Context) ([]models.Filter, error) {
 	var filters []Filter
-	result := r.db.Find(&filters)
+	result := conn(ctx, r.db).Order("project_token, name, type, match").Find(&filters)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	var models []models.Filter
-	for _, filter := range filters {
-		models = append(models, *FilterToModel(&filter))
+	return FilterListToModelList(filters), nil
+}
+
+// FindByName leaves out the type or match left zero, and reports several filters matching as ambiguous.
+func (r *FilterRepo) FindByName(ctx context.Context, projectToken, name string, filterType models.Type, filterMatch models.Match) (*models.Filter, error) {
+	db := conn(ctx, r.db).Where("project_token = ? AND name = ?", projectToken, name)
+	if filterType != (models.Type{}) {
+		db = db.Where("type = ?", filterType.String())
+	}
+	if filterMatch != (models.Match{}) {
+		db = db.Where("match = ?", filterMatch.String())
+	}
+	var filters []Filter
+	if err := db.Limit(2).Find(&filters).Error; err != nil {
+		return nil, fmt.Errorf("error finding filter by name: %w", err)
+	}
+	switch len(filters) {
+	case 0:
+		return nil, models.ErrFilterNotFound
+	case 1:
+		return FilterToModel(&filters[0]), nil
+	default:
+		return nil, models.ErrFilterAmbiguous
 	}
-	return models, nil
 }
 
-func (r *FilterRepo) FindByName(name string) (*models.Filter, error) {
-	var filter Filter
-	result := r.db.First(&filter, "name = ?", name)
+func (r *FilterRepo) FindByProjectToken(ctx context.Context, projectToken string) ([]models.Filter, error) {
+	var filters []Filter
+	result := conn(ctx, r.db).Order("name, type, match").Find(&filters, "project_token = ?", projectToken)
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, models.ErrDomainNotFound
-		}
-		return nil, fmt.Errorf("error finding domain by name: %w", result.Error)
+		return nil, result.Error
 	}
-	return FilterToModel(&filter), nil
+	return FilterListToModelList(filters), nil
 }
 
-func (r *FilterRepo) FindByProjectToken(projectToken string) ([]models.Filter, error) {
+func (r *FilterRepo) FindBatch(ctx context.Context, query models.FilterQuery) ([]models.Filter, error) {
+	db := conn(ctx, r.db).Where("project_token = ?", query.ProjectToken)
+	if query.After != nil {
+		db = db.Where("(name, type, match) > (?, ?, ?)", query.After.Name, query.After.Type.String(), query.After.Match.String())
+	}
+	if query.Type != (models.Type{}) {
+		db = db.Where("type = ?", query.Type.String())
+	}
+	if query.Match != (models.Match{}) {
+		db = db.Where("match = ?", query.Match.String())
+	}
 	var filters []Filter
-	result := r.db.Find(&filters, "project_token = ?", projectToken)
+	result := db.Order("name, type, match").Limit(query.Limit).Find(&filters)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	var models []models.Filter
-	for _, filter := range filters {
-		models = append(models, *FilterToModel(&filter))
-	}
-	return models, nil
+	return FilterListToModelList(filters), nil
 }
 
-func (r *FilterRepo) Create(filter *models.Filter) error {
-	result := r.db.Create(ModelToFilter(filter))
+func (r *FilterRepo) Create(ctx context.Context, filter *models.Filter) error {
+	filterModel := ModelToFilter(filter)
+	result := conn(ctx, r.db).Create(filterModel)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) || strings.Contains(result.Error.Error(), "duplicate key value violates unique constraint") {
-			return models.ErrDomainNotFound
+			return models.ErrFilterAlreadyExists
 		}
 		return result.Error
 	}
+	filter.CreatedAt = filterModel.CreatedAt
+	filter.UpdatedAt = filterModel.UpdatedAt
 	return nil
 }
 
-func (r *FilterRepo) CreateOrUpdate(filter *models.Filter) error {
-	result := r.db.Save(ModelToFilter(filter))
+func (r *FilterRepo) CreateOrUpdate(ctx context.Context, filter *models.Filter) error {
+	result := conn(ctx, r.db).Save(ModelToFilter(filter))
 	if result.Error != nil {
 		return result.Error
 	}
+	r.patterns.evict(filter.Name)
 	return nil
 }
 
-func (r *FilterRepo) Delete(filter *models.Filter) error {
-	result := r.db.Delete(&filter)
+func (r *FilterRepo) Update(ctx context.Context, filter *models.Filter) error {
+	result := conn(ctx, r.db).Model(&Filter{}).Where("project_token = ? AND name = ? AND type = ? AND match = ?", filter.ProjectToken, filter.Name, filter.Type.String(), filter.Match.String()).Updates(map[string]any{
+		"expires_at": filter.ExpiresAt,
+		"updated_at": time.Now(),
+	})
 	if result.Error != nil {
 		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		return models.ErrFilterNotFound
+	}
+	r.patterns.evict(filter.Name)
 	return nil
 }
 
-func (r *FilterRepo) MatchEquals(name, projectToken string) (*models.Filter, error) {
-	var filter Filter
-	result := r.db.First(&filter, "project_token = ? AND name = ? AND match = ?", projectToken, name, EqualsMatch)
+func (r *FilterRepo) Delete(ctx context.Context, filter *models.Filter) error {
+	result := conn(ctx, r.db).Where("project_token = ? AND name = ? AND type = ? AND match = ?", filter.ProjectToken, filter.Name, filter.Type.String(), filter.Match.String()).Delete(&Filter{})
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, models.ErrDomainNotFound
+		return result.Error
 	}
-	return FilterToModel(&filter), nil
+	if result.RowsAffected == 0 {
+		return models.ErrFilterNotFound
+	}
+	r.patterns.evict(filter.Name)
+	return nil
 }
 
-func (r *FilterRepo) MatchContains(name, projectToken string) (*models.Filter, error) {
-	var filter Filter
-	result := r.db.First(&filter, "project_token = ? AND name LIKE ? AND match = ?", projectToken, "%"+name+"%", ContainsMatch)
+func (r *FilterRepo) MatchEquals(ctx context.Context, name, projectToken string) ([]models.Filter, error) {
+	var filters []Filter
+	result := conn(ctx, r.db).Find(&filters, "project_token = ? AND name = ? AND match = ?", projectToken, name, EqualsMatch)
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
 		return nil, result.Error
 	}
-	return FilterToModel(&filter), nil
+	return FilterListToModelList(filters), nil
 }
 
-func (r *FilterRepo) MatchPrefix(name, projectToken string) (*models.Filter, error) {
-	var filter Filter
-	result := r.db.First(&filter, "project_token = ? AND name LIKE ? AND match = ?", projectToken, name+"%", "begins")
+func (r *FilterRepo) MatchContains(ctx context.Context, name, projectToken string) ([]models.Filter, error) {
+	var filters []Filter
+	result := conn(ctx, r.db).Find(&filters, "project_token = ? AND match = ? AND strpos(?, name) > 0", projectToken, ContainsMatch, name)
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
 		return nil, result.Error
 	}
-	return FilterToModel(&filter), nil
+	return FilterListToModelList(filters), nil
 }
 
-func (r *FilterRepo) MatchSuffix(name, projectToken string) (*models.Filter, error) {
-	var filter Filter
-	result := r.db.First(&filter, "project_token = ? AND name LIKE ? AND match = ?", projectToken, "%"+name, "ends")
+func (r *FilterRepo) MatchPrefix(ctx context.Context, name, projectToken string) ([]models.Filter, error) {
+	var filters []Filter
+	result := conn(ctx, r.db).Find(&filters, "project_token = ? AND match = ? AND left(?, length(name)) = name", projectToken, PrefixMatch, name)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return FilterListToModelList(filters), nil
+}
+
+func (r *FilterRepo) MatchRegistrable(ctx context.Context, name, projectToken string) ([]models.Filter, error) {
+	var filters []Filter
+	result := conn(ctx, r.db).Find(&filters, "project_token = ? AND match = ? AND name IN ?", projectToken, RegistrableMatch, models.DecomposeDomain(name).RegistrableKeys())
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
+		return nil, result.Error
+	}
+	return FilterListToModelList(filters), nil
+}
+
+func (r *FilterRepo) MatchGlob(ctx context.Context, name, projectToken string) ([]models.Filter, error) {
+	return r.matchPatterns(ctx, name, projectToken, GlobMatch)
+}
+
+// MatchRegex evaluates the regex rules in Go, Postgres regular expressions are not RE2.
+func (r *FilterRepo) MatchRegex(ctx context.Context, name, projectToken string) ([]models.Filter, error) {
+	return r.matchPatterns(ctx, name, projectToken, RegexMatch)
+}
+
+func (r *FilterRepo) matchPatterns(ctx context.Context, name, projectToken, match string) ([]models.Filter, error) {
+	var filters []Filter
+	result := conn(ctx, r.db).Find(&filters, "project_token = ? AND match = ?", projectToken, match)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	var matched []models.Filter
+	for idx, filter := range FilterListToModelList(filters) {
+		if idx%patternCheckInterval == 0 && ctx.Err() != nil {
+			return matched, ctx.Err()
 		}
+		if re := r.patterns.compile(filter.Domain); re != nil && re.MatchString(name) {
+			matched = append(matched, filter)
+		}
+	}
+	return matched, nil
+}
+
+func (r *FilterRepo) MatchSuffix(ctx context.Context, name, projectToken string) ([]models.Filter, error) {
+	var filters []Filter
+	result := conn(ctx, r.db).Find(&filters, "project_token = ? AND match = ? AND (name = ? OR right(?, length(name) + 1) = '.' || name)", projectToken, SuffixMatch, name, name)
+	if result.Error != nil {
 		return nil, result.Error
 	}
-	return FilterToModel(&filter), nil
+	return FilterListToModelList(filters), nil
 }
@@ -1 +1,94 @@
 package adapters
+
+import (
+	"context"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/aerosystems/common-service/gen/protobuf/lookup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+type LookupAdapter struct {
+	client       lookup.LookupServiceClient
+	timeout      time.Duration
+	retries      int
+	retryBackoff time.Duration
+	breaker      *circuitBreaker
+}
+
+func NewLookupAdapter(conn grpc.ClientConnInterface, timeout time.Duration, retries int, retryBackoff time.Duration, breakerThreshold int, breakerCooldown time.Duration) *LookupAdapter {
+	return &LookupAdapter{
+		client:       lookup.NewLookupServiceClient(conn),
+		timeout:      timeout,
+		retries:      max(retries, 0),
+		retryBackoff: retryBackoff,
+		breaker:      newCircuitBreaker(breakerThreshold, breakerCooldown),
+	}
+}
+
+func NewLookupClientConn(address string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("could not create lookup service client: %w", err)
+	}
+	return conn, nil
+}
+
+func (a *LookupAdapter) Lookup(ctx context.Context, domainName string) (models.Type, error) {
+	if err := a.breaker.allow(); err != nil {
+		return models.UndefinedType, err
+	}
+
+	var err error
+	for attempt := 0; attempt <= a.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				a.breaker.cancel()
+				return models.UndefinedType, ctx.Err()
+			case <-time.After(time.Duration(attempt) * a.retryBackoff):
+			}
+		}
+
+		var res *lookup.LookupResponse
+		res, err = a.lookup(ctx, domainName)
+		if err == nil {
+			a.breaker.done(true)
+			return models.DomainTypeFromString(res.DomainType), nil
+		}
+		if !isRetryable(err) || ctx.Err() != nil {
+			break
+		}
+	}
+	// only transient failures count against the service, a rejected request is not a sign of an unhealthy one
+	// and a call the caller gave up on tells nothing about it
+	switch {
+	case ctx.Err() != nil || status.Code(err) == codes.Canceled:
+		a.breaker.cancel()
+	default:
+		a.breaker.done(!isRetryable(err))
+	}
+	return models.UndefinedType, fmt.Errorf("lookup of %s failed: %w", domainName, err)
+}
+
+func (a *LookupAdapter) lookup(ctx context.Context, domainName string) (*lookup.LookupResponse, error) {
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+	return a.client.Lookup(ctx, &lookup.LookupRequest{Domain: domainName})
+}
+
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,207 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/aerosystems/common-service/gen/protobuf/lookup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeLookupServer struct {
+	lookup.UnimplementedLookupServiceServer
+	calls   atomic.Int32
+	respond func(ctx context.Context, call int) (*lookup.LookupResponse, error)
+}
+
+func (s *fakeLookupServer) Lookup(ctx context.Context, _ *lookup.LookupRequest) (*lookup.LookupResponse, error) {
+	return s.respond(ctx, int(s.calls.Add(1)-1))
+}
+
+func newBufconnLookupConn(t *testing.T, server *fakeLookupServer) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	lookup.RegisterLookupServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+	})
+	return conn
+}
+
+func respondWith(codeByCall ...codes.Code) func(context.Context, int) (*lookup.LookupResponse, error) {
+	return func(_ context.Context, call int) (*lookup.LookupResponse, error) {
+		code := codes.OK
+		if call < len(codeByCall) {
+			code = codeByCall[call]
+		}
+		if code != codes.OK {
+			return nil, status.Error(code, code.String())
+		}
+		return &lookup.LookupResponse{DomainType: models.BlacklistType.String()}, nil
+	}
+}
+
+func TestLookupAdapterRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		codes     []codes.Code
+		retries   int
+		wantType  models.Type
+		wantCode  codes.Code
+		wantCalls int32
+	}{
+		{"success", nil, 2, models.BlacklistType, codes.OK, 1},
+		{"transient failures are retried", []codes.Code{codes.Unavailable, codes.ResourceExhausted}, 2, models.BlacklistType, codes.OK, 3},
+		{"retries run out", []codes.Code{codes.Unavailable, codes.Unavailable, codes.Unavailable}, 2, models.UndefinedType, codes.Unavailable, 3},
+		{"rejected request is not retried", []codes.Code{codes.InvalidArgument}, 2, models.UndefinedType, codes.InvalidArgument, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeLookupServer{respond: respondWith(tt.codes...)}
+			adapter := NewLookupAdapter(newBufconnLookupConn(t, server), time.Second, tt.retries, time.Millisecond, 10, time.Minute)
+
+			got, err := adapter.Lookup(context.Background(), "example.com")
+			if got != tt.wantType || status.Code(errors.Unwrap(err)) != tt.wantCode {
+				t.Fatalf("got %s, %v, want %s, %s", got, err, tt.wantType, tt.wantCode)
+			}
+			if calls := server.calls.Load(); calls != tt.wantCalls {
+				t.Fatalf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestLookupAdapterAttemptTimeout(t *testing.T) {
+	server := &fakeLookupServer{respond: func(ctx context.Context, call int) (*lookup.LookupResponse, error) {
+		if call == 0 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &lookup.LookupResponse{DomainType: models.WhitelistType.String()}, nil
+	}}
+	adapter := NewLookupAdapter(newBufconnLookupConn(t, server), 50*time.Millisecond, 1, time.Millisecond, 10, time.Minute)
+
+	got, err := adapter.Lookup(context.Background(), "example.com")
+	if err != nil || got != models.WhitelistType {
+		t.Fatalf("got %s, %v, want %s", got, err, models.WhitelistType)
+	}
+}
+
+func TestLookupAdapterCircuitBreaker(t *testing.T) {
+	healthy := atomic.Bool{}
+	server := &fakeLookupServer{respond: func(context.Context, int) (*lookup.LookupResponse, error) {
+		if !healthy.Load() {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		}
+		return &lookup.LookupResponse{DomainType: models.BlacklistType.String()}, nil
+	}}
+	cooldown := 100 * time.Millisecond
+	adapter := NewLookupAdapter(newBufconnLookupConn(t, server), time.Second, 0, time.Millisecond, 2, cooldown)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := adapter.Lookup(ctx, "example.com"); status.Code(errors.Unwrap(err)) != codes.Unavailable {
+			t.Fatalf("got %v, want %s", err, codes.Unavailable)
+		}
+	}
+	if _, err := adapter.Lookup(ctx, "example.com"); !errors.Is(err, errCircuitOpen) {
+		t.Fatalf("got %v, want %v", err, errCircuitOpen)
+	}
+	if calls := server.calls.Load(); calls != 2 {
+		t.Fatalf("got %d calls while open, want 2", calls)
+	}
+
+	time.Sleep(cooldown)
+	if _, err := adapter.Lookup(ctx, "example.com"); status.Code(errors.Unwrap(err)) != codes.Unavailable {
+		t.Fatalf("got %v from the failing probe, want %s", err, codes.Unavailable)
+	}
+	if _, err := adapter.Lookup(ctx, "example.com"); !errors.Is(err, errCircuitOpen) {
+		t.Fatalf("got %v after the failing probe, want %v", err, errCircuitOpen)
+	}
+
+	time.Sleep(cooldown)
+	healthy.Store(true)
+	for range 3 {
+		if got, err := adapter.Lookup(ctx, "example.com"); err != nil || got != models.BlacklistType {
+			t.Fatalf("got %s, %v after the successful probe, want %s", got, err, models.BlacklistType)
+		}
+	}
+}
+
+func TestLookupAdapterCallerCancellation(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(context.Context, int) (*lookup.LookupResponse, error)
+		ctx     func() (context.Context, context.CancelFunc)
+	}{
+		{
+			name:    "during backoff",
+			respond: respondWith(codes.Unavailable),
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+		},
+		{
+			name: "deadline during a call",
+			respond: func(ctx context.Context, _ int) (*lookup.LookupResponse, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeLookupServer{respond: tt.respond}
+			adapter := NewLookupAdapter(newBufconnLookupConn(t, server), time.Second, 1, time.Minute, 1, time.Minute)
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			if _, err := adapter.Lookup(ctx, "example.com"); err == nil {
+				t.Fatal("got no error from a cancelled lookup")
+			}
+			if err := adapter.breaker.allow(); err != nil {
+				t.Fatalf("cancelled lookup counted against the breaker: %v", err)
+			}
+		})
+	}
+}
+
+func TestLookupAdapterCanceledByServer(t *testing.T) {
+	server := &fakeLookupServer{respond: respondWith(codes.Unavailable, codes.Canceled, codes.Unavailable)}
+	adapter := NewLookupAdapter(newBufconnLookupConn(t, server), time.Second, 0, time.Millisecond, 2, time.Minute)
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := adapter.Lookup(ctx, "example.com"); err == nil {
+			t.Fatal("got no error")
+		}
+	}
+	if _, err := adapter.Lookup(ctx, "example.com"); !errors.Is(err, errCircuitOpen) {
+		t.Fatalf("got %v, want %v: a cancelled call must not reset the failures", err, errCircuitOpen)
+	}
+}
@@ -0,0 +1,189 @@
+package adapters
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/aerosystems/common-service/pkg/gormclient"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"io"
+	"os"
+	"slices"
+	"testing"
+)
+
+// testDB connects to POSTGRES_TEST_DSN and skips the test without one, each test cleans up the rows it created.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	db := gormclient.NewPostgresDB(log, dsn)
+	if err := AutoMigrateGORM(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+var matchRules = []models.Domain{
+	{Name: "mail.com", Type: models.BlacklistType, Match: models.EqualsMatch},
+	{Name: "mailinator.com", Type: models.BlacklistType, Match: models.SuffixMatch},
+	{Name: "example.co.uk", Type: models.WhitelistType, Match: models.RegistrableMatch},
+	{Name: "tk", Type: models.BlacklistType, Match: models.RegistrableMatch},
+	{Name: "mail-", Type: models.DisposableType, Match: models.PrefixMatch},
+	{Name: "spam", Type: models.BlacklistType, Match: models.ContainsMatch},
+	{Name: "*.xyz", Type: models.BlacklistType, Match: models.GlobMatch},
+	{Name: `mail-[0-9]+\.xyz`, Type: models.DisposableType, Match: models.RegexMatch},
+}
+
+var matchCases = []struct {
+	match models.Match
+	input string
+	want  []string
+}{
+	{models.EqualsMatch, "mail.com", []string{"mail.com"}},
+	{models.EqualsMatch, "eu.mail.com", nil},
+	{models.EqualsMatch, "mail.co", nil},
+	{models.SuffixMatch, "mailinator.com", []string{"mailinator.com"}},
+	{models.SuffixMatch, "foo.mailinator.com", []string{"mailinator.com"}},
+	{models.SuffixMatch, "xmailinator.com", nil},
+	{models.SuffixMatch, "mailinator.com.evil", nil},
+	{models.SuffixMatch, "inator.com", nil},
+	{models.RegistrableMatch, "example.co.uk", []string{"example.co.uk"}},
+	{models.RegistrableMatch, "mx.mail.example.co.uk", []string{"example.co.uk"}},
+	{models.RegistrableMatch, "foo.tk", []string{"tk"}},
+	{models.RegistrableMatch, "example.co.uk.evil", nil},
+	{models.RegistrableMatch, "notexample.co.uk", nil},
+	{models.PrefixMatch, "mail-123.xyz", []string{"mail-"}},
+	{models.PrefixMatch, "xmail-123.xyz", nil},
+	{models.PrefixMatch, "mail", nil},
+	{models.ContainsMatch, "myspamsite.com", []string{"spam"}},
+	{models.ContainsMatch, "spam", []string{"spam"}},
+	{models.ContainsMatch, "spa.com", nil},
+	{models.GlobMatch, "a.xyz", []string{"*.xyz"}},
+	{models.GlobMatch, "xyz", nil},
+	{models.GlobMatch, "a.xyz.com", nil},
+	{models.RegexMatch, "mail-42.xyz", []string{`mail-[0-9]+\.xyz`}},
+	{models.RegexMatch, "mail-x.xyz", nil},
+	{models.RegexMatch, "xmail-42.xyz", nil},
+	{models.RegexMatch, "mail-42.xyz.com", nil},
+}
+
+type matchByMatch func(ctx context.Context, match models.Match, name string) ([]models.Domain, error)
+
+func checkMatchCases(t *testing.T, matchBy matchByMatch) {
+	t.Helper()
+	for _, tt := range matchCases {
+		t.Run(tt.match.String()+"/"+tt.input, func(t *testing.T) {
+			rules, err := matchBy(context.Background(), tt.match, tt.input)
+			if err != nil {
+				t.Fatalf("match: %v", err)
+			}
+			var got []string
+			for _, rule := range rules {
+				if rule.Match != tt.match {
+					t.Errorf("got a %s rule %q", rule.Match, rule.Name)
+				}
+				got = append(got, rule.Name)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainIndexMatch(t *testing.T) {
+	idx := newDomainIndex(matchRules)
+	checkMatchCases(t, func(ctx context.Context, match models.Match, name string) ([]models.Domain, error) {
+		switch match {
+		case models.EqualsMatch:
+			return idx.matchEquals(name), nil
+		case models.SuffixMatch:
+			return idx.matchSuffix(name), nil
+		case models.RegistrableMatch:
+			return idx.matchRegistrable(name), nil
+		case models.PrefixMatch:
+			return idx.matchPrefix(name), nil
+		case models.ContainsMatch:
+			return idx.matchContains(name), nil
+		case models.GlobMatch:
+			return idx.glob.match(ctx, name)
+		default:
+			return idx.regex.match(ctx, name)
+		}
+	})
+}
+
+func TestDomainRepoMatch(t *testing.T) {
+	repo := seedDomains(t, testDB(t), matchRules)
+
+	checkMatchCases(t, func(ctx context.Context, match models.Match, name string) ([]models.Domain, error) {
+		switch match {
+		case models.EqualsMatch:
+			return repo.MatchEquals(ctx, name)
+		case models.SuffixMatch:
+			return repo.MatchSuffix(ctx, name)
+		case models.RegistrableMatch:
+			return repo.MatchRegistrable(ctx, name)
+		case models.PrefixMatch:
+			return repo.MatchPrefix(ctx, name)
+		case models.ContainsMatch:
+			return repo.MatchContains(ctx, name)
+		case models.GlobMatch:
+			return repo.MatchGlob(ctx, name)
+		default:
+			return repo.MatchRegex(ctx, name)
+		}
+	})
+}
+
+func TestFilterRepoMatch(t *testing.T) {
+	db := testDB(t)
+	repo := NewFilterRepo(db)
+	const projectToken, otherProjectToken = "match-test-project", "match-test-other-project"
+	cleanup := func() {
+		db.Where("project_token IN ?", []string{projectToken, otherProjectToken}).Delete(&Filter{})
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+	for _, rule := range matchRules {
+		if err := repo.Create(context.Background(), &models.Filter{ProjectToken: projectToken, Domain: rule}); err != nil {
+			t.Fatalf("create %q: %v", rule.Name, err)
+		}
+	}
+	// Every input matches a contains rule of another project, which must not leak into the results.
+	if err := repo.Create(context.Background(), &models.Filter{ProjectToken: otherProjectToken, Domain: models.Domain{Name: ".", Type: models.BlacklistType, Match: models.ContainsMatch}}); err != nil {
+		t.Fatalf("create filter of another project: %v", err)
+	}
+
+	checkMatchCases(t, func(ctx context.Context, match models.Match, name string) ([]models.Domain, error) {
+		var filters []models.Filter
+		var err error
+		switch match {
+		case models.EqualsMatch:
+			filters, err = repo.MatchEquals(ctx, name, projectToken)
+		case models.SuffixMatch:
+			filters, err = repo.MatchSuffix(ctx, name, projectToken)
+		case models.RegistrableMatch:
+			filters, err = repo.MatchRegistrable(ctx, name, projectToken)
+		case models.PrefixMatch:
+			filters, err = repo.MatchPrefix(ctx, name, projectToken)
+		case models.ContainsMatch:
+			filters, err = repo.MatchContains(ctx, name, projectToken)
+		case models.GlobMatch:
+			filters, err = repo.MatchGlob(ctx, name, projectToken)
+		default:
+			filters, err = repo.MatchRegex(ctx, name, projectToken)
+		}
+		domains := make([]models.Domain, 0, len(filters))
+		for _, filter := range filters {
+			domains = append(domains, filter.Domain)
+		}
+		return domains, err
+	})
+}
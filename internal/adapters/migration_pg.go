@@ -22,8 +22,42 @@ func AutoMigrateGORM(db *gorm.DB) error {
 		return fmt.Errorf("failed to create enum types: %v", err)
 	}
 
-	if err := db.AutoMigrate(&Domain{}, &Filter{}, &Review{}, &Access{}); err != nil {
+	if err := db.Exec(`ALTER TYPE domain_type ADD VALUE IF NOT EXISTS 'disposable'`).Error; err != nil {
+		return fmt.Errorf("failed to extend domain_type enum: %v", err)
+	}
+	for _, match := range []string{GlobMatch, RegexMatch, RegistrableMatch} {
+		if err := db.Exec(fmt.Sprintf(`ALTER TYPE match_type ADD VALUE IF NOT EXISTS '%s'`, match)).Error; err != nil {
+			return fmt.Errorf("failed to extend match_type enum: %v", err)
+		}
+	}
+
+	// The unique index on domains was replaced by one on the live rows only, when soft deletion came in.
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_name_type_match`).Error; err != nil {
+		return fmt.Errorf("failed to drop the unique index on domains: %v", err)
+	}
+
+	// A project may keep several filters with a name, one per type and match, like the domains.
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_project_token_name`).Error; err != nil {
+		return fmt.Errorf("failed to drop the unique index on filters: %v", err)
+	}
+
+	if err := db.AutoMigrate(&Domain{}, &Filter{}, &Review{}, &Access{}, &Provider{}, &AuditLog{}, &ArchivedRule{}); err != nil {
 		return fmt.Errorf("failed to AutoMigrateGORM: %v", err)
 	}
+
+	if err := db.Exec(`
+		CREATE OR REPLACE FUNCTION reject_audit_log_change() RETURNS trigger AS $$ BEGIN
+			RAISE EXCEPTION 'audit_logs is append-only';
+		END $$ LANGUAGE plpgsql;
+		DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+		CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+			FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_log_change();
+	`).Error; err != nil {
+		return fmt.Errorf("failed to protect audit_logs: %v", err)
+	}
+
+	if err := SeedProviders(db); err != nil {
+		return fmt.Errorf("failed to seed providers: %v", err)
+	}
 	return nil
 }
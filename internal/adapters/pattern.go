@@ -0,0 +1,102 @@
+package adapters
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"regexp"
+	"sync"
+)
+
+const patternCheckInterval = 64
+
+// maxCachedPatterns bounds a patternCache, filters changed through other instances are never evicted from it.
+const maxCachedPatterns = 4096
+
+// patternCache caches invalid patterns as nil.
+type patternCache struct {
+	mu       sync.Mutex
+	patterns map[patternKey]*regexp.Regexp
+}
+
+type patternKey struct {
+	match models.Match
+	name  string
+}
+
+func newPatternCache() *patternCache {
+	return &patternCache{patterns: make(map[patternKey]*regexp.Regexp)}
+}
+
+func (c *patternCache) compile(rule models.Domain) *regexp.Regexp {
+	key := patternKey{match: rule.Match, name: rule.Name}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if re, ok := c.patterns[key]; ok {
+		return re
+	}
+	re, err := models.CompilePattern(rule.Name, rule.Match)
+	if err != nil {
+		re = nil
+	}
+	if len(c.patterns) >= maxCachedPatterns {
+		clear(c.patterns)
+	}
+	c.patterns[key] = re
+	return re
+}
+
+func (c *patternCache) evict(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.patterns, patternKey{match: models.GlobMatch, name: name})
+	delete(c.patterns, patternKey{match: models.RegexMatch, name: name})
+}
+
+type patternSet struct {
+	patterns []compiledPattern
+}
+
+type compiledPattern struct {
+	rule models.Domain
+	re   *regexp.Regexp
+}
+
+func newPatternSet(rules []models.Domain) *patternSet {
+	set := &patternSet{}
+	for _, rule := range rules {
+		set.insert(rule)
+	}
+	return set
+}
+
+// insert skips invalid patterns, they could only have been stored before patterns were validated.
+func (s *patternSet) insert(rule models.Domain) {
+	if re, err := models.CompilePattern(rule.Name, rule.Match); err == nil {
+		s.patterns = append(s.patterns, compiledPattern{rule: rule, re: re})
+	}
+}
+
+func (s *patternSet) remove(rule models.Domain) {
+	patterns := s.patterns[:0]
+	for _, pattern := range s.patterns {
+		if !pattern.rule.Same(rule) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	clear(s.patterns[len(patterns):])
+	s.patterns = patterns
+}
+
+// match returns the rules matched so far along with the context error once ctx is done.
+func (s *patternSet) match(ctx context.Context, name string) ([]models.Domain, error) {
+	var rules []models.Domain
+	for idx, pattern := range s.patterns {
+		if idx%patternCheckInterval == 0 && ctx.Err() != nil {
+			return rules, ctx.Err()
+		}
+		if pattern.re.MatchString(name) {
+			rules = append(rules, pattern.rule)
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,33 @@
+package adapters
+
+import (
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"testing"
+)
+
+func TestPatternCache(t *testing.T) {
+	cache := newPatternCache()
+	rule := models.Domain{Name: `mail-[0-9]+\.xyz`, Match: models.RegexMatch}
+	re := cache.compile(rule)
+	if re == nil || !re.MatchString("mail-1.xyz") {
+		t.Fatalf("got %v, want a compiled pattern", re)
+	}
+	if cache.compile(rule) != re {
+		t.Fatal("the pattern was compiled again")
+	}
+	if cache.compile(models.Domain{Name: `(`, Match: models.RegexMatch}) != nil {
+		t.Fatal("got an expression for an invalid pattern")
+	}
+
+	cache.evict(rule.Name)
+	if _, ok := cache.patterns[patternKey{match: rule.Match, name: rule.Name}]; ok {
+		t.Fatal("the pattern was not evicted")
+	}
+	for i := range maxCachedPatterns + 1 {
+		cache.compile(models.Domain{Name: fmt.Sprintf("*.%d", i), Match: models.GlobMatch})
+	}
+	if len(cache.patterns) > maxCachedPatterns {
+		t.Fatalf("got %d cached patterns, want at most %d", len(cache.patterns), maxCachedPatterns)
+	}
+}
@@ -0,0 +1,182 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"gorm.io/gorm"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Provider struct {
+	Domain          string    `gorm:"primaryKey"`
+	CanonicalDomain string    `gorm:"not null"`
+	IgnoreDots      bool      `gorm:"not null;default:false"`
+	TagSeparators   string    `gorm:"not null;default:''"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+// ProviderRepo drops its snapshot of the providers table on every mutation and reloads it once it is older than cacheTTL,
+// so changes made by other instances are picked up within cacheTTL.
+type ProviderRepo struct {
+	db       *gorm.DB
+	cacheTTL time.Duration
+
+	mu       sync.RWMutex
+	cache    map[string]models.Provider
+	loadedAt time.Time
+}
+
+func NewProviderRepo(db *gorm.DB, cacheTTL time.Duration) *ProviderRepo {
+	return &ProviderRepo{
+		db:       db,
+		cacheTTL: cacheTTL,
+	}
+}
+
+func ModelToProvider(model *models.Provider) *Provider {
+	return &Provider{
+		Domain:          model.Domain,
+		CanonicalDomain: model.CanonicalDomain,
+		IgnoreDots:      model.IgnoreDots,
+		TagSeparators:   model.TagSeparators,
+		CreatedAt:       model.CreatedAt,
+		UpdatedAt:       model.UpdatedAt,
+	}
+}
+
+func ProviderToModel(provider *Provider) *models.Provider {
+	return &models.Provider{
+		Domain:          provider.Domain,
+		CanonicalDomain: provider.CanonicalDomain,
+		IgnoreDots:      provider.IgnoreDots,
+		TagSeparators:   provider.TagSeparators,
+		CreatedAt:       provider.CreatedAt,
+		UpdatedAt:       provider.UpdatedAt,
+	}
+}
+
+func ProviderListToModelList(providers []Provider) []models.Provider {
+	modelList := make([]models.Provider, 0, len(providers))
+	for i := range providers {
+		modelList = append(modelList, *ProviderToModel(&providers[i]))
+	}
+	return modelList
+}
+
+func SeedProviders(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&Provider{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	providers := make([]Provider, 0, len(models.DefaultProviders))
+	for i := range models.DefaultProviders {
+		providers = append(providers, *ModelToProvider(&models.DefaultProviders[i]))
+	}
+	return db.Create(&providers).Error
+}
+
+func (r *ProviderRepo) FindByDomain(ctx context.Context, domain string) (*models.Provider, error) {
+	r.mu.RLock()
+	cache, loadedAt := r.cache, r.loadedAt
+	r.mu.RUnlock()
+
+	if cache == nil || time.Since(loadedAt) > r.cacheTTL {
+		providers, err := r.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cache = make(map[string]models.Provider, len(providers))
+		for _, provider := range providers {
+			cache[provider.Domain] = provider
+		}
+		r.mu.Lock()
+		r.cache, r.loadedAt = cache, time.Now()
+		r.mu.Unlock()
+	}
+
+	provider, ok := cache[domain]
+	if !ok {
+		return nil, nil
+	}
+	return &provider, nil
+}
+
+func (r *ProviderRepo) FindAll(ctx context.Context) ([]models.Provider, error) {
+	var providers []Provider
+	result := r.db.WithContext(ctx).Order("domain").Find(&providers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ProviderListToModelList(providers), nil
+}
+
+func (r *ProviderRepo) Get(ctx context.Context, domain string) (*models.Provider, error) {
+	var provider Provider
+	result := r.db.WithContext(ctx).First(&provider, "domain = ?", domain)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, models.ErrProviderNotFound
+		}
+		return nil, fmt.Errorf("error finding provider by domain: %w", result.Error)
+	}
+	return ProviderToModel(&provider), nil
+}
+
+func (r *ProviderRepo) Create(ctx context.Context, provider *models.Provider) error {
+	providerModel := ModelToProvider(provider)
+	result := r.db.WithContext(ctx).Create(providerModel)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) || strings.Contains(result.Error.Error(), "duplicate key value violates unique constraint") {
+			return models.ErrProviderAlreadyExists
+		}
+		return result.Error
+	}
+	provider.CreatedAt = providerModel.CreatedAt
+	provider.UpdatedAt = providerModel.UpdatedAt
+	r.invalidate()
+	return nil
+}
+
+func (r *ProviderRepo) Update(ctx context.Context, provider *models.Provider) error {
+	provider.UpdatedAt = time.Now()
+	result := r.db.WithContext(ctx).Model(&Provider{}).Where("domain = ?", provider.Domain).Updates(map[string]any{
+		"canonical_domain": provider.CanonicalDomain,
+		"ignore_dots":      provider.IgnoreDots,
+		"tag_separators":   provider.TagSeparators,
+		"updated_at":       provider.UpdatedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrProviderNotFound
+	}
+	r.invalidate()
+	return nil
+}
+
+func (r *ProviderRepo) Delete(ctx context.Context, domain string) error {
+	result := r.db.WithContext(ctx).Where("domain = ?", domain).Delete(&Provider{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrProviderNotFound
+	}
+	r.invalidate()
+	return nil
+}
+
+func (r *ProviderRepo) invalidate() {
+	r.mu.Lock()
+	r.cache = nil
+	r.mu.Unlock()
+}
@@ -0,0 +1,67 @@
+package adapters
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type txState struct {
+	tx          *gorm.DB
+	afterCommit []func()
+}
+
+type Transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{
+		db: db,
+	}
+}
+
+// Transaction joins the transaction of ctx when there is one.
+func (t *Transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if activeTx(ctx) != nil {
+		return fn(ctx)
+	}
+	state := &txState{}
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	state.tx = nil
+	if err != nil {
+		return err
+	}
+	for _, f := range state.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// activeTx returns the transaction of ctx until it is committed or rolled back, a context may outlive it.
+func activeTx(ctx context.Context) *txState {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.tx != nil {
+		return state
+	}
+	return nil
+}
+
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state := activeTx(ctx); state != nil {
+		return state.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// afterCommit runs fn once the transaction of ctx is committed, so a rolled back change never reaches in-memory state.
+func afterCommit(ctx context.Context, fn func()) {
+	if state := activeTx(ctx); state != nil {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn()
+}
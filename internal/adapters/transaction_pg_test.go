@@ -0,0 +1,54 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/sirupsen/logrus"
+	"io"
+	"testing"
+)
+
+func TestTransactorRollback(t *testing.T) {
+	db := testDB(t)
+	rule := models.Domain{Name: "rollback.example", Type: models.BlacklistType, Match: models.EqualsMatch}
+	cleanup := func() {
+		db.Unscoped().Where("name = ?", rule.Name).Delete(&Domain{})
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	repo := NewDomainMemRepo(log, NewDomainRepo(db), 0)
+	ctx := context.Background()
+	if err := repo.Reload(ctx); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	transactor := NewTransactor(db)
+
+	errAudit := errors.New("audit failed")
+	err := transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, &rule); err != nil {
+			return err
+		}
+		return errAudit
+	})
+	if !errors.Is(err, errAudit) {
+		t.Fatalf("got %v, want %v", err, errAudit)
+	}
+	if _, err := repo.FindByName(ctx, rule.Name, rule.Type, rule.Match); !errors.Is(err, models.ErrDomainNotFound) {
+		t.Fatalf("got %v, the rolled back rule was stored", err)
+	}
+	if rules, _ := repo.MatchEquals(ctx, rule.Name); len(rules) != 0 {
+		t.Fatalf("got %v, the rolled back rule reached the index", rules)
+	}
+
+	if err := transactor.Transaction(ctx, func(ctx context.Context) error {
+		return repo.Create(ctx, &rule)
+	}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rules, _ := repo.MatchEquals(ctx, rule.Name); len(rules) != 1 {
+		t.Fatalf("got %v, the committed rule is missing from the index", rules)
+	}
+}
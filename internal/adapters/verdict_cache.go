@@ -0,0 +1,135 @@
+package adapters
+
+import (
+	"container/list"
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"sync"
+	"time"
+)
+
+// VerdictLRU expires entries after ttl, which bounds how long changes made through other instances stay unnoticed.
+type VerdictLRU struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu         sync.Mutex
+	entries    map[verdictKey]*list.Element
+	order      *list.List
+	generation uint64
+	stats      models.CacheStats
+}
+
+type verdictKey struct {
+	domainName   string
+	projectToken string
+}
+
+type verdictEntry struct {
+	key       verdictKey
+	verdict   *models.Verdict
+	expiresAt time.Time
+}
+
+func NewVerdictLRU(ttl time.Duration, maxEntries int) *VerdictLRU {
+	return &VerdictLRU{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[verdictKey]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Load does not store a computed verdict when an invalidation happened in the meantime,
+// since it may have been resolved from the rules before the change.
+// A verdict of a temporary rule is kept until the rule expires at most.
+func (c *VerdictLRU) Load(ctx context.Context, domainName, projectToken string, resolve func(ctx context.Context) (*models.Verdict, error)) (*models.Verdict, error) {
+	key := verdictKey{domainName: domainName, projectToken: projectToken}
+	now := time.Now()
+
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*verdictEntry)
+		if now.Before(entry.expiresAt) {
+			c.order.MoveToFront(element)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return entry.verdict.Clone(), nil
+		}
+		c.removeElement(element)
+	}
+	c.stats.Misses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	verdict, err := resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return verdict, nil
+	}
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+	expiresAt := now.Add(c.ttl)
+	if verdict.Rule != nil && verdict.Rule.ExpiresAt != nil && verdict.Rule.ExpiresAt.Before(expiresAt) {
+		expiresAt = *verdict.Rule.ExpiresAt
+	}
+	c.entries[key] = c.order.PushFront(&verdictEntry{key: key, verdict: verdict.Clone(), expiresAt: expiresAt})
+	for c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+		c.stats.Evictions++
+	}
+	return verdict, nil
+}
+
+// Invalidate drops the verdicts in every project when projectToken is empty, the way global rules apply.
+func (c *VerdictLRU) Invalidate(_ context.Context, projectToken string, rules ...models.Domain) {
+	matchers := make([]func(name string) bool, 0, len(rules))
+	for _, rule := range rules {
+		matchers = append(matchers, rule.Matcher())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for key, element := range c.entries {
+		if projectToken != "" && key.projectToken != projectToken {
+			continue
+		}
+		for _, matches := range matchers {
+			if matches(key.domainName) {
+				c.removeElement(element)
+				c.stats.Invalidations++
+				break
+			}
+		}
+	}
+}
+
+func (c *VerdictLRU) Purge(_ context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.stats.Invalidations += uint64(c.order.Len())
+	c.entries = make(map[verdictKey]*list.Element)
+	c.order.Init()
+}
+
+func (c *VerdictLRU) Stats(_ context.Context) models.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.order.Len()
+	stats.Capacity = c.maxEntries
+	return stats
+}
+
+func (c *VerdictLRU) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*verdictEntry).key)
+}
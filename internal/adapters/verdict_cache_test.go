@@ -0,0 +1,191 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+// countingResolve resolves to rule and counts the calls, so a test can tell a hit from a miss.
+func countingResolve(calls *int, rule *models.Domain) func(ctx context.Context) (*models.Verdict, error) {
+	return func(context.Context) (*models.Verdict, error) {
+		*calls++
+		if rule == nil {
+			return &models.Verdict{Source: models.UndefinedSource}, nil
+		}
+		return &models.Verdict{Rule: rule, Source: models.GlobalSource}, nil
+	}
+}
+
+func TestVerdictLRUHitAndMiss(t *testing.T) {
+	ctx := context.Background()
+	cache := NewVerdictLRU(time.Minute, 10)
+	rule := &models.Domain{Name: "spam.com", Type: models.BlacklistType, Match: models.EqualsMatch}
+	var calls int
+
+	for range 3 {
+		verdict, err := cache.Load(ctx, "spam.com", "", countingResolve(&calls, rule))
+		if err != nil || verdict.Rule == nil || !verdict.Rule.Same(*rule) {
+			t.Fatalf("got %+v, %v, want the verdict of %v", verdict, err, rule)
+		}
+	}
+	if _, err := cache.Load(ctx, "spam.com", "project", countingResolve(&calls, nil)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("resolved %d times, want once per domain and project", calls)
+	}
+	if stats := cache.Stats(ctx); stats.Hits != 2 || stats.Misses != 2 || stats.Size != 2 {
+		t.Fatalf("got %+v, want 2 hits, 2 misses and 2 entries", stats)
+	}
+}
+
+func TestVerdictLRUDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	cache := NewVerdictLRU(time.Minute, 10)
+	failure := errors.New("database is down")
+	if _, err := cache.Load(ctx, "spam.com", "", func(context.Context) (*models.Verdict, error) { return nil, failure }); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	var calls int
+	if _, err := cache.Load(ctx, "spam.com", "", countingResolve(&calls, nil)); err != nil || calls != 1 {
+		t.Fatalf("got %v after %d calls, a failure must not be cached", err, calls)
+	}
+}
+
+func TestVerdictLRUExpiry(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+
+	cache := NewVerdictLRU(time.Millisecond, 10)
+	cache.Load(ctx, "spam.com", "", countingResolve(&calls, nil))
+	time.Sleep(2 * time.Millisecond)
+	cache.Load(ctx, "spam.com", "", countingResolve(&calls, nil))
+	if calls != 2 {
+		t.Fatalf("resolved %d times, an entry older than the ttl must be resolved again", calls)
+	}
+
+	calls = 0
+	cache = NewVerdictLRU(time.Hour, 10)
+	expiresAt := time.Now().Add(time.Millisecond)
+	temporary := &models.Domain{Name: "spam.com", Type: models.BlacklistType, Match: models.EqualsMatch, ExpiresAt: &expiresAt}
+	cache.Load(ctx, "spam.com", "", countingResolve(&calls, temporary))
+	time.Sleep(2 * time.Millisecond)
+	cache.Load(ctx, "spam.com", "", countingResolve(&calls, nil))
+	if calls != 2 {
+		t.Fatalf("resolved %d times, the verdict of a temporary rule must expire with it", calls)
+	}
+}
+
+func TestVerdictLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := NewVerdictLRU(time.Minute, 2)
+	var calls int
+	for _, name := range []string{"a.com", "b.com", "a.com", "c.com"} {
+		cache.Load(ctx, name, "", countingResolve(&calls, nil))
+	}
+	calls = 0
+	cache.Load(ctx, "a.com", "", countingResolve(&calls, nil))
+	cache.Load(ctx, "c.com", "", countingResolve(&calls, nil))
+	if calls != 0 {
+		t.Fatalf("resolved %d times, the recently used entries must stay", calls)
+	}
+	cache.Load(ctx, "b.com", "", countingResolve(&calls, nil))
+	if stats := cache.Stats(ctx); calls != 1 || stats.Evictions != 2 || stats.Size != 2 {
+		t.Fatalf("got %d calls and %+v, want b.com evicted", calls, stats)
+	}
+}
+
+func TestVerdictLRUSkipsVerdictsResolvedBeforeAnInvalidation(t *testing.T) {
+	ctx := context.Background()
+	cache := NewVerdictLRU(time.Minute, 10)
+	rule := models.Domain{Name: "spam.com", Type: models.BlacklistType, Match: models.EqualsMatch}
+	stale := func(context.Context) (*models.Verdict, error) {
+		cache.Invalidate(ctx, "", rule)
+		return &models.Verdict{Source: models.UndefinedSource}, nil
+	}
+	if _, err := cache.Load(ctx, "spam.com", "", stale); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if stats := cache.Stats(ctx); stats.Size != 0 {
+		t.Fatalf("got %+v, a verdict resolved across an invalidation must not be stored", stats)
+	}
+
+	cache.Purge(ctx)
+	var calls int
+	cache.Load(ctx, "spam.com", "", countingResolve(&calls, nil))
+	cache.Purge(ctx)
+	cache.Load(ctx, "spam.com", "", countingResolve(&calls, nil))
+	if calls != 2 {
+		t.Fatalf("resolved %d times, a purge must drop every entry", calls)
+	}
+}
+
+func TestVerdictLRUInvalidate(t *testing.T) {
+	cached := []verdictKey{
+		{domainName: "spam.com"},
+		{domainName: "mail.spam.com"},
+		{domainName: "spam.org"},
+		{domainName: "spam.com", projectToken: "project-a"},
+		{domainName: "spam.com", projectToken: "project-b"},
+	}
+	tests := []struct {
+		name         string
+		projectToken string
+		rule         models.Domain
+		want         []verdictKey
+	}{
+		{
+			name: "equals",
+			rule: models.Domain{Name: "spam.com", Match: models.EqualsMatch},
+			want: []verdictKey{cached[0], cached[3], cached[4]},
+		},
+		{
+			name: "suffix",
+			rule: models.Domain{Name: "spam.com", Match: models.SuffixMatch},
+			want: []verdictKey{cached[0], cached[1], cached[3], cached[4]},
+		},
+		{
+			name: "glob",
+			rule: models.Domain{Name: "spam.*", Match: models.GlobMatch},
+			want: []verdictKey{cached[0], cached[2], cached[3], cached[4]},
+		},
+		{
+			name: "regex",
+			rule: models.Domain{Name: `mail\..+`, Match: models.RegexMatch},
+			want: []verdictKey{cached[1]},
+		},
+		{
+			name:         "project filter",
+			projectToken: "project-a",
+			rule:         models.Domain{Name: "spam.com", Match: models.EqualsMatch},
+			want:         []verdictKey{cached[3]},
+		},
+		{
+			name: "unrelated",
+			rule: models.Domain{Name: "example.com", Match: models.SuffixMatch},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewVerdictLRU(time.Minute, 10)
+			for _, key := range cached {
+				cache.Load(ctx, key.domainName, key.projectToken, countingResolve(new(int), nil))
+			}
+			cache.Invalidate(ctx, tt.projectToken, tt.rule)
+			for _, key := range cached {
+				_, kept := cache.entries[key]
+				if dropped := !kept; dropped != slices.Contains(tt.want, key) {
+					t.Errorf("%+v: dropped %t", key, dropped)
+				}
+			}
+			if stats := cache.Stats(ctx); stats.Invalidations != uint64(len(tt.want)) {
+				t.Errorf("got %d invalidations, want %d", stats.Invalidations, len(tt.want))
+			}
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package models
 
 import (
+	"github.com/google/uuid"
 	"time"
 )
 
 type Access struct {
 	Token            string
+	CustomerUUID     uuid.UUID
 	SubscriptionType SubscriptionType
 	AccessCount      int
 	AccessTime       time.Time
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 500
+)
+
+type AuditAction struct {
+	slug string
+}
+
+var (
+	UndefinedAuditAction = AuditAction{"undefined"}
+	CreateAuditAction    = AuditAction{"create"}
+	UpdateAuditAction    = AuditAction{"update"}
+	DeleteAuditAction    = AuditAction{"delete"}
+	ExpireAuditAction    = AuditAction{"expire"}
+	RestoreAuditAction   = AuditAction{"restore"}
+)
+
+func (a AuditAction) String() string {
+	return a.slug
+}
+
+func AuditActionFromString(action string) AuditAction {
+	switch action {
+	case CreateAuditAction.String():
+		return CreateAuditAction
+	case UpdateAuditAction.String():
+		return UpdateAuditAction
+	case DeleteAuditAction.String():
+		return DeleteAuditAction
+	case ExpireAuditAction.String():
+		return ExpireAuditAction
+	case RestoreAuditAction.String():
+		return RestoreAuditAction
+	default:
+		return UndefinedAuditAction
+	}
+}
+
+// Before is nil for a created or restored rule, After for a deleted or expired one.
+type AuditEntry struct {
+	Id           int
+	Action       AuditAction
+	ProjectToken string
+	DomainName   string
+	ActorUUID    uuid.UUID
+	ActorRole    Role
+	Reason       string
+	RequestID    string
+	Before       *Domain
+	After        *Domain
+	CreatedAt    time.Time
+}
+
+type AuditQuery struct {
+	DomainName   string
+	ProjectToken string
+	ActorUUID    uuid.UUID
+	BeforeId     int
+	Limit        int
+}
+
+type AuditPage struct {
+	Entries    []AuditEntry
+	NextBefore int
+}
+
+type AuditMeta struct {
+	ActorUUID uuid.UUID
+	ActorRole Role
+	Reason    string
+	RequestID string
+}
+
+type auditMetaKey struct{}
+
+func WithAuditMeta(ctx context.Context, meta AuditMeta) context.Context {
+	return context.WithValue(ctx, auditMetaKey{}, meta)
+}
+
+func AuditMetaFromContext(ctx context.Context) AuditMeta {
+	meta, ok := ctx.Value(auditMetaKey{}).(AuditMeta)
+	if !ok {
+		return AuditMeta{ActorRole: UnknownRole}
+	}
+	return meta
+}
+
+func NewAuditEntry(ctx context.Context, projectToken string, before, after *Domain) AuditEntry {
+	meta := AuditMetaFromContext(ctx)
+	entry := AuditEntry{
+		Action:       UndefinedAuditAction,
+		ProjectToken: projectToken,
+		ActorUUID:    meta.ActorUUID,
+		ActorRole:    meta.ActorRole,
+		Reason:       meta.Reason,
+		RequestID:    meta.RequestID,
+	}
+	switch {
+	case before == nil && after != nil:
+		entry.Action, entry.DomainName = CreateAuditAction, after.Name
+	case before != nil && after == nil:
+		entry.Action, entry.DomainName = DeleteAuditAction, before.Name
+	case before != nil && after != nil:
+		entry.Action, entry.DomainName = UpdateAuditAction, after.Name
+	}
+	if before != nil {
+		rule := *before
+		entry.Before = &rule
+	}
+	if after != nil {
+		rule := *after
+		entry.After = &rule
+	}
+	return entry
+}
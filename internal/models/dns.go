@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type RouteStatus struct {
+	slug string
+}
+
+var (
+	UndefinedRouteStatus = RouteStatus{"undefined"}
+	MXRouteStatus        = RouteStatus{"mx"}
+	// AddressRouteStatus is the implicit MX of RFC 5321, the A/AAAA records of a domain without MX records.
+	AddressRouteStatus  = RouteStatus{"address"}
+	NullMXRouteStatus   = RouteStatus{"null_mx"}
+	NoRouteStatus       = RouteStatus{"no_route"}
+	NotExistRouteStatus = RouteStatus{"not_exist"}
+)
+
+func (s RouteStatus) String() string {
+	return s.slug
+}
+
+func (s RouteStatus) AcceptsMail() bool {
+	return s == MXRouteStatus || s == AddressRouteStatus
+}
+
+type MailRoute struct {
+	Status  RouteStatus
+	MXHosts []string
+	// TTL is how long the route may be cached, negative answers are cached for the SOA minimum TTL (RFC 2308).
+	TTL time.Duration
+}
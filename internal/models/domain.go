@@ -2,6 +2,8 @@ package models
 
 import (
 	"regexp"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -11,6 +13,20 @@ type Domain struct {
 	Match     Match
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	ExpiresAt *time.Time
+	DeletedAt *time.Time
+}
+
+func (d Domain) Expired(now time.Time) bool {
+	return d.ExpiresAt != nil && !now.Before(*d.ExpiresAt)
+}
+
+func (d Domain) Same(other Domain) bool {
+	return d.Name == other.Name && d.Type == other.Type && d.Match == other.Match
+}
+
+func (d Domain) SuggestionCandidate(now time.Time) bool {
+	return d.Type == WhitelistType && (d.Match == EqualsMatch || d.Match == SuffixMatch) && strings.Contains(d.Name, ".") && !d.Expired(now)
 }
 
 type Type struct {
@@ -18,9 +34,10 @@ type Type struct {
 }
 
 var (
-	UndefinedType = Type{"undefined"}
-	BlacklistType = Type{"blacklist"}
-	WhitelistType = Type{"whitelist"}
+	UndefinedType  = Type{"undefined"}
+	BlacklistType  = Type{"blacklist"}
+	WhitelistType  = Type{"whitelist"}
+	DisposableType = Type{"disposable"}
 )
 
 func (d Type) String() string {
@@ -33,21 +50,27 @@ func DomainTypeFromString(s string) Type {
 		return BlacklistType
 	case WhitelistType.String():
 		return WhitelistType
+	case DisposableType.String():
+		return DisposableType
 	default:
 		return UndefinedType
 	}
 }
 
+// Match defines how a stored rule name is compared with the inspected domain, the rule always matches the input.
 type Match struct {
 	slug string
 }
 
 var (
-	UndefinedMatch = Match{"undefined"}
-	PrefixMatch    = Match{"prefix"}
-	SuffixMatch    = Match{"suffix"}
-	EqualsMatch    = Match{"equals"}
-	ContainsMatch  = Match{"contains"}
+	UndefinedMatch   = Match{"undefined"}
+	PrefixMatch      = Match{"prefix"}
+	SuffixMatch      = Match{"suffix"}
+	EqualsMatch      = Match{"equals"}
+	ContainsMatch    = Match{"contains"}
+	GlobMatch        = Match{"glob"}
+	RegexMatch       = Match{"regex"}
+	RegistrableMatch = Match{"registrable"}
 )
 
 func (d Match) String() string {
@@ -64,6 +87,12 @@ func DomainMatchFromString(s string) Match {
 		return EqualsMatch
 	case ContainsMatch.String():
 		return ContainsMatch
+	case GlobMatch.String():
+		return GlobMatch
+	case RegexMatch.String():
+		return RegexMatch
+	case RegistrableMatch.String():
+		return RegistrableMatch
 	default:
 		return UndefinedMatch
 	}
@@ -76,3 +105,27 @@ func ValidateDomainName(domainName string) error {
 	}
 	return nil
 }
+
+// Matcher matches an A-label domain the way the repositories do, an invalid pattern matches nothing.
+func (d Domain) Matcher() func(name string) bool {
+	switch d.Match {
+	case EqualsMatch:
+		return func(name string) bool { return name == d.Name }
+	case SuffixMatch:
+		return func(name string) bool { return name == d.Name || strings.HasSuffix(name, "."+d.Name) }
+	case RegistrableMatch:
+		return func(name string) bool { return slices.Contains(DecomposeDomain(name).RegistrableKeys(), d.Name) }
+	case PrefixMatch:
+		return func(name string) bool { return strings.HasPrefix(name, d.Name) }
+	case ContainsMatch:
+		return func(name string) bool { return strings.Contains(name, d.Name) }
+	case GlobMatch, RegexMatch:
+		re, err := CompilePattern(d.Name, d.Match)
+		if err != nil {
+			return func(string) bool { return false }
+		}
+		return re.MatchString
+	default:
+		return func(string) bool { return false }
+	}
+}
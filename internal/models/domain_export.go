@@ -0,0 +1,32 @@
+package models
+
+type ExportFormat struct {
+	slug string
+}
+
+var (
+	UndefinedExportFormat = ExportFormat{"undefined"}
+	CSVExportFormat       = ExportFormat{"csv"}
+	NDJSONExportFormat    = ExportFormat{"ndjson"}
+	HostsExportFormat     = ExportFormat{"hosts"}
+	RPZExportFormat       = ExportFormat{"rpz"}
+)
+
+func (f ExportFormat) String() string {
+	return f.slug
+}
+
+func ExportFormatFromString(s string) ExportFormat {
+	switch s {
+	case CSVExportFormat.String():
+		return CSVExportFormat
+	case NDJSONExportFormat.String():
+		return NDJSONExportFormat
+	case HostsExportFormat.String():
+		return HostsExportFormat
+	case RPZExportFormat.String():
+		return RPZExportFormat
+	default:
+		return UndefinedExportFormat
+	}
+}
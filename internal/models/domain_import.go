@@ -0,0 +1,64 @@
+package models
+
+const MaxImportErrors = 100
+
+type ImportFormat struct {
+	slug string
+}
+
+var (
+	UndefinedImportFormat = ImportFormat{"undefined"}
+	CSVImportFormat       = ImportFormat{"csv"}
+	NDJSONImportFormat    = ImportFormat{"ndjson"}
+	TextImportFormat      = ImportFormat{"text"}
+)
+
+func (f ImportFormat) String() string {
+	return f.slug
+}
+
+func ImportFormatFromString(s string) ImportFormat {
+	switch s {
+	case CSVImportFormat.String():
+		return CSVImportFormat
+	case NDJSONImportFormat.String():
+		return NDJSONImportFormat
+	case TextImportFormat.String():
+		return TextImportFormat
+	default:
+		return UndefinedImportFormat
+	}
+}
+
+type ImportResult struct {
+	Inserted []Domain
+	Updated  []Domain
+	// Replaced holds the updated rules as they were before.
+	Replaced []Domain
+	// Conflicts holds the rules left out because a live rule gives the name and coverage another type.
+	Conflicts []Domain
+	Skipped   int
+}
+
+type ImportReport struct {
+	DryRun   bool
+	Inserted int
+	Updated  int
+	Skipped  int
+	Invalid  int
+	Errors   []ImportError
+}
+
+type ImportError struct {
+	Line   int
+	Data   string
+	Reason string
+}
+
+// AddError counts the row as invalid, only the first MaxImportErrors rows are listed.
+func (r *ImportReport) AddError(line int, data, reason string) {
+	r.Invalid++
+	if len(r.Errors) < MaxImportErrors {
+		r.Errors = append(r.Errors, ImportError{Line: line, Data: data, Reason: reason})
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+const (
+	DefaultDomainPageSize = 50
+	MaxDomainPageSize     = 500
+)
+
+type DomainSort struct {
+	slug string
+}
+
+var (
+	NameSort      = DomainSort{"name"}
+	CreatedAtSort = DomainSort{"created_at"}
+	UpdatedAtSort = DomainSort{"updated_at"}
+	DeletedAtSort = DomainSort{"deleted_at"}
+)
+
+func (s DomainSort) String() string {
+	return s.slug
+}
+
+func DomainSortFromString(s string) DomainSort {
+	switch s {
+	case NameSort.String(), "":
+		return NameSort
+	case CreatedAtSort.String():
+		return CreatedAtSort
+	case UpdatedAtSort.String():
+		return UpdatedAtSort
+	default:
+		return DomainSort{}
+	}
+}
+
+// DomainSelector picks one of the rules sharing a name, Type and Match may be left empty when the name is unique.
+type DomainSelector struct {
+	Name  string
+	Type  string
+	Match string
+}
+
+type DomainSearch struct {
+	Type         string
+	Match        string
+	NameContains string
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+	UpdatedFrom  time.Time
+	UpdatedTo    time.Time
+	Sort         string
+	Descending   bool
+	Cursor       string
+	Limit        int
+}
+
+// DomainQuery time ranges include their start and exclude their end.
+type DomainQuery struct {
+	Type         Type
+	Match        Match
+	NameContains string
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+	UpdatedFrom  time.Time
+	UpdatedTo    time.Time
+	Sort         DomainSort
+	Descending   bool
+	Cursor       *DomainCursor
+	Limit        int
+}
+
+type DomainPage struct {
+	Domains    []Domain
+	NextCursor string
+}
+
+// DomainCursor breaks ties by name, type and match, which identify a rule.
+// A cursor is only valid for the sort and direction it was issued for.
+type DomainCursor struct {
+	Sort       string    `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	Name       string    `json:"n"`
+	Type       string    `json:"y"`
+	Match      string    `json:"m"`
+	Time       time.Time `json:"t,omitempty"`
+}
+
+func NewDomainCursor(domain Domain, sort DomainSort, descending bool) *DomainCursor {
+	cursor := &DomainCursor{
+		Sort:       sort.String(),
+		Descending: descending,
+		Name:       domain.Name,
+		Type:       domain.Type.String(),
+		Match:      domain.Match.String(),
+	}
+	switch sort {
+	case CreatedAtSort:
+		cursor.Time = domain.CreatedAt
+	case UpdatedAtSort:
+		cursor.Time = domain.UpdatedAt
+	case DeletedAtSort:
+		if domain.DeletedAt != nil {
+			cursor.Time = *domain.DeletedAt
+		}
+	}
+	return cursor
+}
+
+func (c *DomainCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeDomainCursor(s string, sort DomainSort, descending bool) (*DomainCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor DomainCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != sort.String() || cursor.Descending != descending {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -14,6 +14,28 @@ var (
 	ErrInvalidDomain           = customerrors.InternalError{Message: "Invalid domain name", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
 	ErrDomainNotFound          = customerrors.InternalError{Message: "Domain not found", HttpCode: http.StatusNotFound, GrpcCode: codes.NotFound}
 	ErrDomainAlreadyExists     = customerrors.InternalError{Message: "Domain already exists", HttpCode: http.StatusConflict, GrpcCode: codes.AlreadyExists}
+	ErrDomainAmbiguous         = customerrors.InternalError{Message: "Several domain rules have the name, give the type and coverage of one", HttpCode: http.StatusConflict, GrpcCode: codes.FailedPrecondition}
+	ErrFilterNotFound          = customerrors.InternalError{Message: "Filter not found", HttpCode: http.StatusNotFound, GrpcCode: codes.NotFound}
+	ErrFilterAlreadyExists     = customerrors.InternalError{Message: "Filter already exists", HttpCode: http.StatusConflict, GrpcCode: codes.AlreadyExists}
+	ErrFilterAmbiguous         = customerrors.InternalError{Message: "Several filters of the project have the name, give the type and coverage of one", HttpCode: http.StatusConflict, GrpcCode: codes.FailedPrecondition}
+	ErrProviderNotFound        = customerrors.InternalError{Message: "Provider not found", HttpCode: http.StatusNotFound, GrpcCode: codes.NotFound}
+	ErrProviderAlreadyExists   = customerrors.InternalError{Message: "Provider already exists", HttpCode: http.StatusConflict, GrpcCode: codes.AlreadyExists}
+	ErrInvalidPattern          = customerrors.InternalError{Message: "Invalid regex or glob pattern", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrDomainNotRegistrable    = customerrors.InternalError{Message: "Domain is neither a registrable domain nor a public suffix", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrInvalidCursor           = customerrors.InternalError{Message: "Invalid cursor", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrInvalidSort             = customerrors.InternalError{Message: "Invalid sort field", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrInvalidTypeFilter       = customerrors.InternalError{Message: "Invalid type filter", HttpCode: http.StatusUnprocessableEntity, GrpcCode: codes.InvalidArgument}
+	ErrInvalidCoverageFilter   = customerrors.InternalError{Message: "Invalid coverage filter", HttpCode: http.StatusUnprocessableEntity, GrpcCode: codes.InvalidArgument}
+	ErrInvalidTimeRange        = customerrors.InternalError{Message: "Invalid time range", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrInvalidExpiry           = customerrors.InternalError{Message: "Expiry must be in the future", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrInvalidTagSeparators    = customerrors.InternalError{Message: "Invalid tag separators", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrProjectNotFound         = customerrors.InternalError{Message: "Project not found", HttpCode: http.StatusNotFound, GrpcCode: codes.NotFound}
+	ErrProjectAccessDenied     = customerrors.InternalError{Message: "Access to project denied", HttpCode: http.StatusForbidden, GrpcCode: codes.PermissionDenied}
+	ErrBatchIsEmpty            = customerrors.InternalError{Message: "Batch is empty", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrImportTooLarge          = customerrors.InternalError{Message: "Import is too large", HttpCode: http.StatusRequestEntityTooLarge, GrpcCode: codes.InvalidArgument}
+	ErrInvalidImportFormat     = customerrors.InternalError{Message: "Invalid import format", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrInvalidExportFormat     = customerrors.InternalError{Message: "Invalid export format", HttpCode: http.StatusBadRequest, GrpcCode: codes.InvalidArgument}
+	ErrBatchTooLarge           = customerrors.InternalError{Message: "Batch is too large", HttpCode: http.StatusRequestEntityTooLarge, GrpcCode: codes.InvalidArgument}
 )
 
 var (
@@ -22,4 +44,5 @@ var (
 	ErrDomainNotExist     = customerrors.ExternalError{Code: 400003, Message: "domain does not exist", HttpCode: 400}
 	ErrDomainTrustedTypes = customerrors.ExternalError{Code: 400003, Message: "domain type does not exist in trusted types", HttpCode: 400}
 	ErrDomainCoverage     = customerrors.ExternalError{Code: 400004, Message: "domain coverage does not exist in trusted coverages", HttpCode: 400}
+	ErrDomainNoMail       = customerrors.ExternalError{Code: 400005, Message: "domain does not accept email", HttpCode: 400}
 )
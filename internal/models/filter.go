@@ -4,3 +4,12 @@ type Filter struct {
 	ProjectToken string
 	Domain
 }
+
+type FilterQuery struct {
+	ProjectToken string
+	Type         Type
+	Match        Match
+	// After is the last filter of the previous batch, batches are ordered by name, type and match.
+	After *Domain
+	Limit int
+}
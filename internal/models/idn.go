@@ -0,0 +1,59 @@
+package models
+
+import (
+	"golang.org/x/net/idna"
+	"strings"
+	"unicode/utf8"
+)
+
+// idnaProfile does not enforce STD3 rules, so underscore labels such as _dmarc stay valid.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
+// DomainToASCII only lowercases ASCII names, so rule fragments such as a "mail-" prefix are kept as they are.
+func DomainToASCII(name string) (string, error) {
+	if isASCII(name) {
+		return strings.ToLower(name), nil
+	}
+	ascii, err := idnaProfile.ToASCII(name)
+	if err != nil {
+		return "", ErrDomainNotValid
+	}
+	return ascii, nil
+}
+
+// RuleNames returns the names a rule may be stored under, as written for patterns and in A-labels otherwise.
+func RuleNames(name string) []string {
+	ascii, err := DomainToASCII(name)
+	if err != nil || ascii == name {
+		return []string{name}
+	}
+	return []string{name, ascii}
+}
+
+func DomainToUnicode(name string) string {
+	if !strings.Contains(name, "xn--") {
+		return name
+	}
+	unicode, err := idnaProfile.ToUnicode(name)
+	if err != nil {
+		return name
+	}
+	return unicode
+}
+
+func AddressToUnicode(address string) string {
+	idx := strings.LastIndex(address, "@")
+	if idx < 0 {
+		return address
+	}
+	return address[:idx+1] + DomainToUnicode(address[idx+1:])
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDomainToASCII(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"example.com", "example.com", false},
+		{"Example.COM", "example.com", false},
+		{"_dmarc.example.com", "_dmarc.example.com", false},
+		{"mail-", "mail-", false},
+		{"bücher.de", "xn--bcher-kva.de", false},
+		{"BÜCHER.de", "xn--bcher-kva.de", false},
+		{"straße.de", "xn--strae-oqa.de", false},
+		{"münchen.例え.jp", "xn--mnchen-3ya.xn--r8jz45g.jp", false},
+		{"xn--bcher-kva.de", "xn--bcher-kva.de", false},
+		{"aب.com", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DomainToASCII(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrDomainNotValid) {
+				t.Fatalf("got %v, want %v", err, ErrDomainNotValid)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainToUnicodeRoundTrip(t *testing.T) {
+	for _, name := range []string{"example.com", "bücher.de", "straße.de", "münchen.例え.jp", "пример.рф"} {
+		t.Run(name, func(t *testing.T) {
+			ascii, err := DomainToASCII(name)
+			if err != nil {
+				t.Fatalf("to ascii: %v", err)
+			}
+			if got := DomainToUnicode(ascii); got != name {
+				t.Fatalf("got %q from %q, want %q", got, ascii, name)
+			}
+			if got := AddressToUnicode("jürgen@" + ascii); got != "jürgen@"+name {
+				t.Fatalf("got %q, want the local part kept and the domain in Unicode", got)
+			}
+		})
+	}
+	if got := DomainToUnicode("xn--a.com"); got != "xn--a.com" {
+		t.Fatalf("got %q, an invalid A-label has to be kept as it is", got)
+	}
+	if got := AddressToUnicode("not an address"); got != "not an address" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRuleNames(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"example.com", []string{"example.com"}},
+		{"bücher.de", []string{"bücher.de", "xn--bcher-kva.de"}},
+		{`\D+\.[A-Z]+`, []string{`\D+\.[A-Z]+`, `\d+\.[a-z]+`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RuleNames(tt.name); !slices.Equal(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+type Source struct {
+	slug string
+}
+
+var (
+	UndefinedSource    = Source{"undefined"}
+	ProjectSource      = Source{"project"}
+	GlobalSource       = Source{"global"}
+	DisposableSource   = Source{"disposable"}
+	DisposableMXSource = Source{"disposable_mx"}
+	LookupSource       = Source{"lookup"}
+)
+
+func (s Source) String() string {
+	return s.slug
+}
+
+type InspectResult struct {
+	Address          string
+	CanonicalAddress string
+	LocalPart        string
+	// Flags never affect the verdict.
+	Flags []Flag
+	// Domain is the domain as it was given, rules are matched against it first.
+	Domain string
+	// CanonicalDomain is the domain of the canonical address, rules are matched against it when none matches Domain.
+	CanonicalDomain   string
+	RegistrableDomain string
+	Type              Type
+	Source            Source
+	Rule              *Domain
+	SuggestedDomain   string
+	SuggestedAddress  string
+	MailRoute         *MailRoute
+	// RemainingQuota is negative when the quota is unlimited, streamed results count the units still leased as remaining.
+	RemainingQuota int
+	Latency        time.Duration
+}
+
+func (r *InspectResult) SetRule(rule *Domain, source Source) {
+	r.Rule = rule
+	r.Type = rule.Type
+	r.Source = source
+}
+
+// InspectItem is the outcome of a single entry of a batch inspection, either Result or Err is set.
+type InspectItem struct {
+	Data   string
+	Result *InspectResult
+	Err    error
+}
+
+type SuggestionError struct {
+	Err              error
+	SuggestedDomain  string
+	SuggestedAddress string
+}
+
+func (e SuggestionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e SuggestionError) Unwrap() error {
+	return e.Err
+}
+
+type Decision struct {
+	slug string
+}
+
+var (
+	NoMatchDecision      = Decision{"no_match"}
+	SingleMatchDecision  = Decision{"single_match"}
+	PrecedenceDecision   = Decision{"precedence"}
+	LongestMatchDecision = Decision{"longest_match"}
+	TieBreakDecision     = Decision{"tie_break"}
+)
+
+func (d Decision) String() string {
+	return d.slug
+}
+
+type RuleMatch struct {
+	Rule     Domain
+	Source   Source
+	Selected bool
+}
+
+type Explanation struct {
+	Result *InspectResult
+	// Matches are ordered by precedence, the strongest first.
+	Matches  []RuleMatch
+	Decision Decision
+	TieBreak TieBreak
+}
@@ -0,0 +1,76 @@
+package models
+
+import "sync"
+
+// QuotaLease is a part of the project quota reserved up front by a long-lived inspection stream.
+type QuotaLease struct {
+	ProjectToken string
+
+	mu    sync.Mutex
+	units int
+	// stored is what the Access row had left after the last refill, negative when the quota is unlimited.
+	stored int
+}
+
+// QuotaRefill is a chunk of quota moved from the Access row into a lease.
+type QuotaRefill struct {
+	Units  int
+	Stored int
+}
+
+func NewQuotaLease(projectToken string) *QuotaLease {
+	return &QuotaLease{ProjectToken: projectToken}
+}
+
+func (l *QuotaLease) Take(refill func() (QuotaRefill, error)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.units == 0 {
+		r, err := refill()
+		if err != nil {
+			return err
+		}
+		l.units += r.Units
+		l.stored = r.Stored
+	}
+	l.units--
+	return nil
+}
+
+func (l *QuotaLease) Extend(r QuotaRefill) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.units += r.Units
+	l.stored = r.Stored
+}
+
+// Remaining counts the units still in the lease as the project's, since they are given back when the stream closes.
+// It does not see quota used by other requests since the last refill, and is negative when the quota is unlimited.
+func (l *QuotaLease) Remaining() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stored < 0 {
+		return -1
+	}
+	return l.stored + l.units
+}
+
+func (l *QuotaLease) Put() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.units++
+}
+
+// Drain empties the lease only if fn succeeds, so a failed give-back can be retried.
+func (l *QuotaLease) Drain(fn func(units int) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.units == 0 {
+		return nil
+	}
+	if err := fn(l.units); err != nil {
+		return err
+	}
+	l.units = 0
+	return nil
+}
@@ -0,0 +1,21 @@
+package models
+
+type FlagCode struct {
+	slug string
+}
+
+var (
+	RoleAccountFlag            = FlagCode{"role_account"}
+	RandomStringFlag           = FlagCode{"random_string"}
+	ExcessiveSubaddressingFlag = FlagCode{"excessive_subaddressing"}
+	NumericOnlyFlag            = FlagCode{"numeric_only"}
+)
+
+func (f FlagCode) String() string {
+	return f.slug
+}
+
+type Flag struct {
+	Code   FlagCode
+	Detail string
+}
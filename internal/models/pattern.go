@@ -0,0 +1,66 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxPatternLength bounds regex and glob rules, RE2 guarantees linear time matching but not a small automaton.
+const MaxPatternLength = 256
+
+func (d Match) IsPattern() bool {
+	return d == RegexMatch || d == GlobMatch
+}
+
+// CompilePattern anchors the pattern to the whole domain, in glob rules "*" matches dots too.
+func CompilePattern(name string, match Match) (*regexp.Regexp, error) {
+	if name == "" || len(name) > MaxPatternLength {
+		return nil, ErrInvalidPattern
+	}
+	var expr string
+	switch match {
+	case RegexMatch:
+		expr = name
+	case GlobMatch:
+		expr = globToRegex(name)
+	default:
+		return nil, ErrInvalidPattern
+	}
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return nil, ErrInvalidPattern
+	}
+	return re, nil
+}
+
+func globToRegex(glob string) string {
+	var builder strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			builder.WriteString(`.*`)
+		case '?':
+			builder.WriteString(`.`)
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return builder.String()
+}
+
+// NormalizeRuleName keeps patterns as they are and converts domain names to A-labels.
+func NormalizeRuleName(name string, match Match) (string, error) {
+	if match.IsPattern() {
+		if match == GlobMatch {
+			name = strings.ToLower(name)
+		}
+		if _, err := CompilePattern(name, match); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	if match == RegistrableMatch {
+		return normalizeRegistrableRule(name)
+	}
+	return DomainToASCII(name)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type Provider struct {
+	Domain          string
+	CanonicalDomain string
+	IgnoreDots      bool
+	TagSeparators   string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+const AllowedTagSeparators = "+-_="
+
+func (p *Provider) Normalize(localPart string) (string, string) {
+	if idx := strings.IndexAny(localPart, p.TagSeparators); idx > 0 {
+		localPart = localPart[:idx]
+	}
+	if p.IgnoreDots {
+		localPart = strings.ReplaceAll(localPart, ".", "")
+	}
+	domain := p.CanonicalDomain
+	if domain == "" {
+		domain = p.Domain
+	}
+	return localPart, domain
+}
+
+var DefaultProviders = []Provider{
+	{Domain: "gmail.com", CanonicalDomain: "gmail.com", IgnoreDots: true, TagSeparators: "+"},
+	{Domain: "googlemail.com", CanonicalDomain: "gmail.com", IgnoreDots: true, TagSeparators: "+"},
+	{Domain: "outlook.com", CanonicalDomain: "outlook.com", TagSeparators: "+"},
+	{Domain: "hotmail.com", CanonicalDomain: "hotmail.com", TagSeparators: "+"},
+	{Domain: "live.com", CanonicalDomain: "live.com", TagSeparators: "+"},
+	{Domain: "yahoo.com", CanonicalDomain: "yahoo.com", TagSeparators: "-"},
+	{Domain: "icloud.com", CanonicalDomain: "icloud.com", TagSeparators: "+"},
+	{Domain: "me.com", CanonicalDomain: "icloud.com", TagSeparators: "+"},
+	{Domain: "mac.com", CanonicalDomain: "icloud.com", TagSeparators: "+"},
+	{Domain: "proton.me", CanonicalDomain: "proton.me", TagSeparators: "+"},
+	{Domain: "protonmail.com", CanonicalDomain: "proton.me", TagSeparators: "+"},
+	{Domain: "pm.me", CanonicalDomain: "proton.me", TagSeparators: "+"},
+	{Domain: "fastmail.com", CanonicalDomain: "fastmail.com", TagSeparators: "+"},
+	{Domain: "yandex.ru", CanonicalDomain: "yandex.ru", TagSeparators: "+"},
+	{Domain: "ya.ru", CanonicalDomain: "yandex.ru", TagSeparators: "+"},
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"golang.org/x/net/publicsuffix"
+	"strings"
+)
+
+type Decomposition struct {
+	Domain            string
+	Subdomain         string
+	RegistrableDomain string
+	PublicSuffix      string
+	ICANN             bool
+}
+
+func DecomposeDomain(name string) Decomposition {
+	name = strings.TrimSuffix(name, ".")
+	suffix, icann := publicsuffix.PublicSuffix(name)
+	decomposition := Decomposition{
+		Domain:       name,
+		PublicSuffix: suffix,
+		ICANN:        icann,
+	}
+	if registrable, err := publicsuffix.EffectiveTLDPlusOne(name); err == nil {
+		decomposition.RegistrableDomain = registrable
+		decomposition.Subdomain = strings.TrimSuffix(strings.TrimSuffix(name, registrable), ".")
+	}
+	return decomposition
+}
+
+func (d Decomposition) RegistrableKeys() []string {
+	if d.RegistrableDomain == "" {
+		return []string{d.PublicSuffix}
+	}
+	return []string{d.RegistrableDomain, d.PublicSuffix}
+}
+
+func normalizeRegistrableRule(name string) (string, error) {
+	name, err := DomainToASCII(strings.TrimPrefix(name, "."))
+	if err != nil {
+		return "", err
+	}
+	if name == "" || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		return "", ErrDomainNotRegistrable
+	}
+	decomposition := DecomposeDomain(name)
+	if name != decomposition.RegistrableDomain && name != decomposition.PublicSuffix {
+		return "", ErrDomainNotRegistrable
+	}
+	return name, nil
+}
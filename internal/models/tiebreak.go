@@ -0,0 +1,31 @@
+package models
+
+type TieBreak struct {
+	slug string
+}
+
+var (
+	BlacklistTieBreak = TieBreak{"blacklist"}
+	WhitelistTieBreak = TieBreak{"whitelist"}
+)
+
+func (t TieBreak) String() string {
+	return t.slug
+}
+
+// TieBreakFromString falls back to BlacklistTieBreak, so an ambiguous domain is rather rejected than trusted.
+func TieBreakFromString(s string) TieBreak {
+	switch s {
+	case WhitelistTieBreak.String():
+		return WhitelistTieBreak
+	default:
+		return BlacklistTieBreak
+	}
+}
+
+func (t TieBreak) Type() Type {
+	if t == WhitelistTieBreak {
+		return WhitelistType
+	}
+	return BlacklistType
+}
@@ -0,0 +1,32 @@
+package models
+
+type Verdict struct {
+	Rule   *Domain
+	Source Source
+}
+
+// Clone copies the rule too, so a cached verdict is never shared with a result.
+func (v *Verdict) Clone() *Verdict {
+	clone := *v
+	if v.Rule != nil {
+		rule := *v.Rule
+		clone.Rule = &rule
+	}
+	return &clone
+}
+
+type CacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Evictions     uint64
+	Invalidations uint64
+	Size          int
+	Capacity      int
+}
+
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
@@ -0,0 +1,40 @@
+package GRPCServer
+
+import (
+	"context"
+	"firebase.google.com/go/v4/auth"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+const authorizationMetadataKey = "authorization"
+
+type TokenVerifier interface {
+	VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error)
+}
+
+// requireRole checks the Firebase ID token sent as "authorization: Bearer <token>" metadata, the way the HTTP role based auth does.
+func (cs CheckService) requireRole(ctx context.Context, roles ...models.Role) error {
+	values := metadata.ValueFromIncomingContext(ctx, authorizationMetadataKey)
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "missing token")
+	}
+	jwt, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok || jwt == "" {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+	token, err := cs.tokenVerifier.VerifyIDToken(ctx, jwt)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+	role, _ := token.Claims["role"].(string)
+	for _, allowed := range roles {
+		if models.RoleFromString(role) == allowed {
+			return nil
+		}
+	}
+	return status.Error(codes.PermissionDenied, "access denied")
+}
@@ -2,26 +2,108 @@ package GRPCServer
 
 import (
 	"context"
-	"github.com/aerosystems/common-service/gen/protobuf/checkmail"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/common/protobuf/checkmail"
+	"github.com/aerosystems/checkmail-service/internal/models"
 )
 
 type CheckService struct {
-	inspectUsecase InspectUsecase
+	inspectUsecase    InspectUsecase
+	manageUsecase     ManageUsecase
+	tokenVerifier     TokenVerifier
+	streamConcurrency int
 	checkmail.UnimplementedCheckmailServiceServer
 }
 
-func NewCheckService(inspectUsecase InspectUsecase) *CheckService {
+func NewCheckService(inspectUsecase InspectUsecase, manageUsecase ManageUsecase, tokenVerifier TokenVerifier, streamConcurrency int) *CheckService {
 	return &CheckService{
-		inspectUsecase: inspectUsecase,
+		inspectUsecase:    inspectUsecase,
+		manageUsecase:     manageUsecase,
+		tokenVerifier:     tokenVerifier,
+		streamConcurrency: max(streamConcurrency, 1),
 	}
 }
 
 func (cs CheckService) Inspect(ctx context.Context, req *checkmail.InspectRequest) (*checkmail.InspectResponse, error) {
-	domainType, err := cs.inspectUsecase.InspectData(ctx, req.Data, req.ClientIp, req.ProjectToken)
+	result, err := cs.inspectUsecase.InspectData(ctx, req.Data, req.ClientIp, req.ProjectToken)
 	if err != nil {
 		return nil, err
 	}
+	return ModelToInspectResponse(result), nil
+}
+
+func ModelToInspectResponse(result *models.InspectResult) *checkmail.InspectResponse {
+	res := &checkmail.InspectResponse{
+		DomainType:        result.Type.String(),
+		Address:           models.AddressToUnicode(result.Address),
+		CanonicalAddress:  models.AddressToUnicode(result.CanonicalAddress),
+		LocalPart:         result.LocalPart,
+		Domain:            models.DomainToUnicode(result.Domain),
+		RegistrableDomain: models.DomainToUnicode(result.RegistrableDomain),
+		SuggestedDomain:   models.DomainToUnicode(result.SuggestedDomain),
+		SuggestedAddress:  models.AddressToUnicode(result.SuggestedAddress),
+		RemainingQuota:    int64(result.RemainingQuota),
+		LatencyMs:         result.Latency.Milliseconds(),
+	}
+	if res.Domain != result.Domain {
+		res.AsciiDomain = result.Domain
+	}
+	if result.Rule != nil {
+		res.Rule = &checkmail.Rule{
+			Name:   models.DomainToUnicode(result.Rule.Name),
+			Match:  result.Rule.Match.String(),
+			Source: result.Source.String(),
+		}
+	}
+	for _, flag := range result.Flags {
+		res.Flags = append(res.Flags, &checkmail.Flag{
+			Code:   flag.Code.String(),
+			Detail: flag.Detail,
+		})
+	}
+	if result.MailRoute != nil {
+		res.MailRoute = &checkmail.MailRoute{
+			Status:  result.MailRoute.Status.String(),
+			MxHosts: result.MailRoute.MXHosts,
+		}
+	}
+	return res
+}
+
+func (cs CheckService) InspectBatch(ctx context.Context, req *checkmail.InspectBatchRequest) (*checkmail.InspectBatchResponse, error) {
+	items, err := cs.inspectUsecase.InspectDataBatch(ctx, req.Data, req.ClientIp, req.ProjectToken)
+	if err != nil {
+		return nil, err
+	}
+	res := &checkmail.InspectBatchResponse{
+		Items: make([]*checkmail.InspectBatchItem, 0, len(items)),
+	}
+	for _, item := range items {
+		res.Items = append(res.Items, ModelToInspectBatchItem(item))
+	}
+	return res, nil
+}
+
+func ModelToInspectBatchItem(item models.InspectItem) *checkmail.InspectBatchItem {
+	batchItem := &checkmail.InspectBatchItem{Data: item.Data}
+	if item.Err != nil {
+		batchItem.Error = item.Err.Error()
+		batchItem.Result = suggestionToInspectResponse(item.Err)
+		return batchItem
+	}
+	batchItem.DomainType = item.Result.Type.String()
+	batchItem.Source = item.Result.Source.String()
+	batchItem.Result = ModelToInspectResponse(item.Result)
+	return batchItem
+}
+
+func suggestionToInspectResponse(err error) *checkmail.InspectResponse {
+	var suggestionErr models.SuggestionError
+	if !errors.As(err, &suggestionErr) {
+		return nil
+	}
 	return &checkmail.InspectResponse{
-		DomainType: domainType.String(),
-	}, nil
+		SuggestedDomain:  models.DomainToUnicode(suggestionErr.SuggestedDomain),
+		SuggestedAddress: models.AddressToUnicode(suggestionErr.SuggestedAddress),
+	}
 }
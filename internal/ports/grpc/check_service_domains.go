@@ -0,0 +1,72 @@
+package GRPCServer
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/common/protobuf/checkmail"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"time"
+)
+
+// ListDomains is staff only, like its HTTP counterpart.
+func (cs CheckService) ListDomains(ctx context.Context, req *checkmail.ListDomainsRequest) (*checkmail.ListDomainsResponse, error) {
+	if err := cs.requireRole(ctx, models.StaffRole); err != nil {
+		return nil, err
+	}
+	search := models.DomainSearch{
+		Type:         req.Type,
+		Match:        req.Match,
+		NameContains: req.NameContains,
+		Sort:         req.Sort,
+		Descending:   req.Descending,
+		Cursor:       req.Cursor,
+		Limit:        int(req.Limit),
+	}
+	for _, field := range []struct {
+		value  string
+		target *time.Time
+	}{
+		{req.CreatedFrom, &search.CreatedFrom},
+		{req.CreatedTo, &search.CreatedTo},
+		{req.UpdatedFrom, &search.UpdatedFrom},
+		{req.UpdatedTo, &search.UpdatedTo},
+	} {
+		if field.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, field.value)
+		if err != nil {
+			return nil, models.ErrInvalidTimeRange
+		}
+		*field.target = t
+	}
+	page, err := cs.manageUsecase.SearchDomains(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	res := &checkmail.ListDomainsResponse{
+		Domains:    make([]*checkmail.Domain, 0, len(page.Domains)),
+		NextCursor: page.NextCursor,
+	}
+	for _, domain := range page.Domains {
+		res.Domains = append(res.Domains, ModelToDomainMessage(domain))
+	}
+	return res, nil
+}
+
+func ModelToDomainMessage(domain models.Domain) *checkmail.Domain {
+	return &checkmail.Domain{
+		Name:      models.DomainToUnicode(domain.Name),
+		Type:      domain.Type.String(),
+		Match:     domain.Match.String(),
+		CreatedAt: domain.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: domain.UpdatedAt.Format(time.RFC3339),
+		ExpiresAt: formatExpiry(domain.ExpiresAt),
+	}
+}
+
+func formatExpiry(expiresAt *time.Time) string {
+	if expiresAt == nil {
+		return ""
+	}
+	return expiresAt.Format(time.RFC3339)
+}
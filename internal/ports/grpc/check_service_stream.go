@@ -0,0 +1,106 @@
+package GRPCServer
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/common/protobuf/checkmail"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"sync"
+	"time"
+)
+
+const closeStreamTimeout = 5 * time.Second
+
+// InspectStream bounds the channels between receiver, workers and sender, so a slow client stops the receiver
+// and HTTP/2 flow control pushes back on the sending side.
+func (cs CheckService) InspectStream(stream grpc.BidiStreamingServer[checkmail.InspectStreamRequest, checkmail.InspectStreamResponse]) (err error) {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	lease, err := cs.inspectUsecase.OpenStream(ctx, first.ProjectToken)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), closeStreamTimeout)
+		defer cancel()
+		err = errors.Join(err, cs.inspectUsecase.CloseStream(closeCtx, lease))
+	}()
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	requests := make(chan *checkmail.InspectStreamRequest, cs.streamConcurrency)
+	responses := make(chan *checkmail.InspectStreamResponse, cs.streamConcurrency)
+
+	group.Go(func() error {
+		defer close(requests)
+		for req := first; ; {
+			if req.ProjectToken != "" && req.ProjectToken != first.ProjectToken {
+				return status.Error(codes.InvalidArgument, "project token can not be changed within a stream")
+			}
+			select {
+			case requests <- req:
+			case <-groupCtx.Done():
+				return groupCtx.Err()
+			}
+			var err error
+			if req, err = stream.Recv(); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+		}
+	})
+
+	var workers sync.WaitGroup
+	for range cs.streamConcurrency {
+		workers.Add(1)
+		group.Go(func() error {
+			defer workers.Done()
+			for req := range requests {
+				res := &checkmail.InspectStreamResponse{Id: req.Id}
+				result, err := cs.inspectUsecase.InspectStreamData(groupCtx, lease, req.Data)
+				if err != nil {
+					res.Error = err.Error()
+					res.Result = suggestionToInspectResponse(err)
+				} else {
+					res.DomainType = result.Type.String()
+					res.Source = result.Source.String()
+					res.Result = ModelToInspectResponse(result)
+				}
+				select {
+				case responses <- res:
+				case <-groupCtx.Done():
+					return groupCtx.Err()
+				}
+			}
+			return nil
+		})
+	}
+	group.Go(func() error {
+		workers.Wait()
+		close(responses)
+		return nil
+	})
+
+	group.Go(func() error {
+		for res := range responses {
+			if err := stream.Send(res); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return group.Wait()
+}
@@ -6,5 +6,13 @@ import (
 )
 
 type InspectUsecase interface {
-	InspectData(ctx context.Context, data, clientIp, projectToken string) (models.Type, error)
+	InspectData(ctx context.Context, data, clientIp, projectToken string) (*models.InspectResult, error)
+	InspectDataBatch(ctx context.Context, data []string, clientIp, projectToken string) ([]models.InspectItem, error)
+	OpenStream(ctx context.Context, projectToken string) (*models.QuotaLease, error)
+	InspectStreamData(ctx context.Context, lease *models.QuotaLease, data string) (*models.InspectResult, error)
+	CloseStream(ctx context.Context, lease *models.QuotaLease) error
+}
+
+type ManageUsecase interface {
+	SearchDomains(ctx context.Context, search models.DomainSearch) (*models.DomainPage, error)
 }
@@ -1,7 +1,7 @@
 package GRPCServer
 
 import (
-	"github.com/aerosystems/common-service/gen/protobuf/checkmail"
+	"github.com/aerosystems/checkmail-service/internal/common/protobuf/checkmail"
 	"github.com/aerosystems/common-service/presenters/grpcserver"
 	"github.com/sirupsen/logrus"
 )
@@ -3,25 +3,46 @@ package HTTPServer
 import (
 	"context"
 	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
 type AccessUsecase interface {
 	GetAccess(ctx context.Context, token string) (*models.Access, error)
-	CreateAccess(ctx context.Context, token, subscriptionType string, accessCount int, accessTime time.Time) error
+	CreateAccess(ctx context.Context, token string, customerUUID uuid.UUID, subscriptionType string, accessCount int, accessTime time.Time) error
 }
 
 type InspectUsecase interface {
-	InspectData(ctx context.Context, data, clientIp, projectToken string) (models.Type, error)
+	InspectData(ctx context.Context, data, clientIp, projectToken string) (*models.InspectResult, error)
+	InspectDataBatch(ctx context.Context, data []string, clientIp, projectToken string) ([]models.InspectItem, error)
+	ExplainData(ctx context.Context, data, projectToken string) (*models.Explanation, error)
+	DecomposeData(ctx context.Context, data string) (*models.Decomposition, error)
+	VerdictCacheStats(ctx context.Context) models.CacheStats
 }
 
 type ManageUsecase interface {
-	CreateDomain(ctx context.Context, domainName, domainType, domainCoverage string) (*models.Domain, error)
-	GetDomainByName(ctx context.Context, domainName string) (*models.Domain, error)
-	UpdateDomain(ctx context.Context, domainName string, domainType, domainCoverage string) (*models.Domain, error)
-	DeleteDomain(ctx context.Context, domainName string) error
+	CreateDomain(ctx context.Context, domainName, domainType, domainCoverage string, expiresAt *time.Time) (*models.Domain, error)
+	GetDomainByName(ctx context.Context, selector models.DomainSelector) (*models.Domain, error)
+	SearchDomains(ctx context.Context, search models.DomainSearch) (*models.DomainPage, error)
+	ImportDomains(ctx context.Context, r io.Reader, format, defaultType, defaultCoverage string, dryRun bool) (*models.ImportReport, error)
+	ExportDomains(ctx context.Context, w io.Writer, format, domainType, domainMatch string) error
+	ExportFilters(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken string, w io.Writer, format, domainType, domainMatch string) error
+	UpdateDomain(ctx context.Context, selector models.DomainSelector, domainType, domainCoverage string) (*models.Domain, error)
+	SetDomainExpiry(ctx context.Context, selector models.DomainSelector, expiresAt *time.Time) (*models.Domain, error)
+	DeleteDomain(ctx context.Context, selector models.DomainSelector) error
+	ListDeletedDomains(ctx context.Context, cursor string, limit int) (*models.DomainPage, error)
+	RestoreDomain(ctx context.Context, domainName, domainType, domainCoverage string) (*models.Domain, error)
 	CountDomains(ctx context.Context) (map[models.Type]int, error)
-	CreateFilter(ctx context.Context, domainName, domainType, domainCoverage, projectToken string) (models.Filter, error)
+	GetAuditLog(ctx context.Context, query models.AuditQuery) (*models.AuditPage, error)
+	CreateFilter(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken, domainName, domainType, domainCoverage string, expiresAt *time.Time) (*models.Filter, error)
+	GetFilters(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken string) ([]models.Filter, error)
+	SetFilterExpiry(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken string, selector models.DomainSelector, expiresAt *time.Time) (*models.Filter, error)
+	DeleteFilter(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken string, selector models.DomainSelector) error
+	GetProviders(ctx context.Context) ([]models.Provider, error)
+	CreateProvider(ctx context.Context, domainName, canonicalDomain string, ignoreDots bool, tagSeparators string) (*models.Provider, error)
+	UpdateProvider(ctx context.Context, domainName, canonicalDomain string, ignoreDots bool, tagSeparators string) (*models.Provider, error)
+	DeleteProvider(ctx context.Context, domainName string) error
 }
 
 type ReviewUsecase interface {
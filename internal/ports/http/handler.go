@@ -27,34 +27,54 @@ func NewHandler(
 }
 
 type Domain struct {
-	Name     string `json:"name" example:"gmail.com"`
-	Type     string `json:"type" example:"whitelist"`
-	Coverage string `json:"coverage" example:"equals"`
+	Name      string     `json:"name" example:"gmail.com"`
+	Type      string     `json:"type" example:"whitelist"`
+	Coverage  string     `json:"coverage" example:"equals"`
+	CreatedAt time.Time  `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt time.Time  `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" example:"2024-02-01T00:00:00Z"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" example:"2024-01-15T00:00:00Z"`
 }
 
 func ModelToDomain(model *models.Domain) Domain {
 	return Domain{
-		Name:     model.Name,
-		Type:     model.Type.String(),
-		Coverage: model.Match.String(),
+		Name:      models.DomainToUnicode(model.Name),
+		Type:      model.Type.String(),
+		Coverage:  model.Match.String(),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+		ExpiresAt: model.ExpiresAt,
+		DeletedAt: model.DeletedAt,
 	}
 }
 
+func ModelListToDomainList(domains []models.Domain) []Domain {
+	domainList := make([]Domain, 0, len(domains))
+	for i := range domains {
+		domainList = append(domainList, ModelToDomain(&domains[i]))
+	}
+	return domainList
+}
+
 type Filter struct {
-	Name      string    `json:"name" example:"gmail.com"`
-	Type      string    `json:"type" example:"whitelist"`
-	Match     string    `json:"coverage" example:"equals"`
-	CreatedAt time.Time `json:"createdAt" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt time.Time `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
+	ProjectToken string     `json:"projectToken" example:"38fa45ebb919g5d966122bf9g42a38ceb1e4f6eddf1da70ef00afbdc38197d8f"`
+	Name         string     `json:"name" example:"gmail.com"`
+	Type         string     `json:"type" example:"whitelist"`
+	Match        string     `json:"coverage" example:"equals"`
+	CreatedAt    time.Time  `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt    time.Time  `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty" example:"2024-02-01T00:00:00Z"`
 }
 
 func ModelToFilter(filter models.Filter) Filter {
 	return Filter{
-		Name:      filter.Name,
-		Type:      filter.Type.String(),
-		Match:     filter.Match.String(),
-		CreatedAt: filter.CreatedAt,
-		UpdatedAt: filter.UpdatedAt,
+		ProjectToken: filter.ProjectToken,
+		Name:         models.DomainToUnicode(filter.Name),
+		Type:         filter.Type.String(),
+		Match:        filter.Match.String(),
+		CreatedAt:    filter.CreatedAt,
+		UpdatedAt:    filter.UpdatedAt,
+		ExpiresAt:    filter.ExpiresAt,
 	}
 }
 
@@ -66,6 +86,34 @@ func ModelListToFilterList(filters []models.Filter) []Filter {
 	return filterList
 }
 
+type Provider struct {
+	Domain          string    `json:"domain" example:"googlemail.com"`
+	CanonicalDomain string    `json:"canonicalDomain" example:"gmail.com"`
+	IgnoreDots      bool      `json:"ignoreDots" example:"true"`
+	TagSeparators   string    `json:"tagSeparators" example:"+"`
+	CreatedAt       time.Time `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt       time.Time `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
+}
+
+func ModelToProvider(provider models.Provider) Provider {
+	return Provider{
+		Domain:          models.DomainToUnicode(provider.Domain),
+		CanonicalDomain: models.DomainToUnicode(provider.CanonicalDomain),
+		IgnoreDots:      provider.IgnoreDots,
+		TagSeparators:   provider.TagSeparators,
+		CreatedAt:       provider.CreatedAt,
+		UpdatedAt:       provider.UpdatedAt,
+	}
+}
+
+func ModelListToProviderList(providers []models.Provider) []Provider {
+	providerList := make([]Provider, 0, len(providers))
+	for _, model := range providers {
+		providerList = append(providerList, ModelToProvider(model))
+	}
+	return providerList
+}
+
 type Review struct {
 	Name      string    `json:"name" example:"gmail.com"`
 	Type      string    `json:"type" example:"whitelist"`
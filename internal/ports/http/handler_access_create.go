@@ -3,6 +3,7 @@ package HTTPServer
 import (
 	"encoding/json"
 	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"net/http"
 	"time"
@@ -21,6 +22,7 @@ type CreateAccessRequestBody struct {
 
 type CreateAccessEvent struct {
 	Token            string    `json:"token"`
+	CustomerUUID     uuid.UUID `json:"customerUuid"`
 	SubscriptionType string    `json:"subscriptionType"`
 	AccessCount      int       `json:"accessCount"`
 	AccessTime       time.Time `json:"accessTime"`
@@ -35,7 +37,7 @@ func (h Handler) CreateAccess(c echo.Context) error {
 	if err := json.Unmarshal(req.Message.Data, &event); err != nil {
 		return models.ErrInvalidRequestPayload
 	}
-	if err := h.accessUsecase.CreateAccess(c.Request().Context(), event.Token, event.SubscriptionType, event.AccessCount, event.AccessTime); err != nil {
+	if err := h.accessUsecase.CreateAccess(c.Request().Context(), event.Token, event.CustomerUUID, event.SubscriptionType, event.AccessCount, event.AccessTime); err != nil {
 		return err
 	}
 	return c.NoContent(http.StatusCreated)
@@ -0,0 +1,106 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+type GetAuditLogRequest struct {
+	Domain       string `query:"domain" example:"gmail.com"`
+	ProjectToken string `query:"projectToken" example:"38fa45ebb919g5d966122bf9g42a38ceb1e4f6eddf1da70ef00afbdc38197d8f"`
+	Actor        string `query:"actor" example:"2d9c2f5e-7c3a-4f5b-9b1e-0f6e8a7d3c21"`
+	Before       int    `query:"before" example:"1200"`
+	Limit        int    `query:"limit" example:"50"`
+}
+
+type AuditEntry struct {
+	Id           int       `json:"id" example:"1199"`
+	Action       string    `json:"action" example:"update"`
+	ProjectToken string    `json:"projectToken,omitempty"`
+	Domain       string    `json:"domain" example:"gmail.com"`
+	ActorUUID    uuid.UUID `json:"actorUuid" example:"2d9c2f5e-7c3a-4f5b-9b1e-0f6e8a7d3c21"`
+	ActorRole    string    `json:"actorRole" example:"staff"`
+	Reason       string    `json:"reason,omitempty" example:"false positive reported by support"`
+	RequestID    string    `json:"requestId,omitempty" example:"kZUxXOvFbAaHyOaTKiNwUHbTEbgEwhMj"`
+	Before       *Domain   `json:"before,omitempty"`
+	After        *Domain   `json:"after,omitempty"`
+	CreatedAt    time.Time `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+}
+
+type AuditLogResponse struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextBefore int          `json:"nextBefore,omitempty" example:"1150"`
+}
+
+func ModelToAuditEntry(model *models.AuditEntry) AuditEntry {
+	entry := AuditEntry{
+		Id:           model.Id,
+		Action:       model.Action.String(),
+		ProjectToken: model.ProjectToken,
+		Domain:       models.DomainToUnicode(model.DomainName),
+		ActorUUID:    model.ActorUUID,
+		ActorRole:    model.ActorRole.String(),
+		Reason:       model.Reason,
+		RequestID:    model.RequestID,
+		CreatedAt:    model.CreatedAt,
+	}
+	if model.Before != nil {
+		before := ModelToDomain(model.Before)
+		entry.Before = &before
+	}
+	if model.After != nil {
+		after := ModelToDomain(model.After)
+		entry.After = &after
+	}
+	return entry
+}
+
+// GetAuditLog godoc
+// @Summary Get Audit Log
+// @Description Get the changes of domain rules newest first, of a domain, of an actor or both. With projectToken the domain is a filter of that project. Pass nextBefore of a page as before to get older entries. Roles allowed: staff
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param domain query string false "domain name of the rule"
+// @Param projectToken query string false "project token, for the filters of a project"
+// @Param actor query string false "UUID of the user who made the changes"
+// @Param before query int false "only entries older than this id"
+// @Param limit query int false "page size, 50 by default and 500 at most"
+// @Success 200 {object} AuditLogResponse
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/audit [get]
+func (h Handler) GetAuditLog(c echo.Context) error {
+	var requestPayload GetAuditLogRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	query := models.AuditQuery{
+		DomainName:   requestPayload.Domain,
+		ProjectToken: requestPayload.ProjectToken,
+		BeforeId:     requestPayload.Before,
+		Limit:        requestPayload.Limit,
+	}
+	if requestPayload.Actor != "" {
+		actorUUID, err := uuid.Parse(requestPayload.Actor)
+		if err != nil {
+			return models.ErrInvalidRequestBody
+		}
+		query.ActorUUID = actorUUID
+	}
+	page, err := h.domainUsecase.GetAuditLog(c.Request().Context(), query)
+	if err != nil {
+		return err
+	}
+	response := AuditLogResponse{Entries: make([]AuditEntry, 0, len(page.Entries)), NextBefore: page.NextBefore}
+	for i := range page.Entries {
+		response.Entries = append(response.Entries, ModelToAuditEntry(&page.Entries[i]))
+	}
+	return c.JSON(http.StatusOK, response)
+}
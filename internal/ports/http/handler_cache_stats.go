@@ -0,0 +1,44 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type CacheStats struct {
+	Hits          uint64  `json:"hits" example:"9500"`
+	Misses        uint64  `json:"misses" example:"500"`
+	HitRatio      float64 `json:"hitRatio" example:"0.95"`
+	Evictions     uint64  `json:"evictions" example:"0"`
+	Invalidations uint64  `json:"invalidations" example:"12"`
+	Size          int     `json:"size" example:"480"`
+	Capacity      int     `json:"capacity" example:"100000"`
+}
+
+func ModelToCacheStats(stats models.CacheStats) CacheStats {
+	return CacheStats{
+		Hits:          stats.Hits,
+		Misses:        stats.Misses,
+		HitRatio:      stats.HitRatio(),
+		Evictions:     stats.Evictions,
+		Invalidations: stats.Invalidations,
+		Size:          stats.Size,
+		Capacity:      stats.Capacity,
+	}
+}
+
+// GetCacheStats godoc
+// @Summary get verdict cache statistics
+// @Description Returns the hit and miss statistics of the verdict cache since the start of the instance, all zero when the cache is disabled. Roles allowed: staff
+// @Tags cache
+// @Produce application/json
+// @Security BearerAuth
+// @Success 200 {object} CacheStats
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/cache/stats [get]
+func (h Handler) GetCacheStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, ModelToCacheStats(h.inspectUsecase.VerdictCacheStats(c.Request().Context())))
+}
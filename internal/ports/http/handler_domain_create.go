@@ -4,6 +4,7 @@ import (
 	"github.com/aerosystems/checkmail-service/internal/models"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"time"
 )
 
 type CreateDomainRequest struct {
@@ -11,9 +12,10 @@ type CreateDomainRequest struct {
 }
 
 type CreateDomainRequestBody struct {
-	Name     string `json:"name" validate:"fqdn,required" example:"gmail.com"`
-	Type     string `json:"type" validate:"oneof=blacklist whitelist undefined, required" example:"whitelist"`
-	Coverage string `json:"coverage" validate:"oneof=begins ends equals contains, required" example:"equals"`
+	Name      string     `json:"name" validate:"required" example:"gmail.com"`
+	Type      string     `json:"type" validate:"oneof=blacklist whitelist disposable, required" example:"whitelist"`
+	Coverage  string     `json:"coverage" validate:"oneof=prefix suffix equals contains registrable glob regex, required" example:"equals"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" example:"2024-02-01T00:00:00Z"`
 }
 
 // CreateDomain godoc
@@ -22,6 +24,7 @@ type CreateDomainRequestBody struct {
 // @Accept  json
 // @Produce application/json
 // @Param comment body CreateDomainRequestBody true "raw request body"
+// @Param X-Audit-Reason header string false "reason recorded in the audit log"
 // @Security BearerAuth
 // @Success 201 {object} Domain
 // @Failure 400 {object} echo.HTTPError
@@ -36,7 +39,7 @@ func (h Handler) CreateDomain(c echo.Context) error {
 	if err := c.Bind(&requestPayload); err != nil {
 		return models.ErrInvalidRequestBody
 	}
-	domain, err := h.domainUsecase.CreateDomain(c.Request().Context(), requestPayload.Name, requestPayload.Type, requestPayload.Coverage)
+	domain, err := h.domainUsecase.CreateDomain(auditContext(c), requestPayload.Name, requestPayload.Type, requestPayload.Coverage, requestPayload.ExpiresAt)
 	if err != nil {
 		return err
 	}
@@ -10,22 +10,24 @@ type DeleteDomainRequest struct {
 	UpdateDomainQueryParam
 }
 
-type DeleteDomainQueryParam struct {
-	Name string `json:"name" validate:"fqdn,required" example:"gmail.com"`
-}
-
 // DeleteDomain godoc
 // @Summary delete domain by Domain Name
+// @Description Move the domain to the trash, it stops matching and can be restored until the retention period ends. Roles allowed: staff
 // @Tags domains
 // @Accept  json
 // @Produce application/json
 // @Param	domainName	path	string	true "Domain Name"
+// @Param type query string false "type of the rule, required when several rules have the name" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "coverage of the rule, required when several rules have the name" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Param X-Audit-Reason header string false "reason recorded in the audit log"
 // @Security BearerAuth
 // @Success 204
 // @Failure 400 {object} echo.HTTPError
 // @Failure 401 {object} echo.HTTPError
 // @Failure 403 {object} echo.HTTPError
 // @Failure 404 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Router /v1/domains/{domain_name} [delete]
 func (h Handler) DeleteDomain(c echo.Context) error {
@@ -33,7 +35,7 @@ func (h Handler) DeleteDomain(c echo.Context) error {
 	if err := c.Bind(&requestPayload); err != nil {
 		return models.ErrInvalidRequestBody
 	}
-	if err := h.domainUsecase.DeleteDomain(c.Request().Context(), requestPayload.Name); err != nil {
+	if err := h.domainUsecase.DeleteDomain(auditContext(c), requestPayload.selector()); err != nil {
 		return err
 	}
 	return c.JSON(http.StatusNoContent, nil)
@@ -10,22 +10,22 @@ type GetDomainRequest struct {
 	UpdateDomainQueryParam
 }
 
-type GetDomainQueryParam struct {
-	Name string `json:"name" validate:"fqdn,required" example:"gmail.com"`
-}
-
 // GetDomain godoc
 // @Summary get domain by Domain Name
 // @Tags domains
 // @Accept  json
 // @Produce application/json
 // @Param	domainName	path	string	true "Domain Name"
+// @Param type query string false "type of the rule, required when several rules have the name" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "coverage of the rule, required when several rules have the name" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
 // @Security BearerAuth
 // @Success 200 {object} Domain
 // @Failure 400 {object} echo.HTTPError
 // @Failure 401 {object} echo.HTTPError
 // @Failure 403 {object} echo.HTTPError
 // @Failure 404 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Router /v1/domains/{domain_name} [get]
 func (h Handler) GetDomain(c echo.Context) error {
@@ -33,7 +33,7 @@ func (h Handler) GetDomain(c echo.Context) error {
 	if err := c.Bind(&requestPayload); err != nil {
 		return models.ErrInvalidRequestBody
 	}
-	domain, err := h.domainUsecase.GetDomainByName(c.Request().Context(), requestPayload.Name)
+	domain, err := h.domainUsecase.GetDomainByName(c.Request().Context(), requestPayload.selector())
 	if err != nil {
 		return err
 	}
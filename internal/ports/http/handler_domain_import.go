@@ -0,0 +1,92 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"mime"
+	"net/http"
+)
+
+type ImportDomainsRequest struct {
+	Format   string `query:"format" example:"csv"`
+	Type     string `query:"type" example:"blacklist"`
+	Coverage string `query:"coverage" example:"equals"`
+	DryRun   bool   `query:"dryRun" example:"true"`
+}
+
+type ImportReport struct {
+	DryRun   bool          `json:"dryRun" example:"false"`
+	Inserted int           `json:"inserted" example:"49000"`
+	Updated  int           `json:"updated" example:"12"`
+	Skipped  int           `json:"skipped" example:"980"`
+	Invalid  int           `json:"invalid" example:"8"`
+	Errors   []ImportError `json:"errors"`
+}
+
+type ImportError struct {
+	Line   int    `json:"line" example:"42"`
+	Data   string `json:"data" example:"not a domain"`
+	Reason string `json:"reason" example:"domain does not valid"`
+}
+
+func ModelToImportReport(report *models.ImportReport) ImportReport {
+	importErrors := make([]ImportError, 0, len(report.Errors))
+	for _, importError := range report.Errors {
+		importErrors = append(importErrors, ImportError{
+			Line:   importError.Line,
+			Data:   importError.Data,
+			Reason: importError.Reason,
+		})
+	}
+	return ImportReport{
+		DryRun:   report.DryRun,
+		Inserted: report.Inserted,
+		Updated:  report.Updated,
+		Skipped:  report.Skipped,
+		Invalid:  report.Invalid,
+		Errors:   importErrors,
+	}
+}
+
+var importFormats = map[string]string{
+	"text/csv":             models.CSVImportFormat.String(),
+	"application/x-ndjson": models.NDJSONImportFormat.String(),
+	"text/plain":           models.TextImportFormat.String(),
+}
+
+// ImportDomains godoc
+// @Summary import domains
+// @Description Import domain rules from CSV (name,type,coverage,expiresAt), NDJSON ({"name","type","coverage","expiresAt"} per line) or plain text (one domain per line) in a single transaction. Type and coverage parameters apply to rows without them. A row without an RFC 3339 expiresAt keeps the expiry of an existing rule. Invalid rows and rows giving a name and coverage another type than a live rule or an earlier row are reported and left out, with dryRun nothing is written. Roles allowed: staff
+// @Tags domains
+// @Accept text/csv,application/x-ndjson,text/plain
+// @Produce application/json
+// @Param format query string false "import format, taken from the content type when omitted" Enums(csv, ndjson, text)
+// @Param type query string false "type of rows without one" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "coverage of rows without one, equals by default" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Param dryRun query bool false "only report what the import would do"
+// @Param data body string true "rules"
+// @Param X-Audit-Reason header string false "reason recorded in the audit log"
+// @Security BearerAuth
+// @Success 200 {object} ImportReport
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 413 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/domains/import [post]
+func (h Handler) ImportDomains(c echo.Context) error {
+	var requestPayload ImportDomainsRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	if requestPayload.Format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+		requestPayload.Format = importFormats[mediaType]
+	}
+	report, err := h.domainUsecase.ImportDomains(auditContext(c), c.Request().Body, requestPayload.Format, requestPayload.Type, requestPayload.Coverage, requestPayload.DryRun)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ModelToImportReport(report))
+}
@@ -0,0 +1,82 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+type GetDomainListRequest struct {
+	Type        string    `query:"type" example:"blacklist"`
+	Coverage    string    `query:"coverage" example:"suffix"`
+	Name        string    `query:"name" example:"mail"`
+	CreatedFrom time.Time `query:"createdFrom" example:"2024-01-01T00:00:00Z"`
+	CreatedTo   time.Time `query:"createdTo" example:"2024-02-01T00:00:00Z"`
+	UpdatedFrom time.Time `query:"updatedFrom" example:"2024-01-01T00:00:00Z"`
+	UpdatedTo   time.Time `query:"updatedTo" example:"2024-02-01T00:00:00Z"`
+	Sort        string    `query:"sort" example:"created_at"`
+	Order       string    `query:"order" example:"desc"`
+	Cursor      string    `query:"cursor"`
+	Limit       int       `query:"limit" example:"50"`
+}
+
+type DomainListResponse struct {
+	Domains    []Domain `json:"domains"`
+	NextCursor string   `json:"nextCursor,omitempty" example:"eyJzIjoibmFtZSIsIm4iOiJnbWFpbC5jb20ifQ"`
+}
+
+// GetDomainList godoc
+// @Summary Get Domain List
+// @Description Get a page of the global domain list. Pass nextCursor of a page as cursor, with the same filters and sort, to get the next one. Roles allowed: staff
+// @Tags domains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "domain type" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "domain coverage" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Param name query string false "name substring"
+// @Param createdFrom query string false "created at or after, RFC 3339"
+// @Param createdTo query string false "created before, RFC 3339"
+// @Param updatedFrom query string false "updated at or after, RFC 3339"
+// @Param updatedTo query string false "updated before, RFC 3339"
+// @Param sort query string false "sort field" Enums(name, created_at, updated_at)
+// @Param order query string false "sort order" Enums(asc, desc)
+// @Param cursor query string false "cursor of the next page"
+// @Param limit query int false "page size, 50 by default and 500 at most"
+// @Success 200 {object} DomainListResponse
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/domains [get]
+func (h Handler) GetDomainList(c echo.Context) error {
+	var requestPayload GetDomainListRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	if requestPayload.Order != "" && requestPayload.Order != "asc" && requestPayload.Order != "desc" {
+		return models.ErrInvalidSort
+	}
+	page, err := h.domainUsecase.SearchDomains(c.Request().Context(), models.DomainSearch{
+		Type:         requestPayload.Type,
+		Match:        requestPayload.Coverage,
+		NameContains: requestPayload.Name,
+		CreatedFrom:  requestPayload.CreatedFrom,
+		CreatedTo:    requestPayload.CreatedTo,
+		UpdatedFrom:  requestPayload.UpdatedFrom,
+		UpdatedTo:    requestPayload.UpdatedTo,
+		Sort:         requestPayload.Sort,
+		Descending:   requestPayload.Order == "desc",
+		Cursor:       requestPayload.Cursor,
+		Limit:        requestPayload.Limit,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, DomainListResponse{
+		Domains:    ModelListToDomainList(page.Domains),
+		NextCursor: page.NextCursor,
+	})
+}
@@ -0,0 +1,83 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type GetDomainTrashRequest struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit" example:"50"`
+}
+
+type RestoreDomainRequest struct {
+	Name     string `param:"domain_name" example:"gmail.com"`
+	Type     string `query:"type" example:"whitelist"`
+	Coverage string `query:"coverage" example:"equals"`
+}
+
+// GetDomainTrash godoc
+// @Summary Get Domain Trash
+// @Description Get a page of the deleted domains, the most recently deleted first. Pass nextCursor of a page as cursor to get the next one. Roles allowed: staff
+// @Tags domains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param cursor query string false "cursor of the next page"
+// @Param limit query int false "page size, 50 by default and 500 at most"
+// @Success 200 {object} DomainListResponse
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/domains/trash [get]
+func (h Handler) GetDomainTrash(c echo.Context) error {
+	var requestPayload GetDomainTrashRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	page, err := h.domainUsecase.ListDeletedDomains(c.Request().Context(), requestPayload.Cursor, requestPayload.Limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, DomainListResponse{
+		Domains:    ModelListToDomainList(page.Domains),
+		NextCursor: page.NextCursor,
+	})
+}
+
+// RestoreDomain godoc
+// @Summary restore domain from the trash
+// @Description Bring the most recently deleted domain with the name back, of the type and coverage when given, unless the same rule exists again. Roles allowed: staff
+// @Tags domains
+// @Accept json
+// @Produce application/json
+// @Param domain_name path string true "Domain Name"
+// @Param type query string false "type of the deleted rule" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "coverage of the deleted rule" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Param X-Audit-Reason header string false "reason recorded in the audit log"
+// @Security BearerAuth
+// @Success 200 {object} Domain
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 404 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/domains/{domain_name}/restore [post]
+func (h Handler) RestoreDomain(c echo.Context) error {
+	var requestPayload RestoreDomainRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	domain, err := h.domainUsecase.RestoreDomain(auditContext(c), requestPayload.Name, requestPayload.Type, requestPayload.Coverage)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ModelToDomain(domain))
+}
@@ -12,12 +12,19 @@ type UpdateDomainRequest struct {
 }
 
 type UpdateDomainBody struct {
-	Type     string `json:"type" validate:"oneof=blacklist whitelist undefined, required" example:"whitelist"`
-	Coverage string `json:"coverage" validate:"oneof=begins ends equals contains, required" example:"equals"`
+	Type     string `json:"type" validate:"oneof=blacklist whitelist disposable, required" example:"whitelist"`
+	Coverage string `json:"coverage" validate:"oneof=prefix suffix equals contains registrable glob regex, required" example:"equals"`
 }
 
+// UpdateDomainQueryParam picks the rule by its name, and by its current type and coverage when several rules have the name.
 type UpdateDomainQueryParam struct {
-	Name string `json:"name" validate:"fqdn,required" example:"gmail.com"`
+	Name     string `param:"domain_name" validate:"required" example:"gmail.com"`
+	Type     string `query:"type" example:"blacklist"`
+	Coverage string `query:"coverage" example:"equals"`
+}
+
+func (p UpdateDomainQueryParam) selector() models.DomainSelector {
+	return models.DomainSelector{Name: p.Name, Type: p.Type, Match: p.Coverage}
 }
 
 // UpdateDomain godoc
@@ -26,24 +33,43 @@ type UpdateDomainQueryParam struct {
 // @Accept  json
 // @Produce application/json
 // @Param	domainName	path	string	true "Domain Name"
-// @Param comment body UpdateDomainRequest true "raw request body"
+// @Param type query string false "current type of the rule, required when several rules have the name" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "current coverage of the rule, required when several rules have the name" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Param comment body UpdateDomainBody true "raw request body"
+// @Param X-Audit-Reason header string false "reason recorded in the audit log"
 // @Security BearerAuth
 // @Success 200 {object} Domain
 // @Failure 400 {object} echo.HTTPError
 // @Failure 401 {object} echo.HTTPError
 // @Failure 403 {object} echo.HTTPError
 // @Failure 404 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
 // @Failure 422 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Router /v1/domains/{domain_name} [patch]
 func (h Handler) UpdateDomain(c echo.Context) error {
-	var requestPayload CreateDomainRequestBody
-	if err := c.Bind(&requestPayload); err != nil {
+	var requestPayload UpdateDomainRequest
+	if err := c.Bind(&requestPayload.UpdateDomainBody); err != nil {
 		return models.ErrInvalidRequestBody
 	}
-	domain, err := h.domainUsecase.UpdateDomain(c.Request().Context(), requestPayload.Name, requestPayload.Type, requestPayload.Coverage)
+	if err := bindSelector(c, &requestPayload.UpdateDomainQueryParam); err != nil {
+		return err
+	}
+	domain, err := h.domainUsecase.UpdateDomain(auditContext(c), requestPayload.selector(), requestPayload.UpdateDomainBody.Type, requestPayload.UpdateDomainBody.Coverage)
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusOK, ModelToDomain(domain))
 }
+
+// bindSelector binds the path and the query explicitly, echo binds it only for GET, DELETE and HEAD requests.
+func bindSelector(c echo.Context, param any) error {
+	binder := &echo.DefaultBinder{}
+	if err := binder.BindPathParams(c, param); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	if err := binder.BindQueryParams(c, param); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	return nil
+}
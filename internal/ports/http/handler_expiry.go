@@ -0,0 +1,95 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+type SetDomainExpiryRequest struct {
+	UpdateDomainQueryParam
+	ExpiresAt *time.Time `json:"expiresAt" example:"2024-02-01T00:00:00Z"`
+}
+
+type SetFilterExpiryRequest struct {
+	FilterQueryParam
+	ExpiresAt *time.Time `json:"expiresAt" example:"2024-02-01T00:00:00Z"`
+}
+
+// SetDomainExpiry godoc
+// @Summary set domain expiry
+// @Description Extend, shorten or clear the expiry of a domain rule. An expired rule stops matching and is archived. Roles allowed: staff
+// @Tags domains
+// @Accept json
+// @Produce application/json
+// @Param domain_name path string true "Domain Name"
+// @Param type query string false "type of the rule, required when several rules have the name" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "coverage of the rule, required when several rules have the name" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Param expiry body SetDomainExpiryRequest true "new expiry, null for none"
+// @Param X-Audit-Reason header string false "reason recorded in the audit log"
+// @Security BearerAuth
+// @Success 200 {object} Domain
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 404 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/domains/{domain_name}/expiry [put]
+func (h Handler) SetDomainExpiry(c echo.Context) error {
+	var requestPayload SetDomainExpiryRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	if err := bindSelector(c, &requestPayload.UpdateDomainQueryParam); err != nil {
+		return err
+	}
+	domain, err := h.domainUsecase.SetDomainExpiry(auditContext(c), requestPayload.selector(), requestPayload.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ModelToDomain(domain))
+}
+
+// SetFilterExpiry godoc
+// @Summary Set Filter Expiry
+// @Description Extend, shorten or clear the expiry of a Filter of Project. An expired filter stops matching and is archived. Customers may manage only their own projects. Roles allowed: customer, staff
+// @Tags Filter
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param domain_name path string true "Domain Name"
+// @Param projectToken query string true "project token"
+// @Param type query string false "type of the filter, required when several filters have the name" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "coverage of the filter, required when several filters have the name" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Param expiry body SetFilterExpiryRequest true "new expiry, null for none"
+// @Param X-Audit-Reason header string false "reason recorded in the audit log"
+// @Success 200 {object} Filter
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 404 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/filters/{domain_name}/expiry [put]
+func (h Handler) SetFilterExpiry(c echo.Context) error {
+	user, err := GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, errMessageUnauthorized)
+	}
+	var requestPayload SetFilterExpiryRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	if err := bindSelector(c, &requestPayload.FilterQueryParam); err != nil {
+		return err
+	}
+	filter, err := h.domainUsecase.SetFilterExpiry(auditContext(c), user.UUID, user.Role, requestPayload.ProjectToken, requestPayload.selector(), requestPayload.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ModelToFilter(*filter))
+}
@@ -0,0 +1,113 @@
+package HTTPServer
+
+import (
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type ExportDomainsRequest struct {
+	Format   string `query:"format" example:"csv"`
+	Type     string `query:"type" example:"blacklist"`
+	Coverage string `query:"coverage" example:"suffix"`
+}
+
+type ExportFiltersRequest struct {
+	ExportDomainsRequest
+	ProjectToken string `query:"projectToken" example:"38fa45ebb919g5d966122bf9g42a38ceb1e4f6eddf1da70ef00afbdc38197d8f"`
+}
+
+var exportContentTypes = map[string]string{
+	models.CSVExportFormat.String():    "text/csv; charset=utf-8",
+	models.NDJSONExportFormat.String(): "application/x-ndjson",
+	models.HostsExportFormat.String():  echo.MIMETextPlainCharsetUTF8,
+	models.RPZExportFormat.String():    echo.MIMETextPlainCharsetUTF8,
+}
+
+// exportResponse sets the download headers with the first write, so an error response is never sent as an attachment.
+type exportResponse struct {
+	c       echo.Context
+	name    string
+	format  string
+	started bool
+}
+
+func newExportResponse(c echo.Context, name, format string) *exportResponse {
+	return &exportResponse{c: c, name: name, format: format}
+}
+
+func (w *exportResponse) Write(p []byte) (int, error) {
+	w.start()
+	return w.c.Response().Write(p)
+}
+
+func (w *exportResponse) Flush() {
+	w.start()
+	w.c.Response().Flush()
+}
+
+func (w *exportResponse) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.c.Response().Header().Set(echo.HeaderContentType, exportContentTypes[w.format])
+	w.c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", w.name+"."+w.format))
+}
+
+// ExportDomains godoc
+// @Summary export domains
+// @Description Stream the global domain list ordered by name. CSV is readable by the import, hosts lists blocked equals, suffix and registrable rules, RPZ is a response policy zone. Roles allowed: staff
+// @Tags domains
+// @Produce text/csv,application/x-ndjson,text/plain
+// @Param format query string true "export format" Enums(csv, ndjson, hosts, rpz)
+// @Param type query string false "domain type" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "domain coverage" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Security BearerAuth
+// @Success 200 {string} string
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/domains/export [get]
+func (h Handler) ExportDomains(c echo.Context) error {
+	var requestPayload ExportDomainsRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	w := newExportResponse(c, "domains", requestPayload.Format)
+	return h.domainUsecase.ExportDomains(c.Request().Context(), w, requestPayload.Format, requestPayload.Type, requestPayload.Coverage)
+}
+
+// ExportFilters godoc
+// @Summary export filters
+// @Description Stream the filters of a project ordered by name, in the formats of the domain export. Roles allowed: customer, staff
+// @Tags Filter
+// @Produce text/csv,application/x-ndjson,text/plain
+// @Param projectToken query string true "project token"
+// @Param format query string true "export format" Enums(csv, ndjson, hosts, rpz)
+// @Param type query string false "domain type" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "domain coverage" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Security BearerAuth
+// @Success 200 {string} string
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 404 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/filters/export [get]
+func (h Handler) ExportFilters(c echo.Context) error {
+	user, err := GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, errMessageUnauthorized)
+	}
+	var requestPayload ExportFiltersRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	w := newExportResponse(c, "filters", requestPayload.Format)
+	return h.domainUsecase.ExportFilters(c.Request().Context(), user.UUID, user.Role, requestPayload.ProjectToken, w, requestPayload.Format, requestPayload.Type, requestPayload.Coverage)
+}
@@ -1,27 +1,32 @@
 package HTTPServer
 
 import (
+	"github.com/aerosystems/checkmail-service/internal/models"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"time"
 )
 
 type CreateFilterRequest struct {
-	Name         string `json:"name" example:"gmail.com"`
-	Type         string `json:"type" example:"whitelist"`
-	Coverage     string `json:"coverage" example:"equals"`
-	ProjectToken string `json:"projectToken" example:"38fa45ebb919g5d966122bf9g42a38ceb1e4f6eddf1da70ef00afbdc38197d8f"`
+	Name         string     `json:"name" validate:"required" example:"gmail.com"`
+	Type         string     `json:"type" validate:"oneof=blacklist whitelist disposable,required" example:"whitelist"`
+	Coverage     string     `json:"coverage" validate:"oneof=prefix suffix equals contains registrable glob regex,required" example:"equals"`
+	ProjectToken string     `json:"projectToken" validate:"required" example:"38fa45ebb919g5d966122bf9g42a38ceb1e4f6eddf1da70ef00afbdc38197d8f"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty" example:"2024-02-01T00:00:00Z"`
 }
 
 // CreateFilter godoc
 // @Summary Create Filter
-// @Description Create Filter for ProjectRPCPayload. Roles allowed: business, staff
+// @Description Create Filter for Project. Customers may manage only their own projects. Roles allowed: customer, staff
 // @Tags Filter
 // @Accept json
 // @Produce json
-// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Param filter body CreateFilterRequest true "raw request body"
+// @Param X-Audit-Reason header string false "reason recorded in the audit log"
 // @Success 201 {object} Filter
 // @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
 // @Failure 403 {object} echo.HTTPError
 // @Failure 404 {object} echo.HTTPError
 // @Failure 409 {object} echo.HTTPError
@@ -29,5 +34,17 @@ type CreateFilterRequest struct {
 // @Failure 500 {object} echo.HTTPError
 // @Router /v1/filters [post]
 func (h Handler) CreateFilter(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "not implemented")
+	user, err := GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, errMessageUnauthorized)
+	}
+	var requestPayload CreateFilterRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	filter, err := h.domainUsecase.CreateFilter(auditContext(c), user.UUID, user.Role, requestPayload.ProjectToken, requestPayload.Name, requestPayload.Type, requestPayload.Coverage, requestPayload.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, ModelToFilter(*filter))
 }
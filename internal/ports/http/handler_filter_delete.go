@@ -1,20 +1,37 @@
 package HTTPServer
 
 import (
+	"github.com/aerosystems/checkmail-service/internal/models"
 	"github.com/labstack/echo/v4"
 	"net/http"
 )
 
+// FilterQueryParam picks the filter of the project by its name, and by its type and coverage when several filters have the name.
+type FilterQueryParam struct {
+	Name         string `param:"domain_name" example:"gmail.com"`
+	ProjectToken string `query:"projectToken" example:"38fa45ebb919g5d966122bf9g42a38ceb1e4f6eddf1da70ef00afbdc38197d8f"`
+	Type         string `query:"type" example:"blacklist"`
+	Coverage     string `query:"coverage" example:"equals"`
+}
+
+func (p FilterQueryParam) selector() models.DomainSelector {
+	return models.DomainSelector{Name: p.Name, Type: p.Type, Match: p.Coverage}
+}
+
 // DeleteFilter godoc
 // @Summary Delete Filter
-// @Description Delete Filter for ProjectRPCPayload by projectId. Roles allowed: business, staff
+// @Description Delete Filter of Project by Domain Name. Customers may manage only their own projects. Roles allowed: customer, staff
 // @Tags Filter
 // @Accept json
 // @Produce json
-// @Security ApiKeyAuth
-// @Param id query int true "filter id"
+// @Security BearerAuth
+// @Param domain_name path string true "Domain Name"
+// @Param projectToken query string true "project token"
+// @Param type query string false "type of the filter, required when several filters have the name" Enums(blacklist, whitelist, disposable)
+// @Param coverage query string false "coverage of the filter, required when several filters have the name" Enums(prefix, suffix, equals, contains, registrable, glob, regex)
+// @Param X-Audit-Reason header string false "reason recorded in the audit log"
 // @Success 204
-// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
 // @Failure 403 {object} echo.HTTPError
 // @Failure 404 {object} echo.HTTPError
 // @Failure 409 {object} echo.HTTPError
@@ -22,5 +39,16 @@ import (
 // @Failure 500 {object} echo.HTTPError
 // @Router /v1/filters/{domain_name} [delete]
 func (h Handler) DeleteFilter(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "not implemented")
+	user, err := GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, errMessageUnauthorized)
+	}
+	var requestPayload FilterQueryParam
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	if err := h.domainUsecase.DeleteFilter(auditContext(c), user.UUID, user.Role, requestPayload.ProjectToken, requestPayload.selector()); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
 }
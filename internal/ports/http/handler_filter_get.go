@@ -1,27 +1,42 @@
 package HTTPServer
 
 import (
+	"github.com/aerosystems/checkmail-service/internal/models"
 	"github.com/labstack/echo/v4"
 	"net/http"
 )
 
+type GetFilterListRequest struct {
+	ProjectToken string `query:"projectToken" example:"38fa45ebb919g5d966122bf9g42a38ceb1e4f6eddf1da70ef00afbdc38197d8f"`
+}
+
 // GetFilterList godoc
 // @Summary Get Filter List
-// @Description Get Filter List for all user Projects. Roles allowed: business, staff
+// @Description Get Filter List for the given Project, or for all user Projects when projectToken is omitted. Roles allowed: customer, staff
 // @Tags Filter
 // @Accept json
 // @Produce json
-// @Security ApiKeyAuth
-// @Param userId query int false "user id"
+// @Security BearerAuth
 // @Param projectToken query string false "project token"
-// @Success 200 {object} Filter
-// @Failure 400 {object} echo.HTTPError
+// @Success 200 {array} Filter
+// @Failure 401 {object} echo.HTTPError
 // @Failure 403 {object} echo.HTTPError
 // @Failure 404 {object} echo.HTTPError
-// @Failure 409 {object} echo.HTTPError
 // @Failure 422 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Router /v1/filters [get]
 func (h Handler) GetFilterList(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "not implemented")
+	user, err := GetUserFromContext(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, errMessageUnauthorized)
+	}
+	var requestPayload GetFilterListRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	filters, err := h.domainUsecase.GetFilters(c.Request().Context(), user.UUID, user.Role, requestPayload.ProjectToken)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ModelListToFilterList(filters))
 }
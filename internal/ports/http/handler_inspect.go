@@ -1,11 +1,12 @@
 package HTTPServer
 
 import (
+	"errors"
 	"fmt"
 	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/aerosystems/common-service/customerrors"
 	"github.com/labstack/echo/v4"
 	"net/http"
-	"time"
 )
 
 type InspectRequest struct {
@@ -16,6 +17,85 @@ type InspectRequest struct {
 type InspectResponse struct {
 	Message string `json:"message"`
 	Data    string `json:"data"`
+	InspectResult
+}
+
+type InspectResult struct {
+	Address           string     `json:"address,omitempty" example:"j.o.h.n+news@googlemail.com"`
+	CanonicalAddress  string     `json:"canonicalAddress,omitempty" example:"john@gmail.com"`
+	LocalPart         string     `json:"localPart,omitempty" example:"user"`
+	Flags             []Flag     `json:"flags,omitempty"`
+	Domain            string     `json:"domain" example:"bücher.de"`
+	ASCIIDomain       string     `json:"asciiDomain,omitempty" example:"xn--bcher-kva.de"`
+	RegistrableDomain string     `json:"registrableDomain,omitempty" example:"gmail.com"`
+	Type              string     `json:"type" example:"whitelist"`
+	Rule              *Rule      `json:"rule,omitempty"`
+	SuggestedDomain   string     `json:"suggestedDomain,omitempty" example:"gmail.com"`
+	SuggestedAddress  string     `json:"suggestedAddress,omitempty" example:"user@gmail.com"`
+	MailRoute         *MailRoute `json:"mailRoute,omitempty"`
+	RemainingQuota    int        `json:"remainingQuota" example:"99"`
+	LatencyMs         int64      `json:"latencyMs" example:"3"`
+}
+
+type InspectErrorResponse struct {
+	Code             int    `json:"code" example:"400003"`
+	Message          string `json:"message" example:"domain does not exist"`
+	SuggestedDomain  string `json:"suggestedDomain" example:"hotmail.com"`
+	SuggestedAddress string `json:"suggestedAddress,omitempty" example:"user@hotmail.com"`
+}
+
+type Rule struct {
+	Name   string `json:"name" example:"gmail.com"`
+	Match  string `json:"match" example:"suffix"`
+	Source string `json:"source" example:"global"`
+}
+
+type Flag struct {
+	Code   string `json:"code" example:"role_account"`
+	Detail string `json:"detail" example:"admin is a role account"`
+}
+
+type MailRoute struct {
+	Status  string   `json:"status" example:"mx"`
+	MXHosts []string `json:"mxHosts,omitempty" example:"gmail-smtp-in.l.google.com"`
+}
+
+func ModelToInspectResult(result *models.InspectResult) InspectResult {
+	inspectResult := InspectResult{
+		Address:           models.AddressToUnicode(result.Address),
+		CanonicalAddress:  models.AddressToUnicode(result.CanonicalAddress),
+		LocalPart:         result.LocalPart,
+		Domain:            models.DomainToUnicode(result.Domain),
+		RegistrableDomain: models.DomainToUnicode(result.RegistrableDomain),
+		Type:              result.Type.String(),
+		SuggestedDomain:   models.DomainToUnicode(result.SuggestedDomain),
+		SuggestedAddress:  models.AddressToUnicode(result.SuggestedAddress),
+		RemainingQuota:    result.RemainingQuota,
+		LatencyMs:         result.Latency.Milliseconds(),
+	}
+	if inspectResult.Domain != result.Domain {
+		inspectResult.ASCIIDomain = result.Domain
+	}
+	if result.Rule != nil {
+		inspectResult.Rule = &Rule{
+			Name:   models.DomainToUnicode(result.Rule.Name),
+			Match:  result.Rule.Match.String(),
+			Source: result.Source.String(),
+		}
+	}
+	for _, flag := range result.Flags {
+		inspectResult.Flags = append(inspectResult.Flags, Flag{
+			Code:   flag.Code.String(),
+			Detail: flag.Detail,
+		})
+	}
+	if result.MailRoute != nil {
+		inspectResult.MailRoute = &MailRoute{
+			Status:  result.MailRoute.Status.String(),
+			MXHosts: result.MailRoute.MXHosts,
+		}
+	}
+	return inspectResult
 }
 
 // Inspect godoc
@@ -25,21 +105,37 @@ type InspectResponse struct {
 // @Produce application/json
 // @Param X-Api-Key header string true "api key"
 // @Param data body InspectRequest true "raw request body"
-// @Success 200 {object} Response{data=string}
-// @Failure 400 {object} ErrorResponse
+// @Success 200 {object} InspectResponse
+// @Failure 400 {object} InspectErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /v1/data/inspect [post]
 func (h Handler) Inspect(c echo.Context) error {
-	start := time.Now()
 	var requestPayload InspectRequest
 	if err := c.Bind(&requestPayload); err != nil {
 		return models.ErrInvalidRequestBody
 	}
-	domainType, err := h.inspectUsecase.InspectData(c.Request().Context(), requestPayload.Data, requestPayload.ClientIp, getAPIKeyFromContext(c))
+	result, err := h.inspectUsecase.InspectData(c.Request().Context(), requestPayload.Data, requestPayload.ClientIp, getAPIKeyFromContext(c))
 	if err != nil {
-		return err
+		return inspectError(c, err)
+	}
+	return c.JSON(http.StatusOK, InspectResponse{
+		Message:       fmt.Sprintf("%s is defined as %s per %d milliseconds", requestPayload.Data, result.Type.String(), result.Latency.Milliseconds()),
+		Data:          result.Type.String(),
+		InspectResult: ModelToInspectResult(result),
+	})
+}
+
+func inspectError(c echo.Context, err error) error {
+	var suggestionErr models.SuggestionError
+	var extErr customerrors.ExternalError
+	if errors.As(err, &suggestionErr) && errors.As(err, &extErr) {
+		return c.JSON(extErr.HttpCode, InspectErrorResponse{
+			Code:             extErr.Code,
+			Message:          extErr.Message,
+			SuggestedDomain:  models.DomainToUnicode(suggestionErr.SuggestedDomain),
+			SuggestedAddress: models.AddressToUnicode(suggestionErr.SuggestedAddress),
+		})
 	}
-	duration := time.Since(start)
-	return c.JSON(http.StatusOK, InspectResponse{fmt.Sprintf("%s is defined as %s per %d milliseconds", requestPayload.Data, domainType.String(), duration.Milliseconds()), domainType.String()})
+	return err
 }
@@ -0,0 +1,87 @@
+package HTTPServer
+
+import (
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/aerosystems/common-service/customerrors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type InspectBatchRequest struct {
+	Data     []string `json:"data" example:"user@gmail.com,mailinator.com"`
+	ClientIp string   `json:"clientIp,omitempty"`
+}
+
+type InspectBatchResponse struct {
+	Items []InspectBatchItem `json:"items"`
+}
+
+type InspectBatchItem struct {
+	Data string `json:"data" example:"user@gmail.com"`
+	*InspectResult
+	Error *ItemError `json:"error,omitempty"`
+}
+
+type ItemError struct {
+	Code             int    `json:"code,omitempty" example:"400003"`
+	Message          string `json:"message" example:"domain does not exist"`
+	SuggestedDomain  string `json:"suggestedDomain,omitempty" example:"hotmail.com"`
+	SuggestedAddress string `json:"suggestedAddress,omitempty" example:"user@hotmail.com"`
+}
+
+func ModelToInspectBatchItem(item models.InspectItem) InspectBatchItem {
+	batchItem := InspectBatchItem{Data: item.Data}
+	if item.Err != nil {
+		batchItem.Error = errorToItemError(item.Err)
+		return batchItem
+	}
+	result := ModelToInspectResult(item.Result)
+	batchItem.InspectResult = &result
+	return batchItem
+}
+
+func errorToItemError(err error) *ItemError {
+	itemError := &ItemError{Message: err.Error()}
+	var extErr customerrors.ExternalError
+	if errors.As(err, &extErr) {
+		itemError.Code, itemError.Message = extErr.Code, extErr.Message
+	}
+	var suggestionErr models.SuggestionError
+	if errors.As(err, &suggestionErr) {
+		itemError.SuggestedDomain = models.DomainToUnicode(suggestionErr.SuggestedDomain)
+		itemError.SuggestedAddress = models.AddressToUnicode(suggestionErr.SuggestedAddress)
+	}
+	return itemError
+}
+
+// InspectBatch godoc
+// @Summary get information about a batch of domain names or email addresses
+// @Description Every entry gets its own verdict or error, quota is charged once for the whole batch
+// @Tags inspect
+// @Accept  json
+// @Produce application/json
+// @Param X-Api-Key header string true "api key"
+// @Param data body InspectBatchRequest true "raw request body"
+// @Success 200 {object} InspectBatchResponse
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 413 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/data/inspect/batch [post]
+func (h Handler) InspectBatch(c echo.Context) error {
+	var requestPayload InspectBatchRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	items, err := h.inspectUsecase.InspectDataBatch(c.Request().Context(), requestPayload.Data, requestPayload.ClientIp, getAPIKeyFromContext(c))
+	if err != nil {
+		return err
+	}
+	response := InspectBatchResponse{Items: make([]InspectBatchItem, 0, len(items))}
+	for _, item := range items {
+		response.Items = append(response.Items, ModelToInspectBatchItem(item))
+	}
+	return c.JSON(http.StatusOK, response)
+}
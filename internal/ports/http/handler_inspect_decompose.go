@@ -0,0 +1,60 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type DecomposeRequest struct {
+	Data string `json:"data" example:"user@mx.mail.example.co.uk"`
+}
+
+type DecomposeResponse struct {
+	Domain            string `json:"domain" example:"mx.mail.example.co.uk"`
+	ASCIIDomain       string `json:"asciiDomain,omitempty" example:"xn--bcher-kva.co.uk"`
+	Subdomain         string `json:"subdomain,omitempty" example:"mx.mail"`
+	RegistrableDomain string `json:"registrableDomain,omitempty" example:"example.co.uk"`
+	PublicSuffix      string `json:"publicSuffix" example:"co.uk"`
+	ICANN             bool   `json:"icann" example:"true"`
+}
+
+func ModelToDecomposeResponse(decomposition *models.Decomposition) DecomposeResponse {
+	response := DecomposeResponse{
+		Domain:            models.DomainToUnicode(decomposition.Domain),
+		Subdomain:         models.DomainToUnicode(decomposition.Subdomain),
+		RegistrableDomain: models.DomainToUnicode(decomposition.RegistrableDomain),
+		PublicSuffix:      models.DomainToUnicode(decomposition.PublicSuffix),
+		ICANN:             decomposition.ICANN,
+	}
+	if response.Domain != decomposition.Domain {
+		response.ASCIIDomain = decomposition.Domain
+	}
+	return response
+}
+
+// Decompose godoc
+// @Summary show how domain name or email address decomposes at its public suffix
+// @Description Returns the subdomain, the registrable domain (eTLD+1) and the public suffix registrable rules are matched against. Roles allowed: customer, staff
+// @Tags inspect
+// @Accept  json
+// @Produce application/json
+// @Security BearerAuth
+// @Param data body DecomposeRequest true "raw request body"
+// @Success 200 {object} DecomposeResponse
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/data/decompose [post]
+func (h Handler) Decompose(c echo.Context) error {
+	var requestPayload DecomposeRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	decomposition, err := h.inspectUsecase.DecomposeData(c.Request().Context(), requestPayload.Data)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ModelToDecomposeResponse(decomposition))
+}
@@ -0,0 +1,73 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type ExplainRequest struct {
+	Data         string `json:"data" example:"user@gmail.com"`
+	ProjectToken string `json:"projectToken,omitempty" example:"38fa45ebb919g5d966122bf9g42a38ceb1e4f6eddf1da70ef00afbdc38197d8f"`
+}
+
+type ExplainResponse struct {
+	InspectResult
+	Matches  []RuleMatch `json:"matches"`
+	Decision string      `json:"decision" example:"precedence"`
+	TieBreak string      `json:"tieBreak" example:"blacklist"`
+}
+
+type RuleMatch struct {
+	Rule
+	Type     string `json:"type" example:"whitelist"`
+	Selected bool   `json:"selected" example:"true"`
+}
+
+func ModelToExplainResponse(explanation *models.Explanation) ExplainResponse {
+	matches := make([]RuleMatch, 0, len(explanation.Matches))
+	for _, match := range explanation.Matches {
+		matches = append(matches, RuleMatch{
+			Rule: Rule{
+				Name:   models.DomainToUnicode(match.Rule.Name),
+				Match:  match.Rule.Match.String(),
+				Source: match.Source.String(),
+			},
+			Type:     match.Rule.Type.String(),
+			Selected: match.Selected,
+		})
+	}
+	return ExplainResponse{
+		InspectResult: ModelToInspectResult(explanation.Result),
+		Matches:       matches,
+		Decision:      explanation.Decision.String(),
+		TieBreak:      explanation.TieBreak.String(),
+	}
+}
+
+// Explain godoc
+// @Summary explain the verdict for domain name or email address without consuming quota
+// @Description Returns every rule matching the domain, from the project filters when projectToken is set and from the global list, along with the precedence decision. The verdict, suggestion and DNS check are those of inspect, so an undefined domain failing the DNS check gets the error inspect answers. Roles allowed: staff
+// @Tags inspect
+// @Accept  json
+// @Produce application/json
+// @Security BearerAuth
+// @Param data body ExplainRequest true "raw request body"
+// @Success 200 {object} ExplainResponse
+// @Failure 400 {object} InspectErrorResponse
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 404 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/data/explain [post]
+func (h Handler) Explain(c echo.Context) error {
+	var requestPayload ExplainRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	explanation, err := h.inspectUsecase.ExplainData(c.Request().Context(), requestPayload.Data, requestPayload.ProjectToken)
+	if err != nil {
+		return inspectError(c, err)
+	}
+	return c.JSON(http.StatusOK, ModelToExplainResponse(explanation))
+}
@@ -0,0 +1,42 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type CreateProviderRequest struct {
+	Domain          string `json:"domain" validate:"fqdn,required" example:"googlemail.com"`
+	CanonicalDomain string `json:"canonicalDomain" validate:"omitempty,fqdn" example:"gmail.com"`
+	IgnoreDots      bool   `json:"ignoreDots" example:"true"`
+	TagSeparators   string `json:"tagSeparators" example:"+"`
+}
+
+// CreateProvider godoc
+// @Summary Create Provider
+// @Description Create the address normalization rules of a mail provider, canonicalDomain defaults to domain. Roles allowed: staff
+// @Tags providers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider body CreateProviderRequest true "raw request body"
+// @Success 201 {object} Provider
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/providers [post]
+func (h Handler) CreateProvider(c echo.Context) error {
+	var requestPayload CreateProviderRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	provider, err := h.domainUsecase.CreateProvider(c.Request().Context(), requestPayload.Domain, requestPayload.CanonicalDomain, requestPayload.IgnoreDots, requestPayload.TagSeparators)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, ModelToProvider(*provider))
+}
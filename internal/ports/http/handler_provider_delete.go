@@ -0,0 +1,37 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type DeleteProviderRequest struct {
+	Domain string `param:"domain" validate:"fqdn,required" example:"googlemail.com"`
+}
+
+// DeleteProvider godoc
+// @Summary Delete Provider
+// @Description Delete the address normalization rules of a mail provider, its addresses are no longer normalized. Roles allowed: staff
+// @Tags providers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Provider Domain"
+// @Success 204
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 404 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/providers/{domain} [delete]
+func (h Handler) DeleteProvider(c echo.Context) error {
+	var requestPayload DeleteProviderRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	if err := h.domainUsecase.DeleteProvider(c.Request().Context(), requestPayload.Domain); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,26 @@
+package HTTPServer
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// GetProviderList godoc
+// @Summary Get Provider List
+// @Description Get the address normalization rules of all mail providers. Roles allowed: staff
+// @Tags providers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} Provider
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/providers [get]
+func (h Handler) GetProviderList(c echo.Context) error {
+	providers, err := h.domainUsecase.GetProviders(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ModelListToProviderList(providers))
+}
@@ -0,0 +1,43 @@
+package HTTPServer
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type UpdateProviderRequest struct {
+	Domain          string `param:"domain" validate:"fqdn,required" example:"googlemail.com"`
+	CanonicalDomain string `json:"canonicalDomain" validate:"omitempty,fqdn" example:"gmail.com"`
+	IgnoreDots      bool   `json:"ignoreDots" example:"true"`
+	TagSeparators   string `json:"tagSeparators" example:"+"`
+}
+
+// UpdateProvider godoc
+// @Summary Update Provider
+// @Description Replace the address normalization rules of a mail provider, canonicalDomain defaults to domain. Roles allowed: staff
+// @Tags providers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Provider Domain"
+// @Param provider body UpdateProviderRequest true "raw request body"
+// @Success 200 {object} Provider
+// @Failure 400 {object} echo.HTTPError
+// @Failure 401 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 404 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /v1/providers/{domain} [patch]
+func (h Handler) UpdateProvider(c echo.Context) error {
+	var requestPayload UpdateProviderRequest
+	if err := c.Bind(&requestPayload); err != nil {
+		return models.ErrInvalidRequestBody
+	}
+	provider, err := h.domainUsecase.UpdateProvider(c.Request().Context(), requestPayload.Domain, requestPayload.CanonicalDomain, requestPayload.IgnoreDots, requestPayload.TagSeparators)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ModelToProvider(*provider))
+}
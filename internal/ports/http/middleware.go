@@ -17,7 +17,8 @@ type ctxKey int
 const (
 	userContextKey ctxKey = iota
 
-	xAPIHeaderName = "X-Api-Key"
+	xAPIHeaderName        = "X-Api-Key"
+	auditReasonHeaderName = "X-Audit-Reason"
 
 	errMessageForbidden    = "access denied"
 	errMessageUnauthorized = "invalid token"
@@ -96,6 +97,19 @@ func GetUserFromContext(ctx context.Context) (User, error) {
 	return user, nil
 }
 
+func auditContext(c echo.Context) context.Context {
+	ctx := c.Request().Context()
+	meta := models.AuditMeta{
+		ActorRole: models.UnknownRole,
+		Reason:    strings.TrimSpace(c.Request().Header.Get(auditReasonHeaderName)),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	}
+	if user, err := GetUserFromContext(ctx); err == nil {
+		meta.ActorUUID, meta.ActorRole = user.UUID, user.Role
+	}
+	return models.WithAuditMeta(ctx, meta)
+}
+
 func isAccess(roles []models.Role, role models.Role) bool {
 	for _, r := range roles {
 		if r == role {
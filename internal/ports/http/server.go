@@ -56,17 +56,35 @@ func NewHTTPServer(
 				}
 			}),
 			httpserver.WithMiddleware(middleware.Recover()),
+			httpserver.WithMiddleware(middleware.RequestID()),
 
 			httpserver.WithRouter(http.MethodPost, "/v1/data/inspect", handler.Inspect),
+			httpserver.WithRouter(http.MethodPost, "/v1/data/inspect/batch", handler.InspectBatch),
+			httpserver.WithRouter(http.MethodPost, "/v1/data/explain", handler.Explain, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodPost, "/v1/data/decompose", handler.Decompose, firebaseAuth.RoleBasedAuth(models.CustomerRole, models.StaffRole)),
+			httpserver.WithRouter(http.MethodGet, "/v1/cache/stats", handler.GetCacheStats, firebaseAuth.RoleBasedAuth(models.StaffRole)),
 			httpserver.WithRouter(http.MethodPost, "/v1/access", handler.CreateAccess),
 			httpserver.WithRouter(http.MethodPost, "/v1/domains/count", handler.Count),
 			httpserver.WithRouter(http.MethodGet, "/v1/filters", handler.GetFilterList, firebaseAuth.RoleBasedAuth(models.CustomerRole, models.StaffRole)),
+			httpserver.WithRouter(http.MethodGet, "/v1/filters/export", handler.ExportFilters, firebaseAuth.RoleBasedAuth(models.CustomerRole, models.StaffRole)),
 			httpserver.WithRouter(http.MethodPost, "/v1/filters", handler.CreateFilter, firebaseAuth.RoleBasedAuth(models.CustomerRole, models.StaffRole)),
+			httpserver.WithRouter(http.MethodPut, "/v1/filters/:domain_name/expiry", handler.SetFilterExpiry, firebaseAuth.RoleBasedAuth(models.CustomerRole, models.StaffRole)),
 			httpserver.WithRouter(http.MethodDelete, "/v1/filters/:domain_name", handler.DeleteFilter, firebaseAuth.RoleBasedAuth(models.CustomerRole, models.StaffRole)),
+			httpserver.WithRouter(http.MethodGet, "/v1/domains", handler.GetDomainList, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodGet, "/v1/domains/export", handler.ExportDomains, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodGet, "/v1/domains/trash", handler.GetDomainTrash, firebaseAuth.RoleBasedAuth(models.StaffRole)),
 			httpserver.WithRouter(http.MethodGet, "/v1/domains/:domain_name", handler.GetDomain, firebaseAuth.RoleBasedAuth(models.StaffRole)),
 			httpserver.WithRouter(http.MethodPost, "/v1/domains", handler.CreateDomain, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodPost, "/v1/domains/import", handler.ImportDomains, firebaseAuth.RoleBasedAuth(models.StaffRole)),
 			httpserver.WithRouter(http.MethodPatch, "/v1/domains/:domain_name", handler.UpdateDomain, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodPost, "/v1/domains/:domain_name/restore", handler.RestoreDomain, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodPut, "/v1/domains/:domain_name/expiry", handler.SetDomainExpiry, firebaseAuth.RoleBasedAuth(models.StaffRole)),
 			httpserver.WithRouter(http.MethodDelete, "/v1/domains/:domain_name", handler.DeleteDomain, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodGet, "/v1/audit", handler.GetAuditLog, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodGet, "/v1/providers", handler.GetProviderList, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodPost, "/v1/providers", handler.CreateProvider, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodPatch, "/v1/providers/:domain", handler.UpdateProvider, firebaseAuth.RoleBasedAuth(models.StaffRole)),
+			httpserver.WithRouter(http.MethodDelete, "/v1/providers/:domain", handler.DeleteProvider, firebaseAuth.RoleBasedAuth(models.StaffRole)),
 		),
 	}
 }
@@ -3,6 +3,7 @@ package usecases
 import (
 	"context"
 	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
 	"time"
 )
 
@@ -25,9 +26,10 @@ func (a AccessUsecase) GetAccess(ctx context.Context, token string) (*models.Acc
 	return access, nil
 }
 
-func (a AccessUsecase) CreateAccess(ctx context.Context, token, subscriptionType string, accessCount int, accessTime time.Time) error {
+func (a AccessUsecase) CreateAccess(ctx context.Context, token string, customerUUID uuid.UUID, subscriptionType string, accessCount int, accessTime time.Time) error {
 	return a.apiAccessRepo.CreateOrUpdate(ctx, &models.Access{
 		Token:            token,
+		CustomerUUID:     customerUUID,
 		SubscriptionType: models.SubscriptionTypeFromString(subscriptionType),
 		AccessCount:      accessCount,
 		AccessTime:       accessTime,
@@ -3,29 +3,78 @@ package usecases
 import (
 	"context"
 	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
+	"time"
 )
 
 type DomainRepository interface {
-	FindByName(ctx context.Context, name string) (*models.Domain, error)
+	FindByName(ctx context.Context, name string, domainType models.Type, domainMatch models.Match) (*models.Domain, error)
+	FindSuggestionCandidates(ctx context.Context) ([]string, error)
+	Search(ctx context.Context, query models.DomainQuery) (*models.DomainPage, error)
+	SearchDeleted(ctx context.Context, query models.DomainQuery) (*models.DomainPage, error)
+	Import(ctx context.Context, domains []models.Domain, batchSize int, dryRun bool) (*models.ImportResult, error)
 	Create(ctx context.Context, domain *models.Domain) error
-	Update(ctx context.Context, domain *models.Domain) error
+	Update(ctx context.Context, previous, domain *models.Domain) error
 	Delete(ctx context.Context, domain *models.Domain) error
+	Restore(ctx context.Context, name string, domainType models.Type, domainMatch models.Match) (*models.Domain, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+	ArchiveExpired(ctx context.Context, now time.Time, limit int) ([]models.Domain, error)
 	CountDomainTypes(ctx context.Context) (map[models.Type]int, error)
-	MatchEquals(ctx context.Context, name string) (*models.Domain, error)
-	MatchPrefix(ctx context.Context, name string) (*models.Domain, error)
-	MatchSuffix(ctx context.Context, name string) (*models.Domain, error)
-	MatchContains(ctx context.Context, name string) (*models.Domain, error)
+	MatchEquals(ctx context.Context, name string) ([]models.Domain, error)
+	MatchPrefix(ctx context.Context, name string) ([]models.Domain, error)
+	MatchSuffix(ctx context.Context, name string) ([]models.Domain, error)
+	MatchRegistrable(ctx context.Context, name string) ([]models.Domain, error)
+	MatchContains(ctx context.Context, name string) ([]models.Domain, error)
+	MatchGlob(ctx context.Context, name string) ([]models.Domain, error)
+	MatchRegex(ctx context.Context, name string) ([]models.Domain, error)
 }
 
 type FilterRepository interface {
-	FindAll() ([]models.Filter, error)
-	FindByName(name string) (*models.Filter, error)
-	FindByProjectToken(projectToken string) ([]models.Filter, error)
-	Create(domain *models.Filter) error
-	CreateOrUpdate(domain *models.Filter) error
-	Delete(domain *models.Filter) error
-	MatchEquals(domainName, projectToken string) (*models.Filter, error)
-	MatchSuffix(domainName, projectToken string) (*models.Filter, error)
+	FindAll(ctx context.Context) ([]models.Filter, error)
+	FindByName(ctx context.Context, projectToken, name string, filterType models.Type, filterMatch models.Match) (*models.Filter, error)
+	FindByProjectToken(ctx context.Context, projectToken string) ([]models.Filter, error)
+	FindBatch(ctx context.Context, query models.FilterQuery) ([]models.Filter, error)
+	Create(ctx context.Context, filter *models.Filter) error
+	CreateOrUpdate(ctx context.Context, filter *models.Filter) error
+	Update(ctx context.Context, filter *models.Filter) error
+	Delete(ctx context.Context, filter *models.Filter) error
+	ArchiveExpired(ctx context.Context, now time.Time, limit int) ([]models.Filter, error)
+	MatchEquals(ctx context.Context, domainName, projectToken string) ([]models.Filter, error)
+	MatchSuffix(ctx context.Context, domainName, projectToken string) ([]models.Filter, error)
+	MatchRegistrable(ctx context.Context, domainName, projectToken string) ([]models.Filter, error)
+	MatchPrefix(ctx context.Context, domainName, projectToken string) ([]models.Filter, error)
+	MatchContains(ctx context.Context, domainName, projectToken string) ([]models.Filter, error)
+	MatchGlob(ctx context.Context, domainName, projectToken string) ([]models.Filter, error)
+	MatchRegex(ctx context.Context, domainName, projectToken string) ([]models.Filter, error)
+}
+
+type ProviderRepository interface {
+	FindByDomain(ctx context.Context, domain string) (*models.Provider, error)
+	FindAll(ctx context.Context) ([]models.Provider, error)
+	Get(ctx context.Context, domain string) (*models.Provider, error)
+	Create(ctx context.Context, provider *models.Provider) error
+	Update(ctx context.Context, provider *models.Provider) error
+	Delete(ctx context.Context, domain string) error
+}
+
+type DisposableRepository interface {
+	MatchDomain(ctx context.Context, name string) ([]models.Domain, error)
+	MatchMXHost(ctx context.Context, host string) ([]models.Domain, error)
+}
+
+type LookupService interface {
+	Lookup(ctx context.Context, domainName string) (models.Type, error)
+}
+
+type DNSResolver interface {
+	LookupMailRoute(ctx context.Context, name string) (*models.MailRoute, error)
+}
+
+type VerdictCache interface {
+	Load(ctx context.Context, domainName, projectToken string, resolve func(ctx context.Context) (*models.Verdict, error)) (*models.Verdict, error)
+	Invalidate(ctx context.Context, projectToken string, rules ...models.Domain)
+	Purge(ctx context.Context)
+	Stats(ctx context.Context) models.CacheStats
 }
 
 type ReviewRepository interface {
@@ -34,6 +83,16 @@ type ReviewRepository interface {
 
 type AccessRepository interface {
 	Get(ctx context.Context, token string) (*models.Access, error)
+	FindByCustomerUUID(ctx context.Context, customerUUID uuid.UUID) ([]models.Access, error)
 	CreateOrUpdate(ctx context.Context, access *models.Access) error
 	Tx(ctx context.Context, token string, fn func(a *models.Access) (any, error)) (any, error)
 }
+
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, entries ...models.AuditEntry) error
+	Find(ctx context.Context, query models.AuditQuery) (*models.AuditPage, error)
+}
@@ -0,0 +1,106 @@
+package usecases
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const expirySweepBatchSize = 1000
+
+func (mu ManageUsecase) ArchiveExpiredRules(ctx context.Context) (int, error) {
+	now := time.Now()
+	archived := 0
+	for {
+		var domains []models.Domain
+		if err := mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+			var err error
+			if domains, err = mu.domainRepo.ArchiveExpired(ctx, now, expirySweepBatchSize); err != nil || len(domains) == 0 {
+				return err
+			}
+			return mu.recordAudit(ctx, expiryAuditEntries(ctx, "", domains)...)
+		}); err != nil {
+			return archived, err
+		}
+		if len(domains) > 0 {
+			archived += len(domains)
+			mu.domainsChanged(ctx, domains...)
+		}
+		if len(domains) < expirySweepBatchSize {
+			break
+		}
+	}
+	for {
+		var filters []models.Filter
+		if err := mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+			var err error
+			if filters, err = mu.filterRepo.ArchiveExpired(ctx, now, expirySweepBatchSize); err != nil || len(filters) == 0 {
+				return err
+			}
+			entries := make([]models.AuditEntry, 0, len(filters))
+			for i := range filters {
+				entries = append(entries, expiryAuditEntries(ctx, filters[i].ProjectToken, []models.Domain{filters[i].Domain})...)
+			}
+			return mu.recordAudit(ctx, entries...)
+		}); err != nil {
+			return archived, err
+		}
+		archived += len(filters)
+		for i := range filters {
+			mu.filtersChanged(ctx, &filters[i])
+		}
+		if len(filters) < expirySweepBatchSize {
+			break
+		}
+	}
+	return archived, nil
+}
+
+func expiryAuditEntries(ctx context.Context, projectToken string, rules []models.Domain) []models.AuditEntry {
+	entries := make([]models.AuditEntry, 0, len(rules))
+	for i := range rules {
+		entry := models.NewAuditEntry(ctx, projectToken, &rules[i], nil)
+		entry.Action = models.ExpireAuditAction
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+type ExpirySweeper struct {
+	log           *logrus.Logger
+	manageUsecase *ManageUsecase
+	interval      time.Duration
+}
+
+func NewExpirySweeper(log *logrus.Logger, manageUsecase *ManageUsecase, interval time.Duration) *ExpirySweeper {
+	return &ExpirySweeper{
+		log:           log,
+		manageUsecase: manageUsecase,
+		interval:      interval,
+	}
+}
+
+func (s *ExpirySweeper) Run(ctx context.Context) error {
+	if s.interval <= 0 {
+		return nil
+	}
+	ctx = models.WithAuditMeta(ctx, models.AuditMeta{ActorRole: models.UnknownRole, Reason: "rule expired"})
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			archived, err := s.manageUsecase.ArchiveExpiredRules(ctx)
+			if err != nil {
+				s.log.Errorf("could not archive expired rules: %v", err)
+			}
+			if archived > 0 {
+				s.log.Infof("archived %d expired rules", archived)
+			}
+		}
+	}
+}
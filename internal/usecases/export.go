@@ -0,0 +1,219 @@
+package usecases
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
+	"io"
+	"time"
+)
+
+const exportBatchSize = 1000
+
+// ExportDomains validates the parameters before anything is written, a failure afterwards cuts the output short.
+func (mu ManageUsecase) ExportDomains(ctx context.Context, w io.Writer, format, domainType, domainMatch string) error {
+	query := models.DomainQuery{Sort: models.NameSort, Limit: exportBatchSize}
+	var err error
+	if query.Type, query.Match, err = parseRuleFilter(domainType, domainMatch); err != nil {
+		return err
+	}
+	encoder, err := newExportEncoder(w, models.ExportFormatFromString(format))
+	if err != nil {
+		return err
+	}
+	for {
+		page, err := mu.domainRepo.Search(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, domain := range page.Domains {
+			if err := encoder.encode(domain); err != nil {
+				return err
+			}
+		}
+		if err := encoder.flush(); err != nil {
+			return err
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		if query.Cursor, err = models.DecodeDomainCursor(page.NextCursor, query.Sort, query.Descending); err != nil {
+			return err
+		}
+	}
+}
+
+func (mu ManageUsecase) ExportFilters(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken string, w io.Writer, format, domainType, domainMatch string) error {
+	if err := mu.checkProjectAccess(ctx, userUUID, userRole, projectToken); err != nil {
+		return err
+	}
+	query := models.FilterQuery{ProjectToken: projectToken, Limit: exportBatchSize}
+	var err error
+	if query.Type, query.Match, err = parseRuleFilter(domainType, domainMatch); err != nil {
+		return err
+	}
+	encoder, err := newExportEncoder(w, models.ExportFormatFromString(format))
+	if err != nil {
+		return err
+	}
+	for {
+		filters, err := mu.filterRepo.FindBatch(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, filter := range filters {
+			if err := encoder.encode(filter.Domain); err != nil {
+				return err
+			}
+		}
+		if err := encoder.flush(); err != nil {
+			return err
+		}
+		if len(filters) < query.Limit {
+			return nil
+		}
+		query.After = &filters[len(filters)-1].Domain
+	}
+}
+
+type exportEncoder interface {
+	encode(domain models.Domain) error
+	flush() error
+}
+
+func newExportEncoder(w io.Writer, format models.ExportFormat) (exportEncoder, error) {
+	switch format {
+	case models.CSVExportFormat:
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"name", "type", "coverage", "expiresAt"}); err != nil {
+			return nil, err
+		}
+		return &csvEncoder{w: w, writer: writer}, nil
+	case models.NDJSONExportFormat:
+		return &ndjsonEncoder{w: w, encoder: json.NewEncoder(w)}, nil
+	case models.HostsExportFormat:
+		return &hostsEncoder{w: w}, nil
+	case models.RPZExportFormat:
+		encoder := &rpzEncoder{w: w}
+		return encoder, encoder.header()
+	default:
+		return nil, models.ErrInvalidExportFormat
+	}
+}
+
+func flushWriter(w io.Writer) {
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+}
+
+type csvEncoder struct {
+	w      io.Writer
+	writer *csv.Writer
+}
+
+func (e *csvEncoder) encode(domain models.Domain) error {
+	var expiresAt string
+	if domain.ExpiresAt != nil {
+		expiresAt = domain.ExpiresAt.Format(time.RFC3339)
+	}
+	return e.writer.Write([]string{domain.Name, domain.Type.String(), domain.Match.String(), expiresAt})
+}
+
+func (e *csvEncoder) flush() error {
+	e.writer.Flush()
+	flushWriter(e.w)
+	return e.writer.Error()
+}
+
+type ndjsonEncoder struct {
+	w       io.Writer
+	encoder *json.Encoder
+}
+
+type ndjsonRule struct {
+	Name      string     `json:"name"`
+	Type      string     `json:"type"`
+	Coverage  string     `json:"coverage"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+func (e *ndjsonEncoder) encode(domain models.Domain) error {
+	return e.encoder.Encode(ndjsonRule{
+		Name:      domain.Name,
+		Type:      domain.Type.String(),
+		Coverage:  domain.Match.String(),
+		ExpiresAt: domain.ExpiresAt,
+		CreatedAt: domain.CreatedAt,
+		UpdatedAt: domain.UpdatedAt,
+	})
+}
+
+func (e *ndjsonEncoder) flush() error {
+	flushWriter(e.w)
+	return nil
+}
+
+type hostsEncoder struct {
+	w io.Writer
+}
+
+func (e *hostsEncoder) encode(domain models.Domain) error {
+	if domain.Type == models.WhitelistType || domain.Type == models.UndefinedType {
+		return nil
+	}
+	switch domain.Match {
+	case models.EqualsMatch, models.SuffixMatch, models.RegistrableMatch:
+		_, err := fmt.Fprintf(e.w, "0.0.0.0 %s\n", domain.Name)
+		return err
+	default:
+		return nil
+	}
+}
+
+func (e *hostsEncoder) flush() error {
+	flushWriter(e.w)
+	return nil
+}
+
+// rpzEncoder covers subdomains with a wildcard, rules on names in the middle of a label can not be expressed.
+type rpzEncoder struct {
+	w io.Writer
+}
+
+func (e *rpzEncoder) header() error {
+	_, err := fmt.Fprintf(e.w, "$TTL 300\n@ SOA localhost. root.localhost. %d 3600 600 86400 300\n@ NS localhost.\n", time.Now().Unix())
+	return err
+}
+
+func (e *rpzEncoder) encode(domain models.Domain) error {
+	var action string
+	switch domain.Type {
+	case models.BlacklistType, models.DisposableType:
+		action = "."
+	case models.WhitelistType:
+		action = "rpz-passthru."
+	default:
+		return nil
+	}
+	switch domain.Match {
+	case models.EqualsMatch:
+		_, err := fmt.Fprintf(e.w, "%s CNAME %s\n", domain.Name, action)
+		return err
+	case models.SuffixMatch, models.RegistrableMatch:
+		_, err := fmt.Fprintf(e.w, "%s CNAME %s\n*.%s CNAME %s\n", domain.Name, action, domain.Name, action)
+		return err
+	default:
+		return nil
+	}
+}
+
+func (e *rpzEncoder) flush() error {
+	flushWriter(e.w)
+	return nil
+}
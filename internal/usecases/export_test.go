@@ -0,0 +1,51 @@
+package usecases
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func (r *fakeDomainRepo) Search(_ context.Context, query models.DomainQuery) (*models.DomainPage, error) {
+	key := func(rule models.Domain) string {
+		return rule.Name + "\x00" + rule.Type.String() + "\x00" + rule.Match.String()
+	}
+	rules := slices.SortedFunc(slices.Values(r.rules), func(a, b models.Domain) int { return cmp.Compare(key(a), key(b)) })
+	if cursor := query.Cursor; cursor != nil {
+		after := cursor.Name + "\x00" + cursor.Type + "\x00" + cursor.Match
+		rules = slices.DeleteFunc(rules, func(rule models.Domain) bool { return key(rule) <= after })
+	}
+	page := &models.DomainPage{Domains: rules}
+	if len(rules) > query.Limit {
+		page.Domains = rules[:query.Limit]
+		page.NextCursor = models.NewDomainCursor(page.Domains[query.Limit-1], query.Sort, query.Descending).Encode()
+	}
+	return page, nil
+}
+
+func TestExportDomainsSharedNames(t *testing.T) {
+	domainRepo := &fakeDomainRepo{}
+	for i := range exportBatchSize/3 + 1 {
+		name := fmt.Sprintf("%04d.example", i)
+		domainRepo.rules = append(domainRepo.rules,
+			models.Domain{Name: name, Type: models.BlacklistType, Match: models.EqualsMatch},
+			models.Domain{Name: name, Type: models.BlacklistType, Match: models.SuffixMatch},
+			models.Domain{Name: name, Type: models.WhitelistType, Match: models.EqualsMatch},
+		)
+	}
+	mu := NewManageUsecase(nil, domainRepo, nil, nil, nil, fakeTransactor{}, nil, time.Minute, 1, 1)
+
+	var out bytes.Buffer
+	if err := mu.ExportDomains(context.Background(), &out, models.NDJSONExportFormat.String(), "", ""); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != len(domainRepo.rules) {
+		t.Fatalf("got %d rules, want %d", lines, len(domainRepo.rules))
+	}
+}
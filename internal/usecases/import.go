@@ -0,0 +1,269 @@
+package usecases
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxInvalidatedRules bounds the rules an import invalidates one by one, a larger import purges the verdict cache.
+const maxInvalidatedRules = 1000
+
+type importRow struct {
+	line       int
+	data       string
+	name       string
+	domainType string
+	coverage   string
+	expiresAt  string
+	err        error
+}
+
+// ImportDomains keeps the first of the rows repeating a rule, a row without an expiry keeps the one of an existing rule.
+// A row giving a name and coverage another type than an earlier row or a live rule is reported and left out.
+func (mu ManageUsecase) ImportDomains(ctx context.Context, r io.Reader, format, defaultType, defaultCoverage string, dryRun bool) (*models.ImportReport, error) {
+	if defaultCoverage == "" {
+		defaultCoverage = models.EqualsMatch.String()
+	}
+	rows, err := readImportRows(r, models.ImportFormatFromString(format), mu.importMaxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{DryRun: dryRun, Errors: make([]models.ImportError, 0)}
+	domains := make([]models.Domain, 0, len(rows))
+	// a name and coverage keep the type of their first row, a later row of another type conflicts with it
+	kept := make(map[models.Domain]importRow, len(rows))
+	for _, row := range rows {
+		domain, err := validateImportRow(row, defaultType, defaultCoverage)
+		if err != nil {
+			report.AddError(row.line, row.data, err.Error())
+			continue
+		}
+		key := models.Domain{Name: domain.Name, Match: domain.Match}
+		if first, ok := kept[key]; ok {
+			if first.domainType == domain.Type.String() {
+				report.Skipped++
+			} else {
+				report.AddError(row.line, row.data, fmt.Sprintf("conflicts with line %d, a rule of another type for the name and coverage", first.line))
+			}
+			continue
+		}
+		row.domainType = domain.Type.String()
+		kept[key] = row
+		domains = append(domains, *domain)
+	}
+	if len(domains) == 0 {
+		return report, nil
+	}
+
+	var result *models.ImportResult
+	if err := mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if result, err = mu.domainRepo.Import(ctx, domains, mu.importBatchSize, dryRun); err != nil {
+			return err
+		}
+		if dryRun || len(result.Inserted)+len(result.Updated) == 0 {
+			return nil
+		}
+		return mu.recordAudit(ctx, importAuditEntries(ctx, result)...)
+	}); err != nil {
+		return nil, err
+	}
+	report.Inserted = len(result.Inserted)
+	report.Updated = len(result.Updated)
+	report.Skipped += result.Skipped
+	for _, domain := range result.Conflicts {
+		row := kept[models.Domain{Name: domain.Name, Match: domain.Match}]
+		report.AddError(row.line, row.data, "conflicts with a live rule of another type for the name and coverage")
+	}
+	slices.SortStableFunc(report.Errors, func(a, b models.ImportError) int { return a.Line - b.Line })
+	if !dryRun && report.Inserted+report.Updated > 0 {
+		mu.importChanged(ctx, result)
+	}
+	return report, nil
+}
+
+func (mu ManageUsecase) importChanged(ctx context.Context, result *models.ImportResult) {
+	rules := make([]models.Domain, 0, len(result.Inserted)+len(result.Updated)+len(result.Replaced))
+	rules = append(rules, result.Inserted...)
+	rules = append(rules, result.Updated...)
+	rules = append(rules, result.Replaced...)
+	if len(rules) <= maxInvalidatedRules {
+		mu.domainsChanged(ctx, rules...)
+		return
+	}
+	mu.domainCounts.reset()
+	if mu.verdictCache != nil {
+		mu.verdictCache.Purge(ctx)
+	}
+}
+
+func importAuditEntries(ctx context.Context, result *models.ImportResult) []models.AuditEntry {
+	entries := make([]models.AuditEntry, 0, len(result.Inserted)+len(result.Updated))
+	for i := range result.Inserted {
+		entries = append(entries, models.NewAuditEntry(ctx, "", nil, &result.Inserted[i]))
+	}
+	for i := range result.Updated {
+		entries = append(entries, models.NewAuditEntry(ctx, "", &result.Replaced[i], &result.Updated[i]))
+	}
+	return entries
+}
+
+func validateImportRow(row importRow, defaultType, defaultCoverage string) (*models.Domain, error) {
+	if row.err != nil {
+		return nil, row.err
+	}
+	if row.domainType == "" {
+		row.domainType = defaultType
+	}
+	if row.coverage == "" {
+		row.coverage = defaultCoverage
+	}
+	domainType := models.DomainTypeFromString(row.domainType)
+	if domainType == models.UndefinedType {
+		return nil, fmt.Errorf("unknown type %q", row.domainType)
+	}
+	domainMatch := models.DomainMatchFromString(row.coverage)
+	if domainMatch == models.UndefinedMatch {
+		return nil, fmt.Errorf("unknown coverage %q", row.coverage)
+	}
+	name, err := normalizeRuleName(row.name, domainMatch)
+	if err != nil {
+		return nil, err
+	}
+	domain := &models.Domain{Name: name, Type: domainType, Match: domainMatch}
+	if row.expiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, row.expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry %q", row.expiresAt)
+		}
+		if err := validateExpiry(&expiresAt); err != nil {
+			return nil, err
+		}
+		domain.ExpiresAt = &expiresAt
+	}
+	return domain, nil
+}
+
+type importRows struct {
+	rows    []importRow
+	maxRows int
+}
+
+func (r *importRows) add(row importRow) error {
+	if len(r.rows) >= r.maxRows {
+		return models.ErrImportTooLarge
+	}
+	r.rows = append(r.rows, row)
+	return nil
+}
+
+func readImportRows(r io.Reader, format models.ImportFormat, maxRows int) ([]importRow, error) {
+	rows := &importRows{maxRows: maxRows}
+	var err error
+	switch format {
+	case models.CSVImportFormat:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.Comment = '#'
+		reader.TrimLeadingSpace = true
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return rows.rows, nil
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				if err := rows.add(importRow{line: parseErr.Line, err: parseErr.Err}); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			line, _ := reader.FieldPos(0)
+			if len(rows.rows) == 0 && strings.EqualFold(record[0], "name") {
+				continue
+			}
+			row := importRow{line: line, data: strings.Join(record, ","), name: strings.TrimSpace(record[0])}
+			if len(record) > 1 {
+				row.domainType = strings.TrimSpace(record[1])
+			}
+			if len(record) > 2 {
+				row.coverage = strings.TrimSpace(record[2])
+			}
+			if len(record) > 3 {
+				row.expiresAt = strings.TrimSpace(record[3])
+			}
+			if len(record) > 4 {
+				row.err = errors.New("too many fields")
+			}
+			if err := rows.add(row); err != nil {
+				return nil, err
+			}
+		}
+	case models.NDJSONImportFormat:
+		err = scanImportLines(r, rows, func(line int, data string) (importRow, bool) {
+			var entry struct {
+				Name      string `json:"name"`
+				Type      string `json:"type"`
+				Coverage  string `json:"coverage"`
+				ExpiresAt string `json:"expiresAt"`
+			}
+			row := importRow{line: line, data: data}
+			if err := json.Unmarshal([]byte(data), &entry); err != nil {
+				row.err = errors.New("malformed JSON")
+				return row, true
+			}
+			row.name, row.domainType, row.coverage, row.expiresAt = entry.Name, entry.Type, entry.Coverage, entry.ExpiresAt
+			return row, true
+		})
+	case models.TextImportFormat:
+		// Hosts file lines such as "0.0.0.0 example.com" are accepted, the last field is the name.
+		err = scanImportLines(r, rows, func(line int, data string) (importRow, bool) {
+			if idx := strings.IndexByte(data, '#'); idx >= 0 {
+				data = strings.TrimSpace(data[:idx])
+			}
+			fields := strings.Fields(data)
+			if len(fields) == 0 {
+				return importRow{}, false
+			}
+			return importRow{line: line, data: data, name: fields[len(fields)-1]}, true
+		})
+	default:
+		return nil, models.ErrInvalidImportFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rows.rows, nil
+}
+
+func scanImportLines(r io.Reader, rows *importRows, parse func(line int, data string) (importRow, bool)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+		row, ok := parse(line, data)
+		if !ok {
+			continue
+		}
+		if err := rows.add(row); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,248 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func (r *fakeDomainRepo) Import(_ context.Context, domains []models.Domain, _ int, dryRun bool) (*models.ImportResult, error) {
+	result := &models.ImportResult{}
+	for _, domain := range domains {
+		i := slices.IndexFunc(r.rules, domain.Same)
+		taken := slices.ContainsFunc(r.rules, func(rule models.Domain) bool {
+			return rule.DeletedAt == nil && rule.Name == domain.Name && rule.Match == domain.Match
+		})
+		switch {
+		case i < 0 && taken:
+			result.Conflicts = append(result.Conflicts, domain)
+		case i < 0:
+			result.Inserted = append(result.Inserted, domain)
+		case domain.ExpiresAt == nil:
+			result.Skipped++
+		default:
+			result.Replaced = append(result.Replaced, r.rules[i])
+			result.Updated = append(result.Updated, domain)
+			if !dryRun {
+				r.rules[i].ExpiresAt = domain.ExpiresAt
+			}
+		}
+	}
+	if !dryRun {
+		r.rules = append(r.rules, result.Inserted...)
+	}
+	return result, nil
+}
+
+func TestReadImportRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		format models.ImportFormat
+		input  string
+		want   []importRow
+	}{
+		{
+			name:   "csv",
+			format: models.CSVImportFormat,
+			input:  "name,type,coverage,expiresAt\nexample.com,blacklist,suffix,2030-01-02T03:04:05Z\n# comment\nexample.org\na,b,c,d,e\n",
+			want: []importRow{
+				{line: 2, name: "example.com", domainType: "blacklist", coverage: "suffix", expiresAt: "2030-01-02T03:04:05Z"},
+				{line: 4, name: "example.org"},
+				{line: 5, name: "a", domainType: "b", coverage: "c", expiresAt: "d", err: errors.New("too many fields")},
+			},
+		},
+		{
+			name:   "ndjson",
+			format: models.NDJSONImportFormat,
+			input:  "{\"name\":\"example.com\",\"type\":\"whitelist\",\"coverage\":\"equals\",\"expiresAt\":\"2030-01-02T03:04:05Z\"}\n\n{\"name\":\n",
+			want: []importRow{
+				{line: 1, name: "example.com", domainType: "whitelist", coverage: "equals", expiresAt: "2030-01-02T03:04:05Z"},
+				{line: 3, err: errors.New("malformed JSON")},
+			},
+		},
+		{
+			name:   "text",
+			format: models.TextImportFormat,
+			input:  "# hosts\n0.0.0.0 example.com # tracker\nexample.org\n",
+			want: []importRow{
+				{line: 2, name: "example.com"},
+				{line: 3, name: "example.org"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readImportRows(strings.NewReader(tt.input), tt.format, 10)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d: %+v", len(rows), len(tt.want), rows)
+			}
+			for i, want := range tt.want {
+				got := rows[i]
+				if got.line != want.line || got.name != want.name || got.domainType != want.domainType || got.coverage != want.coverage || got.expiresAt != want.expiresAt || (got.err == nil) != (want.err == nil) {
+					t.Fatalf("row %d: got %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadImportRowsTooLarge(t *testing.T) {
+	if _, err := readImportRows(strings.NewReader("a.com\nb.com\nc.com\n"), models.TextImportFormat, 2); !errors.Is(err, models.ErrImportTooLarge) {
+		t.Fatalf("got %v, want %v", err, models.ErrImportTooLarge)
+	}
+}
+
+func TestImportDomainsReport(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "existing.example", Type: models.BlacklistType, Match: models.EqualsMatch},
+	}}
+	mu := NewManageUsecase(nil, domainRepo, nil, nil, &fakeAuditRepo{}, fakeTransactor{}, nil, time.Minute, 100, 100)
+	input := strings.Join([]string{
+		"shared.example,blacklist,equals",
+		"shared.example,blacklist,suffix",
+		"shared.example,blacklist,equals",
+		"existing.example,blacklist,equals",
+		"existing.example,whitelist,equals",
+		"bad name,blacklist,equals",
+		"shared.example,undefined,equals",
+		"shared.example,whitelist,equals",
+		"existing.example,whitelist,suffix",
+	}, "\n")
+
+	report, err := mu.ImportDomains(context.Background(), strings.NewReader(input), models.CSVImportFormat.String(), "", "", false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	want := models.ImportReport{Inserted: 3, Skipped: 2, Invalid: 4}
+	if report.Inserted != want.Inserted || report.Updated != want.Updated || report.Skipped != want.Skipped || report.Invalid != want.Invalid {
+		t.Fatalf("got %+v, want %+v", report, want)
+	}
+	var lines []int
+	for _, importError := range report.Errors {
+		lines = append(lines, importError.Line)
+	}
+	if !slices.Equal(lines, []int{5, 6, 7, 8}) {
+		t.Fatalf("got errors %+v, want lines 5 to 8, the rules of another type than a live or earlier one are left out", report.Errors)
+	}
+	if len(domainRepo.rules) != 4 {
+		t.Fatalf("got %d rules, want 4: %+v", len(domainRepo.rules), domainRepo.rules)
+	}
+}
+
+func TestValidateImportRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     importRow
+		want    *models.Domain
+		wantErr bool
+	}{
+		{"defaults", importRow{name: "Example.COM"}, &models.Domain{Name: "example.com", Type: models.BlacklistType, Match: models.EqualsMatch}, false},
+		{"own type and coverage", importRow{name: "example.com", domainType: "whitelist", coverage: "suffix"}, &models.Domain{Name: "example.com", Type: models.WhitelistType, Match: models.SuffixMatch}, false},
+		{"undefined type", importRow{name: "example.com", domainType: "undefined"}, nil, true},
+		{"undefined coverage", importRow{name: "example.com", coverage: "undefined"}, nil, true},
+		{"unknown type", importRow{name: "example.com", domainType: "greylist"}, nil, true},
+		{"invalid name", importRow{name: "not a domain"}, nil, true},
+		{"expiry", importRow{name: "example.com", expiresAt: "2030-01-02T03:04:05Z"}, &models.Domain{Name: "example.com", Type: models.BlacklistType, Match: models.EqualsMatch}, false},
+		{"malformed expiry", importRow{name: "example.com", expiresAt: "tomorrow"}, nil, true},
+		{"past expiry", importRow{name: "example.com", expiresAt: "2001-01-02T03:04:05Z"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateImportRow(tt.row, models.BlacklistType.String(), models.EqualsMatch.String())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.want != nil && (got == nil || !got.Same(*tt.want)) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuleFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		domainType  string
+		domainMatch string
+		wantErr     error
+	}{
+		{"no filter", "", "", nil},
+		{"known filter", "disposable", "regex", nil},
+		{"undefined type", "undefined", "", models.ErrInvalidTypeFilter},
+		{"undefined coverage", "", "undefined", models.ErrInvalidCoverageFilter},
+		{"unknown coverage", "", "begins", models.ErrInvalidCoverageFilter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := parseRuleFilter(tt.domainType, tt.domainMatch); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExportImportKeepsExpiry(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	rules := []models.Domain{
+		{Name: "temporary.example", Type: models.BlacklistType, Match: models.EqualsMatch, ExpiresAt: &expiresAt},
+		{Name: "permanent.example", Type: models.WhitelistType, Match: models.SuffixMatch},
+	}
+	for _, format := range []models.ExportFormat{models.CSVExportFormat, models.NDJSONExportFormat} {
+		t.Run(format.String(), func(t *testing.T) {
+			source := NewManageUsecase(nil, &fakeDomainRepo{rules: rules}, nil, nil, nil, fakeTransactor{}, nil, time.Minute, 1, 1)
+			var backup bytes.Buffer
+			if err := source.ExportDomains(context.Background(), &backup, format.String(), "", ""); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+
+			target := &fakeDomainRepo{}
+			mu := NewManageUsecase(nil, target, nil, nil, &fakeAuditRepo{}, fakeTransactor{}, nil, time.Minute, 100, 100)
+			if _, err := mu.ImportDomains(context.Background(), &backup, format.String(), "", "", false); err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if len(target.rules) != len(rules) {
+				t.Fatalf("got %v, want %v", target.rules, rules)
+			}
+			for _, rule := range target.rules {
+				want := rules[slices.IndexFunc(rules, rule.Same)]
+				if (rule.ExpiresAt == nil) != (want.ExpiresAt == nil) || rule.ExpiresAt != nil && !rule.ExpiresAt.Equal(*want.ExpiresAt) {
+					t.Fatalf("%s: got expiry %v, want %v", rule.Name, rule.ExpiresAt, want.ExpiresAt)
+				}
+			}
+		})
+	}
+}
+
+func TestImportDomainsUpdatesExpiry(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "temporary.example", Type: models.BlacklistType, Match: models.EqualsMatch, ExpiresAt: &expiresAt},
+	}}
+	mu := NewManageUsecase(nil, domainRepo, nil, nil, &fakeAuditRepo{}, fakeTransactor{}, nil, time.Minute, 100, 100)
+	extended := expiresAt.Add(time.Hour).UTC().Format(time.RFC3339)
+	input := "temporary.example,blacklist,equals\ntemporary.example,blacklist,equals," + extended + "\n"
+
+	report, err := mu.ImportDomains(context.Background(), strings.NewReader(input), models.CSVImportFormat.String(), "", "", false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if report.Updated != 0 || report.Skipped != 2 {
+		t.Fatalf("got %+v, the row without an expiry keeps the rule, the repeated one is skipped", report)
+	}
+
+	report, err = mu.ImportDomains(context.Background(), strings.NewReader("temporary.example,blacklist,equals,"+extended+"\n"), models.CSVImportFormat.String(), "", "", false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if report.Updated != 1 || domainRepo.rules[0].ExpiresAt.UTC().Format(time.RFC3339) != extended {
+		t.Fatalf("got %+v, %v, want the expiry extended to %s", report, domainRepo.rules[0].ExpiresAt, extended)
+	}
+}
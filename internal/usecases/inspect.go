@@ -4,55 +4,367 @@ import (
 	"context"
 	"errors"
 	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/aerosystems/common-service/customerrors"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/publicsuffix"
+	"golang.org/x/sync/errgroup"
 	"net/mail"
 	"strings"
-	"sync"
 	"time"
 )
 
 type InspectUsecase struct {
-	log        *logrus.Logger
-	accessRepo AccessRepository
-	domainRepo DomainRepository
-	filterRepo FilterRepository
+	log            *logrus.Logger
+	accessRepo     AccessRepository
+	domainRepo     DomainRepository
+	filterRepo     FilterRepository
+	providerRepo   ProviderRepository
+	disposableRepo DisposableRepository
+	dnsResolver    DNSResolver
+	mxResolver     DNSResolver
+	lookupService  LookupService
+	verdictCache   VerdictCache
+	tieBreak       models.TieBreak
+	patternBudget  time.Duration
+
+	batchMaxSize     int
+	batchConcurrency int
+	streamQuotaLease int
 }
 
-func NewInspectUsecase(log *logrus.Logger, accessRepo AccessRepository, domainRepo DomainRepository, filterRepo FilterRepository) *InspectUsecase {
+// NewInspectUsecase takes optional resolvers, lookup service and verdict cache.
+// patternBudget bounds the evaluation of regex and glob rules per tier, zero disables the bound.
+func NewInspectUsecase(log *logrus.Logger, accessRepo AccessRepository, domainRepo DomainRepository, filterRepo FilterRepository, providerRepo ProviderRepository, disposableRepo DisposableRepository, dnsResolver, mxResolver DNSResolver, lookupService LookupService, verdictCache VerdictCache, tieBreak models.TieBreak, patternBudget time.Duration, batchMaxSize, batchConcurrency, streamQuotaLease int) *InspectUsecase {
 	return &InspectUsecase{
-		log:        log,
-		accessRepo: accessRepo,
-		domainRepo: domainRepo,
-		filterRepo: filterRepo,
+		log:              log,
+		accessRepo:       accessRepo,
+		domainRepo:       domainRepo,
+		filterRepo:       filterRepo,
+		providerRepo:     providerRepo,
+		disposableRepo:   disposableRepo,
+		dnsResolver:      dnsResolver,
+		mxResolver:       mxResolver,
+		lookupService:    lookupService,
+		verdictCache:     verdictCache,
+		tieBreak:         tieBreak,
+		patternBudget:    patternBudget,
+		batchMaxSize:     batchMaxSize,
+		batchConcurrency: batchConcurrency,
+		streamQuotaLease: max(streamQuotaLease, 1),
 	}
 }
 
-func (i *InspectUsecase) InspectData(ctx context.Context, data, _, projectToken string) (models.Type, error) {
-	res, err := i.accessRepo.Tx(ctx, projectToken, func(a *models.Access) (any, error) {
-		if err := validateAccess(a); err != nil {
-			return models.UndefinedType, err
+// InspectData reserves a unit of quota the way InspectDataBatch does, so the access row is not locked while the domain is resolved.
+func (i *InspectUsecase) InspectData(ctx context.Context, data, _, projectToken string) (*models.InspectResult, error) {
+	start := time.Now()
+	access, reserved, err := i.reserveBatch(ctx, projectToken, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := i.newInspectResult(ctx, data)
+	if err == nil {
+		err = i.inspectDomain(ctx, result, projectToken)
+	}
+	charged := 0
+	if err == nil && result.Type != models.UndefinedType {
+		access.AccessCount--
+		charged++
+	}
+	if refundErr := i.refundBatch(ctx, projectToken, reserved-charged); refundErr != nil {
+		if err == nil {
+			return nil, refundErr
 		}
+		i.log.Errorf("could not give back the quota of a failed inspection: %v", refundErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.RemainingQuota = remainingQuota(access)
+	result.Latency = time.Since(start)
+	return result, nil
+}
+
+// InspectDataBatch reserves a unit of quota per entry and commits before the entries are inspected,
+// so the access row is not locked while domains are resolved. The quota is charged in the order of entries
+// and the unused units are given back, so the outcome is the same as inspecting them one by one.
+// An entry rejected with a customer error only fails itself, any other error fails the batch.
+func (i *InspectUsecase) InspectDataBatch(ctx context.Context, data []string, _, projectToken string) ([]models.InspectItem, error) {
+	if len(data) == 0 {
+		return nil, models.ErrBatchIsEmpty
+	}
+	if len(data) > i.batchMaxSize {
+		return nil, models.ErrBatchTooLarge
+	}
+
+	start := time.Now()
+	access, reserved, err := i.reserveBatch(ctx, projectToken, len(data))
+	if err != nil {
+		return nil, err
+	}
 
-		domainName, err := extractDomainName(data)
-		if err != nil || !isValidDomain(domainName) {
-			return models.UndefinedType, models.ErrDomainNotExist
+	items := make([]models.InspectItem, len(data))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(max(i.batchConcurrency, 1))
+	for idx, entry := range data {
+		items[idx].Data = entry
+		group.Go(func() error {
+			result, err := i.newInspectResult(groupCtx, entry)
+			if err == nil {
+				err = i.inspectDomain(groupCtx, result, projectToken)
+			}
+			var entryErr customerrors.ExternalError
+			switch {
+			case err == nil:
+				items[idx].Result = result
+			case errors.As(err, &entryErr):
+				items[idx].Err = err
+			default:
+				return err
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		if refundErr := i.refundBatch(ctx, projectToken, reserved); refundErr != nil {
+			i.log.Errorf("could not give back %d units of quota of a failed batch: %v", reserved, refundErr)
 		}
+		return nil, err
+	}
 
-		domainType, err := i.getDomainType(ctx, domainName)
-		if err != nil {
-			return models.UndefinedType, err
+	charged := 0
+	for idx := range items {
+		if items[idx].Err != nil {
+			continue
 		}
+		if err := validateAccess(access); err != nil {
+			items[idx].Result, items[idx].Err = nil, err
+			continue
+		}
+		if items[idx].Result.Type != models.UndefinedType {
+			access.AccessCount--
+			charged++
+		}
+		items[idx].Result.RemainingQuota = remainingQuota(access)
+	}
+	if err := i.refundBatch(ctx, projectToken, reserved-charged); err != nil {
+		return nil, err
+	}
 
-		if domainType != models.UndefinedType {
-			a.AccessCount--
+	latency := time.Since(start)
+	for idx := range items {
+		if items[idx].Result != nil {
+			items[idx].Result.Latency = latency
 		}
-		return domainType, nil
+	}
+	return items, nil
+}
+
+// reserveBatch returns the access as it was before the reservation, a quota smaller than the batch is reserved whole.
+func (i *InspectUsecase) reserveBatch(ctx context.Context, projectToken string, size int) (*models.Access, int, error) {
+	var access models.Access
+	var reserved int
+	if _, err := i.accessRepo.Tx(ctx, projectToken, func(a *models.Access) (any, error) {
+		if err := validateAccess(a); err != nil {
+			return nil, err
+		}
+		access = *a
+		reserved = size
+		if a.SubscriptionType != models.BusinessSubscriptionType {
+			reserved = min(size, a.AccessCount)
+		}
+		a.AccessCount -= reserved
+		return nil, nil
+	}); err != nil {
+		return nil, 0, err
+	}
+	return &access, reserved, nil
+}
+
+func (i *InspectUsecase) refundBatch(ctx context.Context, projectToken string, units int) error {
+	if units <= 0 {
+		return nil
+	}
+	_, err := i.accessRepo.Tx(ctx, projectToken, func(a *models.Access) (any, error) {
+		a.AccessCount += units
+		return nil, nil
+	})
+	return err
+}
+
+// inspectDomain resolves the domain type layer by layer, the first layer with a matching rule decides:
+// the project filters override the global domain list, which overrides the disposable provider lists and the lookup service.
+func (i *InspectUsecase) inspectDomain(ctx context.Context, result *models.InspectResult, projectToken string) error {
+	return i.decide(ctx, result, func(domainName string) (*models.Verdict, error) {
+		return i.matchVerdict(ctx, domainName, projectToken)
 	})
+}
+
+// decide runs the pipeline inspect and explain share, match gives the verdict of each domain the result is matched by in turn.
+func (i *InspectUsecase) decide(ctx context.Context, result *models.InspectResult, match func(domainName string) (*models.Verdict, error)) error {
+	if err := i.normalize(ctx, result); err != nil {
+		return err
+	}
+	for _, domainName := range matchDomains(result) {
+		verdict, err := match(domainName)
+		if err != nil {
+			return err
+		}
+		if verdict.Rule != nil {
+			result.SetRule(verdict.Rule, verdict.Source)
+			return nil
+		}
+	}
+	// an undefined domain gets a suggestion whether or not the mail route is checked
+	if err := i.suggest(ctx, result); err != nil {
+		return err
+	}
+	if routeErr := i.checkMailRoute(ctx, result); routeErr != nil {
+		return withSuggestion(result, routeErr)
+	}
+	return nil
+}
+
+// matchVerdict asks the lookup service outside the verdict cache,
+// its failures leave the domain undefined and such a verdict must not be cached.
+func (i *InspectUsecase) matchVerdict(ctx context.Context, domainName, projectToken string) (*models.Verdict, error) {
+	resolve := func(ctx context.Context) (*models.Verdict, error) {
+		return i.resolveLayers(ctx, domainName, i.localRuleLayers(projectToken))
+	}
+	var verdict *models.Verdict
+	var err error
+	if i.verdictCache != nil {
+		verdict, err = i.verdictCache.Load(ctx, domainName, projectToken, resolve)
+	} else {
+		verdict, err = resolve(ctx)
+	}
+	if err != nil || verdict.Rule != nil || i.lookupService == nil {
+		return verdict, err
+	}
+	return i.resolveLayers(ctx, domainName, []ruleLayer{i.lookupLayer()})
+}
+
+func (i *InspectUsecase) resolveLayers(ctx context.Context, domainName string, layers []ruleLayer) (*models.Verdict, error) {
+	for _, layer := range layers {
+		rule, err := matchRules(ctx, domainName, i.tieBreak, layer.matchFuncs...)
+		if err != nil {
+			return nil, err
+		}
+		if rule != nil {
+			return &models.Verdict{Rule: rule, Source: layer.source}, nil
+		}
+	}
+	return &models.Verdict{Source: models.UndefinedSource}, nil
+}
+
+func (i *InspectUsecase) VerdictCacheStats(ctx context.Context) models.CacheStats {
+	if i.verdictCache == nil {
+		return models.CacheStats{}
+	}
+	return i.verdictCache.Stats(ctx)
+}
+
+func (i *InspectUsecase) suggest(ctx context.Context, result *models.InspectResult) error {
+	if result.Type == models.WhitelistType {
+		return nil
+	}
+	domainName, err := i.suggestDomain(ctx, result.Domain)
+	if err != nil || domainName == "" {
+		return err
+	}
+	result.SuggestedDomain = domainName
+	if result.Address != "" {
+		result.SuggestedAddress = result.LocalPart + "@" + domainName
+	}
+	return nil
+}
+
+func withSuggestion(result *models.InspectResult, err error) error {
+	if result.SuggestedDomain == "" {
+		return err
+	}
+	return models.SuggestionError{Err: err, SuggestedDomain: result.SuggestedDomain, SuggestedAddress: result.SuggestedAddress}
+}
+
+// normalize keeps the domain as it was given, so rules on an alias of a provider still match it.
+func (i *InspectUsecase) normalize(ctx context.Context, result *models.InspectResult) error {
+	result.CanonicalAddress = result.Address
+	result.CanonicalDomain = result.Domain
+	provider, err := i.providerRepo.FindByDomain(ctx, result.Domain)
+	if err != nil || provider == nil {
+		return err
+	}
+	localPart, domainName := provider.Normalize(result.LocalPart)
+	if result.Address != "" {
+		result.CanonicalAddress = localPart + "@" + domainName
+	}
+	result.CanonicalDomain = domainName
+	return nil
+}
+
+func matchDomains(result *models.InspectResult) []string {
+	if result.CanonicalDomain == "" || result.CanonicalDomain == result.Domain {
+		return []string{result.Domain}
+	}
+	return []string{result.Domain, result.CanonicalDomain}
+}
+
+// checkMailRoute only logs resolver failures, so a DNS outage does not fail the inspection.
+func (i *InspectUsecase) checkMailRoute(ctx context.Context, result *models.InspectResult) error {
+	if i.dnsResolver == nil {
+		return nil
+	}
+	route, err := i.dnsResolver.LookupMailRoute(ctx, result.Domain)
+	if err != nil {
+		i.log.Warnf("could not lookup mail route of %s: %v", result.Domain, err)
+		return nil
+	}
+	result.MailRoute = route
+	switch {
+	case route.Status == models.NotExistRouteStatus:
+		return models.ErrDomainNotExist
+	case !route.Status.AcceptsMail():
+		return models.ErrDomainNoMail
+	}
+	return nil
+}
+
+func (i *InspectUsecase) newInspectResult(ctx context.Context, data string) (*models.InspectResult, error) {
+	address, domainName, err := extractDomainName(data)
 	if err != nil {
-		return models.UndefinedType, err
+		return nil, models.ErrDomainNotExist
+	}
+	registrableDomain, _ := publicsuffix.EffectiveTLDPlusOne(domainName)
+	var localPart string
+	var flags []models.Flag
+	if address != "" {
+		localPart = address[:strings.LastIndex(address, "@")]
+		flags = analyzeLocalPart(localPart)
 	}
-	return res.(models.Type), nil
+	result := &models.InspectResult{
+		Address:           address,
+		LocalPart:         localPart,
+		Flags:             flags,
+		Domain:            domainName,
+		RegistrableDomain: registrableDomain,
+		Type:              models.UndefinedType,
+		Source:            models.UndefinedSource,
+	}
+	if !isValidDomain(domainName) {
+		if err := i.suggest(ctx, result); err != nil {
+			return nil, err
+		}
+		return nil, withSuggestion(result, models.ErrDomainNotExist)
+	}
+	return result, nil
+}
+
+func remainingQuota(a *models.Access) int {
+	if a.SubscriptionType == models.BusinessSubscriptionType {
+		return -1
+	}
+	return a.AccessCount
 }
 
 func validateAccess(a *models.Access) error {
@@ -65,16 +377,26 @@ func validateAccess(a *models.Access) error {
 	return nil
 }
 
-func extractDomainName(data string) (string, error) {
+// extractDomainName keeps the local part in Unicode (SMTPUTF8).
+func extractDomainName(data string) (string, string, error) {
 	data = strings.ToLower(data)
 	if strings.Contains(data, "@") {
 		email, err := mail.ParseAddress(data)
 		if err != nil {
-			return "", err
+			return "", "", err
+		}
+		idx := strings.LastIndex(email.Address, "@")
+		domainName, err := models.DomainToASCII(email.Address[idx+1:])
+		if err != nil {
+			return "", "", err
 		}
-		return strings.Split(email.Address, "@")[1], nil
+		return email.Address[:idx+1] + domainName, domainName, nil
 	}
-	return data, nil
+	domainName, err := models.DomainToASCII(data)
+	if err != nil {
+		return "", "", err
+	}
+	return "", domainName, nil
 }
 
 func isValidDomain(domainName string) bool {
@@ -85,39 +407,135 @@ func isValidDomain(domainName string) bool {
 	return icann || strings.Contains(eTLD, ".")
 }
 
-func (i *InspectUsecase) getDomainType(ctx context.Context, domainName string) (models.Type, error) {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	var wg sync.WaitGroup
-	resChan := make(chan models.Type, 1)
-	errChan := make(chan error, 1)
-	matchFuncs := []func(ctx context.Context, name string) (*models.Domain, error){
-		i.domainRepo.MatchEquals,
-		i.domainRepo.MatchPrefix,
-		i.domainRepo.MatchSuffix,
-		i.domainRepo.MatchContains,
-	}
-
-	for _, f := range matchFuncs {
-		wg.Add(1)
-		go func(matchFunc func(ctx context.Context, name string) (*models.Domain, error)) {
-			defer wg.Done()
-			if domain, err := matchFunc(ctx, domainName); err == nil && domain != nil {
-				select {
-				case resChan <- domain.Type:
-					cancel()
-				default:
-				}
+// ruleLayer is a set of rules with a common source, its match functions are ordered by precedence:
+// equals, suffix, registrable, prefix, contains, glob, regex.
+type ruleLayer struct {
+	source     models.Source
+	matchFuncs []matchFunc
+}
+
+func (i *InspectUsecase) ruleLayers(projectToken string) []ruleLayer {
+	layers := i.localRuleLayers(projectToken)
+	if i.lookupService != nil {
+		layers = append(layers, i.lookupLayer())
+	}
+	return layers
+}
+
+func (i *InspectUsecase) localRuleLayers(projectToken string) []ruleLayer {
+	layers := make([]ruleLayer, 0, 5)
+	if projectToken != "" {
+		layers = append(layers, ruleLayer{source: models.ProjectSource, matchFuncs: i.filterMatchFuncs(projectToken)})
+	}
+	layers = append(layers, ruleLayer{source: models.GlobalSource, matchFuncs: i.domainMatchFuncs()})
+	if i.disposableRepo != nil {
+		layers = append(layers, ruleLayer{source: models.DisposableSource, matchFuncs: []matchFunc{i.disposableRepo.MatchDomain}})
+		if i.mxResolver != nil {
+			layers = append(layers, ruleLayer{source: models.DisposableMXSource, matchFuncs: []matchFunc{i.matchDisposableMX}})
+		}
+	}
+	return layers
+}
+
+func (i *InspectUsecase) lookupLayer() ruleLayer {
+	return ruleLayer{source: models.LookupSource, matchFuncs: []matchFunc{i.matchLookup}}
+}
+
+func (i *InspectUsecase) domainMatchFuncs() []matchFunc {
+	return []matchFunc{
+		unexpired(i.domainRepo.MatchEquals),
+		unexpired(i.domainRepo.MatchSuffix),
+		unexpired(i.domainRepo.MatchRegistrable),
+		unexpired(i.domainRepo.MatchPrefix),
+		unexpired(i.domainRepo.MatchContains),
+		unexpired(i.budgeted(i.domainRepo.MatchGlob)),
+		unexpired(i.budgeted(i.domainRepo.MatchRegex)),
+	}
+}
+
+func (i *InspectUsecase) filterMatchFuncs(projectToken string) []matchFunc {
+	filterMatchFunc := func(f func(ctx context.Context, domainName, projectToken string) ([]models.Filter, error)) matchFunc {
+		return unexpired(func(ctx context.Context, domainName string) ([]models.Domain, error) {
+			filters, err := f(ctx, domainName, projectToken)
+			if err != nil {
+				return nil, err
+			}
+			return filtersToDomains(filters), nil
+		})
+	}
+	return []matchFunc{
+		filterMatchFunc(i.filterRepo.MatchEquals),
+		filterMatchFunc(i.filterRepo.MatchSuffix),
+		filterMatchFunc(i.filterRepo.MatchRegistrable),
+		filterMatchFunc(i.filterRepo.MatchPrefix),
+		filterMatchFunc(i.filterRepo.MatchContains),
+		i.budgeted(filterMatchFunc(i.filterRepo.MatchGlob)),
+		i.budgeted(filterMatchFunc(i.filterRepo.MatchRegex)),
+	}
+}
+
+// unexpired leaves out the rules that expired but are not archived by the sweeper yet.
+func unexpired(f matchFunc) matchFunc {
+	return func(ctx context.Context, domainName string) ([]models.Domain, error) {
+		rules, err := f(ctx, domainName)
+		now := time.Now()
+		active := make([]models.Domain, 0, len(rules))
+		for _, rule := range rules {
+			if !rule.Expired(now) {
+				active = append(active, rule)
 			}
-		}(f)
+		}
+		return active, err
 	}
+}
 
-	go func() {
-		wg.Wait()
-		close(resChan)
-		close(errChan)
-	}()
+// budgeted degrades a slow pattern tier to the rules matched so far instead of failing the request.
+func (i *InspectUsecase) budgeted(f matchFunc) matchFunc {
+	if i.patternBudget <= 0 {
+		return f
+	}
+	return func(ctx context.Context, domainName string) ([]models.Domain, error) {
+		budgetCtx, cancel := context.WithTimeout(ctx, i.patternBudget)
+		defer cancel()
+		rules, err := f(budgetCtx, domainName)
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			i.log.Warnf("pattern rules for %s exceeded the evaluation budget of %s, using %d partial matches", domainName, i.patternBudget, len(rules))
+			return rules, nil
+		}
+		return rules, err
+	}
+}
 
-	return <-resChan, nil
+func (i *InspectUsecase) matchDisposableMX(ctx context.Context, domainName string) ([]models.Domain, error) {
+	route, err := i.mxResolver.LookupMailRoute(ctx, domainName)
+	if err != nil {
+		i.log.Warnf("could not lookup MX records of %s: %v", domainName, err)
+		return nil, nil
+	}
+	var rules []models.Domain
+	for _, host := range route.MXHosts {
+		matched, err := i.disposableRepo.MatchMXHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, matched...)
+	}
+	return rules, nil
+}
+
+// matchLookup only logs failures of the lookup service, it is advisory and the domain is left undefined.
+func (i *InspectUsecase) matchLookup(ctx context.Context, domainName string) ([]models.Domain, error) {
+	domainType, err := i.lookupService.Lookup(ctx, domainName)
+	if err != nil {
+		i.log.Warnf("could not lookup domain %s: %v", domainName, err)
+		return nil, nil
+	}
+	if domainType == models.UndefinedType {
+		return nil, nil
+	}
+	return []models.Domain{{
+		Name:  domainName,
+		Type:  domainType,
+		Match: models.EqualsMatch,
+	}}, nil
 }
@@ -0,0 +1,16 @@
+package usecases
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+)
+
+// DecomposeData accepts public suffixes as input, so no domain validation is done.
+func (i *InspectUsecase) DecomposeData(_ context.Context, data string) (*models.Decomposition, error) {
+	_, domainName, err := extractDomainName(data)
+	if err != nil || domainName == "" {
+		return nil, models.ErrDomainNotValid
+	}
+	decomposition := models.DecomposeDomain(domainName)
+	return &decomposition, nil
+}
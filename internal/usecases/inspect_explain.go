@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"time"
+)
+
+// ExplainData only reads the access, so the quota is neither checked nor charged.
+// It decides like inspect, the domains after the one a rule decides are not explained.
+func (i *InspectUsecase) ExplainData(ctx context.Context, data, projectToken string) (*models.Explanation, error) {
+	start := time.Now()
+	result, err := i.newInspectResult(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	result.RemainingQuota = -1
+	if projectToken != "" {
+		access, err := i.accessRepo.Get(ctx, projectToken)
+		if err != nil {
+			if errors.Is(err, models.ErrApiKeyNotFound) {
+				return nil, models.ErrProjectNotFound
+			}
+			return nil, err
+		}
+		result.RemainingQuota = remainingQuota(access)
+	}
+
+	var tiers [][]models.Domain
+	var sources []models.Source
+	err = i.decide(ctx, result, func(domainName string) (*models.Verdict, error) {
+		var domainTiers [][]models.Domain
+		var domainSources []models.Source
+		for _, layer := range i.ruleLayers(projectToken) {
+			layerTiers, err := collectRules(ctx, domainName, layer.matchFuncs...)
+			if err != nil {
+				return nil, err
+			}
+			domainTiers = append(domainTiers, layerTiers...)
+			for range layerTiers {
+				domainSources = append(domainSources, layer.source)
+			}
+		}
+		tiers = append(tiers, domainTiers...)
+		sources = append(sources, domainSources...)
+		if match := selectedMatch(explainTiers(domainTiers, domainSources, i.tieBreak).Matches); match != nil {
+			return &models.Verdict{Rule: &match.Rule, Source: match.Source}, nil
+		}
+		return &models.Verdict{Source: models.UndefinedSource}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	explanation := explainTiers(tiers, sources, i.tieBreak)
+	result.Latency = time.Since(start)
+	explanation.Result = result
+	return explanation, nil
+}
+
+func explainTiers(tiers [][]models.Domain, sources []models.Source, tieBreak models.TieBreak) *models.Explanation {
+	explanation := &models.Explanation{
+		Matches:  make([]models.RuleMatch, 0),
+		Decision: models.NoMatchDecision,
+		TieBreak: tieBreak,
+	}
+	decided := false
+	for idx, rules := range tiers {
+		sorted := sortRules(rules)
+		var selected *models.Domain
+		if !decided {
+			selected = resolveRules(sorted, tieBreak)
+		}
+		for _, rule := range sorted {
+			isSelected := selected != nil && rule == *selected
+			explanation.Matches = append(explanation.Matches, models.RuleMatch{
+				Rule:     rule,
+				Source:   sources[idx],
+				Selected: isSelected,
+			})
+		}
+		if selected == nil {
+			continue
+		}
+		decided = true
+		switch longest := longestRules(sorted); {
+		case hasTypeConflict(longest):
+			explanation.Decision = models.TieBreakDecision
+		case len(sorted) > 1:
+			explanation.Decision = models.LongestMatchDecision
+		default:
+			explanation.Decision = models.SingleMatchDecision
+		}
+	}
+	if explanation.Decision == models.SingleMatchDecision && len(explanation.Matches) > 1 {
+		explanation.Decision = models.PrecedenceDecision
+	}
+	return explanation
+}
+
+func selectedMatch(matches []models.RuleMatch) *models.RuleMatch {
+	for idx := range matches {
+		if matches[idx].Selected {
+			return &matches[idx]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"testing"
+)
+
+func TestExplainTiers(t *testing.T) {
+	tests := []struct {
+		name         string
+		tiers        [][]models.Domain
+		tieBreak     models.TieBreak
+		wantDecision models.Decision
+		wantSelected *models.Domain
+		wantMatches  int
+	}{
+		{
+			name:         "no match",
+			tiers:        [][]models.Domain{nil, {}},
+			tieBreak:     models.BlacklistTieBreak,
+			wantDecision: models.NoMatchDecision,
+		},
+		{
+			name:         "single match",
+			tiers:        [][]models.Domain{{rule("mail.com", models.BlacklistType, models.EqualsMatch)}},
+			tieBreak:     models.BlacklistTieBreak,
+			wantDecision: models.SingleMatchDecision,
+			wantSelected: &models.Domain{Name: "mail.com", Type: models.BlacklistType, Match: models.EqualsMatch},
+			wantMatches:  1,
+		},
+		{
+			name: "precedence over a weaker tier",
+			tiers: [][]models.Domain{
+				{rule("mail.com", models.WhitelistType, models.EqualsMatch)},
+				{rule("mail.com", models.BlacklistType, models.SuffixMatch)},
+			},
+			tieBreak:     models.BlacklistTieBreak,
+			wantDecision: models.PrecedenceDecision,
+			wantSelected: &models.Domain{Name: "mail.com", Type: models.WhitelistType, Match: models.EqualsMatch},
+			wantMatches:  2,
+		},
+		{
+			name: "longest match",
+			tiers: [][]models.Domain{{
+				rule("mail.com", models.BlacklistType, models.SuffixMatch),
+				rule("eu.mail.com", models.WhitelistType, models.SuffixMatch),
+			}},
+			tieBreak:     models.BlacklistTieBreak,
+			wantDecision: models.LongestMatchDecision,
+			wantSelected: &models.Domain{Name: "eu.mail.com", Type: models.WhitelistType, Match: models.SuffixMatch},
+			wantMatches:  2,
+		},
+		{
+			name: "tie break",
+			tiers: [][]models.Domain{{
+				rule("mail.com", models.BlacklistType, models.SuffixMatch),
+				rule("mail.com", models.WhitelistType, models.SuffixMatch),
+			}},
+			tieBreak:     models.WhitelistTieBreak,
+			wantDecision: models.TieBreakDecision,
+			wantSelected: &models.Domain{Name: "mail.com", Type: models.WhitelistType, Match: models.SuffixMatch},
+			wantMatches:  2,
+		},
+		{
+			name: "tie break in the deciding tier only",
+			tiers: [][]models.Domain{
+				{},
+				{rule("mail.com", models.DisposableType, models.SuffixMatch)},
+				{rule("mail.com", models.BlacklistType, models.ContainsMatch), rule("mail.com", models.WhitelistType, models.ContainsMatch)},
+			},
+			tieBreak:     models.BlacklistTieBreak,
+			wantDecision: models.PrecedenceDecision,
+			wantSelected: &models.Domain{Name: "mail.com", Type: models.DisposableType, Match: models.SuffixMatch},
+			wantMatches:  3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources := make([]models.Source, len(tt.tiers))
+			for idx := range sources {
+				sources[idx] = models.GlobalSource
+			}
+			explanation := explainTiers(tt.tiers, sources, tt.tieBreak)
+			if explanation.Decision != tt.wantDecision {
+				t.Fatalf("got decision %s, want %s", explanation.Decision, tt.wantDecision)
+			}
+			if len(explanation.Matches) != tt.wantMatches {
+				t.Fatalf("got %d matches, want %d: %+v", len(explanation.Matches), tt.wantMatches, explanation.Matches)
+			}
+			selected := selectedMatch(explanation.Matches)
+			switch {
+			case tt.wantSelected == nil && selected != nil:
+				t.Fatalf("got %+v selected, want none", selected.Rule)
+			case tt.wantSelected != nil && (selected == nil || selected.Rule != *tt.wantSelected):
+				t.Fatalf("got %+v selected, want %+v", selected, tt.wantSelected)
+			}
+			if explanation.TieBreak != tt.tieBreak {
+				t.Fatalf("got tie-break %s, want %s", explanation.TieBreak, tt.tieBreak)
+			}
+		})
+	}
+}
+
+func TestExplainDataReportsEveryLayer(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "inbox.mailinator.com", Type: models.WhitelistType, Match: models.EqualsMatch},
+		{Name: "mailinator.com", Type: models.BlacklistType, Match: models.SuffixMatch},
+	}}
+	usecase := newTestInspectUsecase(domainRepo, nil)
+	usecase.disposableRepo = fakeDisposableRepo{domains: []string{"mailinator.com"}}
+
+	explanation, err := usecase.ExplainData(context.Background(), "user@inbox.mailinator.com", "")
+	if err != nil {
+		t.Fatalf("ExplainData: %v", err)
+	}
+	if len(explanation.Matches) != 3 || explanation.Decision != models.PrecedenceDecision {
+		t.Fatalf("got %s with %+v, want precedence over 3 matches", explanation.Decision, explanation.Matches)
+	}
+	if last := explanation.Matches[2]; last.Source != models.DisposableSource || last.Selected {
+		t.Fatalf("got %+v, want the disposable list reported last and not selected", last)
+	}
+	if result := explanation.Result; result.Type != models.WhitelistType || result.Source != models.GlobalSource || result.RemainingQuota != -1 {
+		t.Fatalf("got %+v, want the whitelist rule without a quota", result)
+	}
+}
+
+func TestExplainDataDecidesLikeInspect(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "gmali.com", Type: models.BlacklistType, Match: models.EqualsMatch},
+	}}
+	dnsResolver := fakeDNSResolver{
+		"gmail.com": models.MXRouteStatus,
+		"gmial.com": models.NotExistRouteStatus,
+	}
+	usecase := newTestInspectUsecase(domainRepo, dnsResolver)
+
+	for _, data := range []string{"user@gmali.com", "user@gmial.com", "user@gmail.com"} {
+		t.Run(data, func(t *testing.T) {
+			inspected, inspectErr := usecase.InspectData(context.Background(), data, "", "")
+			explanation, explainErr := usecase.ExplainData(context.Background(), data, "")
+			if !errors.Is(explainErr, inspectErr) {
+				t.Fatalf("got %v, inspect answers %v", explainErr, inspectErr)
+			}
+			if inspectErr != nil {
+				return
+			}
+			explained := explanation.Result
+			if explained.Type != inspected.Type || explained.Source != inspected.Source ||
+				explained.SuggestedDomain != inspected.SuggestedDomain || explained.SuggestedAddress != inspected.SuggestedAddress ||
+				(explained.MailRoute == nil) != (inspected.MailRoute == nil) {
+				t.Fatalf("got %+v, inspect answers %+v", explained, inspected)
+			}
+		})
+	}
+}
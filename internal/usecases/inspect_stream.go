@@ -0,0 +1,72 @@
+package usecases
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"time"
+)
+
+// OpenStream takes quota from the Access row in chunks of streamQuotaLease,
+// so a stream costs one transaction per lease instead of one per entry.
+func (i *InspectUsecase) OpenStream(ctx context.Context, projectToken string) (*models.QuotaLease, error) {
+	lease := models.NewQuotaLease(projectToken)
+	refill, err := i.refillLease(ctx, lease)
+	if err != nil {
+		return nil, err
+	}
+	lease.Extend(refill)
+	return lease, nil
+}
+
+// InspectStreamData is safe for concurrent use. A unit of quota is reserved before the domain is resolved
+// and given back when the verdict is undefined, same as for a single inspection.
+func (i *InspectUsecase) InspectStreamData(ctx context.Context, lease *models.QuotaLease, data string) (*models.InspectResult, error) {
+	start := time.Now()
+	result, err := i.newInspectResult(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lease.Take(func() (models.QuotaRefill, error) { return i.refillLease(ctx, lease) }); err != nil {
+		return nil, err
+	}
+	if err := i.inspectDomain(ctx, result, lease.ProjectToken); err != nil {
+		lease.Put()
+		return nil, err
+	}
+	if result.Type == models.UndefinedType {
+		lease.Put()
+	}
+	result.RemainingQuota = lease.Remaining()
+	result.Latency = time.Since(start)
+	return result, nil
+}
+
+// CloseStream gives the unused quota back. It has to be called with a context that outlives the stream.
+func (i *InspectUsecase) CloseStream(ctx context.Context, lease *models.QuotaLease) error {
+	return lease.Drain(func(units int) error {
+		_, err := i.accessRepo.Tx(ctx, lease.ProjectToken, func(a *models.Access) (any, error) {
+			a.AccessCount += units
+			return nil, nil
+		})
+		return err
+	})
+}
+
+func (i *InspectUsecase) refillLease(ctx context.Context, lease *models.QuotaLease) (models.QuotaRefill, error) {
+	res, err := i.accessRepo.Tx(ctx, lease.ProjectToken, func(a *models.Access) (any, error) {
+		if err := validateAccess(a); err != nil {
+			return nil, err
+		}
+		units := i.streamQuotaLease
+		if a.SubscriptionType != models.BusinessSubscriptionType {
+			units = min(units, a.AccessCount)
+		}
+		a.AccessCount -= units
+		return models.QuotaRefill{Units: units, Stored: remainingQuota(a)}, nil
+	})
+	if err != nil {
+		return models.QuotaRefill{}, err
+	}
+	return res.(models.QuotaRefill), nil
+}
@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"golang.org/x/sync/errgroup"
+	"testing"
+	"time"
+)
+
+// fakeFilterRepo has no filters, so the global rules decide.
+type fakeFilterRepo struct {
+	FilterRepository
+}
+
+func (fakeFilterRepo) MatchEquals(context.Context, string, string) ([]models.Filter, error) {
+	return nil, nil
+}
+
+func (fakeFilterRepo) MatchSuffix(context.Context, string, string) ([]models.Filter, error) {
+	return nil, nil
+}
+
+func (fakeFilterRepo) MatchRegistrable(context.Context, string, string) ([]models.Filter, error) {
+	return nil, nil
+}
+
+func (fakeFilterRepo) MatchPrefix(context.Context, string, string) ([]models.Filter, error) {
+	return nil, nil
+}
+
+func (fakeFilterRepo) MatchContains(context.Context, string, string) ([]models.Filter, error) {
+	return nil, nil
+}
+
+func (fakeFilterRepo) MatchGlob(context.Context, string, string) ([]models.Filter, error) {
+	return nil, nil
+}
+
+func (fakeFilterRepo) MatchRegex(context.Context, string, string) ([]models.Filter, error) {
+	return nil, nil
+}
+
+func newTestStreamUsecase(accessCount, lease int) (*InspectUsecase, *fakeAccessRepo) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "spam.com", Type: models.BlacklistType, Match: models.SuffixMatch},
+	}}
+	usecase := newTestInspectUsecase(domainRepo, nil)
+	accessRepo := &fakeAccessRepo{access: models.Access{
+		SubscriptionType: models.StartupSubscriptionType,
+		AccessTime:       time.Now().Add(time.Hour),
+		AccessCount:      accessCount,
+	}}
+	usecase.accessRepo = accessRepo
+	usecase.filterRepo = fakeFilterRepo{}
+	usecase.streamQuotaLease = lease
+	return usecase, accessRepo
+}
+
+func TestInspectStreamQuotaLease(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []string
+		wantCharged   int
+		wantRemaining int
+	}{
+		{"nothing inspected", nil, 0, 10},
+		{"within the first lease", []string{"a@spam.com", "b@spam.com"}, 2, 8},
+		{"across leases", []string{"a@spam.com", "b@spam.com", "c@spam.com", "d@spam.com", "e@spam.com"}, 5, 5},
+		{"undefined verdicts are given back", []string{"a@spam.com", "b@example.com", "c@example.com", "d@example.com"}, 1, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			usecase, accessRepo := newTestStreamUsecase(10, 3)
+			lease, err := usecase.OpenStream(ctx, "token")
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			lastRemaining := 0
+			if accessRepo.access.AccessCount != 7 {
+				t.Fatalf("got %d left after opening, want a lease of 3 reserved", accessRepo.access.AccessCount)
+			}
+			for _, data := range tt.data {
+				result, err := usecase.InspectStreamData(ctx, lease, data)
+				if err != nil {
+					t.Fatalf("%s: %v", data, err)
+				}
+				lastRemaining = result.RemainingQuota
+			}
+			if len(tt.data) > 0 && lastRemaining != tt.wantRemaining {
+				t.Fatalf("got %d remaining reported, want %d, the units left in the lease count as remaining", lastRemaining, tt.wantRemaining)
+			}
+			if err := usecase.CloseStream(ctx, lease); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+			if accessRepo.access.AccessCount != tt.wantRemaining {
+				t.Fatalf("got %d left, want %d after charging %d", accessRepo.access.AccessCount, tt.wantRemaining, tt.wantCharged)
+			}
+			if err := usecase.CloseStream(ctx, lease); err != nil || accessRepo.access.AccessCount != tt.wantRemaining {
+				t.Fatalf("got %d left, %v, closing twice must not give the quota back twice", accessRepo.access.AccessCount, err)
+			}
+		})
+	}
+}
+
+func TestInspectStreamQuotaExhausted(t *testing.T) {
+	ctx := context.Background()
+	usecase, accessRepo := newTestStreamUsecase(2, 3)
+	lease, err := usecase.OpenStream(ctx, "token")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, data := range []string{"a@spam.com", "b@spam.com"} {
+		if _, err := usecase.InspectStreamData(ctx, lease, data); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+	}
+	if _, err := usecase.InspectStreamData(ctx, lease, "c@spam.com"); err == nil {
+		t.Fatal("got no error with the quota used up")
+	}
+	if err := usecase.CloseStream(ctx, lease); err != nil || accessRepo.access.AccessCount != 0 {
+		t.Fatalf("got %d left, %v, want the whole quota charged", accessRepo.access.AccessCount, err)
+	}
+}
+
+func TestInspectStreamConcurrentEntries(t *testing.T) {
+	ctx := context.Background()
+	usecase, accessRepo := newTestStreamUsecase(100, 7)
+	lease, err := usecase.OpenStream(ctx, "token")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	var group errgroup.Group
+	for idx := range 40 {
+		group.Go(func() error {
+			domainName := "spam.com"
+			if idx%4 == 0 {
+				domainName = "example.com"
+			}
+			_, err := usecase.InspectStreamData(ctx, lease, fmt.Sprintf("user%d@%s", idx, domainName))
+			return err
+		})
+	}
+	if err := group.Wait(); err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if err := usecase.CloseStream(ctx, lease); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if accessRepo.access.AccessCount != 70 {
+		t.Fatalf("got %d left, want 30 of 100 charged", accessRepo.access.AccessCount)
+	}
+}
@@ -0,0 +1,420 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/sirupsen/logrus"
+	"io"
+	"testing"
+	"time"
+)
+
+type fakeAccessRepo struct {
+	AccessRepository
+	access models.Access
+	inTx   bool
+}
+
+func (r *fakeAccessRepo) Tx(_ context.Context, _ string, fn func(a *models.Access) (any, error)) (any, error) {
+	r.inTx = true
+	defer func() { r.inTx = false }()
+	return fn(&r.access)
+}
+
+type fakeDomainRepo struct {
+	DomainRepository
+	rules []models.Domain
+	err   error
+}
+
+func (r *fakeDomainRepo) FindSuggestionCandidates(context.Context) ([]string, error) {
+	var names []string
+	for _, rule := range r.rules {
+		if rule.SuggestionCandidate(time.Now()) {
+			names = append(names, rule.Name)
+		}
+	}
+	return names, nil
+}
+
+func (r *fakeDomainRepo) match(match models.Match, name string) ([]models.Domain, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var rules []models.Domain
+	for _, rule := range r.rules {
+		if rule.Match == match && rule.Matcher()(name) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (r *fakeDomainRepo) MatchEquals(_ context.Context, name string) ([]models.Domain, error) {
+	return r.match(models.EqualsMatch, name)
+}
+
+func (r *fakeDomainRepo) MatchPrefix(_ context.Context, name string) ([]models.Domain, error) {
+	return r.match(models.PrefixMatch, name)
+}
+
+func (r *fakeDomainRepo) MatchSuffix(_ context.Context, name string) ([]models.Domain, error) {
+	return r.match(models.SuffixMatch, name)
+}
+
+func (r *fakeDomainRepo) MatchRegistrable(_ context.Context, name string) ([]models.Domain, error) {
+	return r.match(models.RegistrableMatch, name)
+}
+
+func (r *fakeDomainRepo) MatchContains(_ context.Context, name string) ([]models.Domain, error) {
+	return r.match(models.ContainsMatch, name)
+}
+
+func (r *fakeDomainRepo) MatchGlob(_ context.Context, name string) ([]models.Domain, error) {
+	return r.match(models.GlobMatch, name)
+}
+
+func (r *fakeDomainRepo) MatchRegex(_ context.Context, name string) ([]models.Domain, error) {
+	return r.match(models.RegexMatch, name)
+}
+
+type fakeProviderRepo struct {
+	ProviderRepository
+	providers []models.Provider
+}
+
+func (r *fakeProviderRepo) FindByDomain(_ context.Context, name string) (*models.Provider, error) {
+	for idx := range r.providers {
+		if r.providers[idx].Domain == name {
+			return &r.providers[idx], nil
+		}
+	}
+	return nil, nil
+}
+
+type fakeDNSResolver map[string]models.RouteStatus
+
+func (r fakeDNSResolver) LookupMailRoute(_ context.Context, name string) (*models.MailRoute, error) {
+	status, ok := r[name]
+	if !ok {
+		return nil, errors.New("no answer")
+	}
+	return &models.MailRoute{Status: status}, nil
+}
+
+func newTestInspectUsecase(domainRepo *fakeDomainRepo, dnsResolver DNSResolver) *InspectUsecase {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	accessRepo := &fakeAccessRepo{access: models.Access{
+		SubscriptionType: models.BusinessSubscriptionType,
+		AccessTime:       time.Now().Add(time.Hour),
+	}}
+	return NewInspectUsecase(log, accessRepo, domainRepo, nil, &fakeProviderRepo{}, nil, dnsResolver, nil, nil, nil, models.BlacklistTieBreak, 0, 10, 4, 1)
+}
+
+func TestInspectDataBatchEntryErrors(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "spam.com", Type: models.BlacklistType, Match: models.EqualsMatch},
+	}}
+	dnsResolver := fakeDNSResolver{
+		"example.com": models.MXRouteStatus,
+		"gone.com":    models.NotExistRouteStatus,
+		"nomail.com":  models.NullMXRouteStatus,
+	}
+	usecase := newTestInspectUsecase(domainRepo, dnsResolver)
+
+	data := []string{"a@example.com", "b@gone.com", "not an address", "c@nomail.com", "d@spam.com", "e@timeout.com"}
+	items, err := usecase.InspectDataBatch(context.Background(), data, "", "")
+	if err != nil {
+		t.Fatalf("InspectDataBatch: %v", err)
+	}
+	wantErrs := []error{nil, models.ErrDomainNotExist, models.ErrDomainNotExist, models.ErrDomainNoMail, nil, nil}
+	for idx, item := range items {
+		if item.Data != data[idx] {
+			t.Errorf("item %d is %q, want %q", idx, item.Data, data[idx])
+		}
+		if !errors.Is(item.Err, wantErrs[idx]) {
+			t.Errorf("%s: got error %v, want %v", item.Data, item.Err, wantErrs[idx])
+		}
+		if (item.Err == nil) != (item.Result != nil) {
+			t.Errorf("%s: got result %+v with error %v", item.Data, item.Result, item.Err)
+		}
+	}
+	if got := items[4].Result.Type; got != models.BlacklistType {
+		t.Errorf("d@spam.com: got type %s, want %s", got, models.BlacklistType)
+	}
+}
+
+func TestInspectDataBatchInfrastructureError(t *testing.T) {
+	repoErr := errors.New("connection refused")
+	usecase := newTestInspectUsecase(&fakeDomainRepo{err: repoErr}, nil)
+
+	_, err := usecase.InspectDataBatch(context.Background(), []string{"a@example.com", "b@example.org"}, "", "")
+	if !errors.Is(err, repoErr) {
+		t.Fatalf("got error %v, want %v", err, repoErr)
+	}
+}
+
+func TestInspectDataBatchQuota(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "spam.com", Type: models.BlacklistType, Match: models.SuffixMatch},
+	}}
+	usecase := newTestInspectUsecase(domainRepo, nil)
+	accessRepo := &fakeAccessRepo{access: models.Access{
+		SubscriptionType: models.StartupSubscriptionType,
+		AccessTime:       time.Now().Add(time.Hour),
+		AccessCount:      3,
+	}}
+	usecase.accessRepo = accessRepo
+
+	data := []string{"a@spam.com", "b@example.com", "c@spam.com", "d@example.com", "e@spam.com", "f@spam.com", "g@example.com"}
+	items, err := usecase.InspectDataBatch(context.Background(), data, "", "")
+	if err != nil {
+		t.Fatalf("InspectDataBatch: %v", err)
+	}
+	wantQuota := []int{2, 2, 1, 1, 0}
+	for idx, want := range wantQuota {
+		if items[idx].Err != nil || items[idx].Result.RemainingQuota != want {
+			t.Errorf("%s: got %+v, %v, want %d left", items[idx].Data, items[idx].Result, items[idx].Err, want)
+		}
+	}
+	for _, item := range items[len(wantQuota):] {
+		if item.Err == nil {
+			t.Errorf("%s: got no error with the quota used up", item.Data)
+		}
+	}
+	if accessRepo.access.AccessCount != 0 {
+		t.Fatalf("got %d left, want the whole quota charged", accessRepo.access.AccessCount)
+	}
+
+	accessRepo.access.AccessCount = 10
+	if _, err := usecase.InspectDataBatch(context.Background(), []string{"a@spam.com", "b@example.com"}, "", ""); err != nil {
+		t.Fatalf("InspectDataBatch: %v", err)
+	}
+	if accessRepo.access.AccessCount != 9 {
+		t.Fatalf("got %d left, want the unused reservation given back", accessRepo.access.AccessCount)
+	}
+
+	domainRepo.err = errors.New("connection refused")
+	if _, err := usecase.InspectDataBatch(context.Background(), []string{"a@spam.com", "b@example.com"}, "", ""); err == nil {
+		t.Fatal("got no error")
+	}
+	if accessRepo.access.AccessCount != 9 {
+		t.Fatalf("got %d left, a failed batch must give the whole reservation back", accessRepo.access.AccessCount)
+	}
+}
+
+// txCheckingResolver fails a lookup made while the access row is locked.
+type txCheckingResolver struct {
+	fakeDNSResolver
+	accessRepo *fakeAccessRepo
+}
+
+func (r txCheckingResolver) LookupMailRoute(ctx context.Context, name string) (*models.MailRoute, error) {
+	if r.accessRepo.inTx {
+		return nil, errors.New("looked up inside the access transaction")
+	}
+	return r.fakeDNSResolver.LookupMailRoute(ctx, name)
+}
+
+func TestInspectDataQuota(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "spam.com", Type: models.BlacklistType, Match: models.SuffixMatch},
+	}}
+	accessRepo := &fakeAccessRepo{access: models.Access{
+		SubscriptionType: models.StartupSubscriptionType,
+		AccessTime:       time.Now().Add(time.Hour),
+		AccessCount:      2,
+	}}
+	usecase := newTestInspectUsecase(domainRepo, txCheckingResolver{fakeDNSResolver{"example.com": models.MXRouteStatus}, accessRepo})
+	usecase.accessRepo = accessRepo
+
+	result, err := usecase.InspectData(context.Background(), "a@example.com", "", "")
+	if err != nil {
+		t.Fatalf("InspectData: %v", err)
+	}
+	if result.RemainingQuota != 2 || accessRepo.access.AccessCount != 2 {
+		t.Fatalf("got %d reported and %d left, an undefined domain must not be charged", result.RemainingQuota, accessRepo.access.AccessCount)
+	}
+	result, err = usecase.InspectData(context.Background(), "b@spam.com", "", "")
+	if err != nil {
+		t.Fatalf("InspectData: %v", err)
+	}
+	if result.RemainingQuota != 1 || accessRepo.access.AccessCount != 1 {
+		t.Fatalf("got %d reported and %d left, want 1", result.RemainingQuota, accessRepo.access.AccessCount)
+	}
+
+	domainRepo.err = errors.New("connection refused")
+	if _, err := usecase.InspectData(context.Background(), "c@spam.com", "", ""); err == nil {
+		t.Fatal("got no error")
+	}
+	if accessRepo.access.AccessCount != 1 {
+		t.Fatalf("got %d left, a failed inspection must give its reservation back", accessRepo.access.AccessCount)
+	}
+}
+
+func TestInspectDataSuggestsForRejectedDomains(t *testing.T) {
+	dnsResolver := fakeDNSResolver{"gmial.com": models.NotExistRouteStatus}
+	usecase := newTestInspectUsecase(&fakeDomainRepo{}, dnsResolver)
+
+	tests := []struct {
+		data        string
+		wantErr     error
+		wantDomain  string
+		wantAddress string
+	}{
+		{"user@hotmail.con", models.ErrDomainNotExist, "hotmail.com", "user@hotmail.com"},
+		{"user@yahoo.comm", models.ErrDomainNotExist, "yahoo.com", "user@yahoo.com"},
+		{"gmial.com", models.ErrDomainNotExist, "gmail.com", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			_, err := usecase.InspectData(context.Background(), tt.data, "", "")
+			var suggestionErr models.SuggestionError
+			if !errors.Is(err, tt.wantErr) || !errors.As(err, &suggestionErr) {
+				t.Fatalf("got error %v, want %v with a suggestion", err, tt.wantErr)
+			}
+			if suggestionErr.SuggestedDomain != tt.wantDomain || suggestionErr.SuggestedAddress != tt.wantAddress {
+				t.Fatalf("got suggestion %q, %q, want %q, %q", suggestionErr.SuggestedDomain, suggestionErr.SuggestedAddress, tt.wantDomain, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestInspectDataNormalize(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "googlemail.com", Type: models.BlacklistType, Match: models.EqualsMatch},
+		{Name: "proton.me", Type: models.DisposableType, Match: models.EqualsMatch},
+		{Name: "icloud.com", Type: models.WhitelistType, Match: models.EqualsMatch},
+		{Name: "me.com", Type: models.BlacklistType, Match: models.EqualsMatch},
+	}}
+	usecase := newTestInspectUsecase(domainRepo, nil)
+	usecase.providerRepo = &fakeProviderRepo{providers: models.DefaultProviders}
+
+	tests := []struct {
+		data          string
+		wantDomain    string
+		wantCanonical string
+		wantAddress   string
+		wantType      models.Type
+	}{
+		{"j.o.h.n+news@googlemail.com", "googlemail.com", "gmail.com", "john@gmail.com", models.BlacklistType},
+		{"j.o.h.n+news@gmail.com", "gmail.com", "gmail.com", "john@gmail.com", models.UndefinedType},
+		{"john+news@pm.me", "pm.me", "proton.me", "john@proton.me", models.DisposableType},
+		{"john@me.com", "me.com", "icloud.com", "john@icloud.com", models.BlacklistType},
+		{"john@mac.com", "mac.com", "icloud.com", "john@icloud.com", models.WhitelistType},
+		{"john+news@example.com", "example.com", "example.com", "john+news@example.com", models.UndefinedType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			result, err := usecase.InspectData(context.Background(), tt.data, "", "")
+			if err != nil {
+				t.Fatalf("InspectData: %v", err)
+			}
+			if result.Domain != tt.wantDomain || result.CanonicalDomain != tt.wantCanonical || result.CanonicalAddress != tt.wantAddress {
+				t.Fatalf("got domain %q, canonical %q, %q, want %q, %q, %q", result.Domain, result.CanonicalDomain, result.CanonicalAddress, tt.wantDomain, tt.wantCanonical, tt.wantAddress)
+			}
+			if result.Type != tt.wantType {
+				t.Fatalf("got type %s, want %s", result.Type, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestExtractDomainName(t *testing.T) {
+	tests := []struct {
+		data        string
+		wantAddress string
+		wantDomain  string
+		wantErr     bool
+	}{
+		{"User@Example.COM", "user@example.com", "example.com", false},
+		{"Jürgen@Bücher.DE", "jürgen@xn--bcher-kva.de", "xn--bcher-kva.de", false},
+		{"用户@例え.jp", "用户@xn--r8jz45g.jp", "xn--r8jz45g.jp", false},
+		{"münchen.de", "", "xn--mnchen-3ya.de", false},
+		{"xn--mnchen-3ya.de", "", "xn--mnchen-3ya.de", false},
+		{"user@aب.com", "", "", true},
+		{"not an address@", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			address, domainName, err := extractDomainName(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if address != tt.wantAddress || domainName != tt.wantDomain {
+				t.Fatalf("got %q, %q, want %q, %q", address, domainName, tt.wantAddress, tt.wantDomain)
+			}
+		})
+	}
+}
+
+type fakeDisposableRepo struct {
+	domains, mxHosts []string
+}
+
+func disposableRules(names []string, name string) []models.Domain {
+	var rules []models.Domain
+	for _, listed := range names {
+		rule := models.Domain{Name: listed, Type: models.DisposableType, Match: models.SuffixMatch}
+		if rule.Matcher()(name) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func (r fakeDisposableRepo) MatchDomain(_ context.Context, name string) ([]models.Domain, error) {
+	return disposableRules(r.domains, name), nil
+}
+
+func (r fakeDisposableRepo) MatchMXHost(_ context.Context, host string) ([]models.Domain, error) {
+	return disposableRules(r.mxHosts, host), nil
+}
+
+type fakeMXResolver map[string][]string
+
+func (r fakeMXResolver) LookupMailRoute(_ context.Context, name string) (*models.MailRoute, error) {
+	hosts, ok := r[name]
+	if !ok {
+		return nil, errors.New("no answer")
+	}
+	return &models.MailRoute{Status: models.MXRouteStatus, MXHosts: hosts}, nil
+}
+
+func TestInspectDataDisposable(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "trusted.mailinator.com", Type: models.WhitelistType, Match: models.EqualsMatch},
+	}}
+	usecase := newTestInspectUsecase(domainRepo, nil)
+	usecase.disposableRepo = fakeDisposableRepo{domains: []string{"mailinator.com"}, mxHosts: []string{"mailinator.com"}}
+	usecase.mxResolver = fakeMXResolver{
+		"burner.net":  {"mx1.mailinator.com"},
+		"company.com": {"aspmx.l.google.com"},
+	}
+
+	tests := []struct {
+		data       string
+		wantType   models.Type
+		wantSource models.Source
+	}{
+		{"user@mailinator.com", models.DisposableType, models.DisposableSource},
+		{"user@inbox.mailinator.com", models.DisposableType, models.DisposableSource},
+		{"user@trusted.mailinator.com", models.WhitelistType, models.GlobalSource},
+		{"user@burner.net", models.DisposableType, models.DisposableMXSource},
+		{"user@company.com", models.UndefinedType, models.UndefinedSource},
+		{"user@unresolved.org", models.UndefinedType, models.UndefinedSource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			result, err := usecase.InspectData(context.Background(), tt.data, "", "")
+			if err != nil {
+				t.Fatalf("InspectData: %v", err)
+			}
+			if result.Type != tt.wantType || result.Source != tt.wantSource {
+				t.Fatalf("got %s from %s, want %s from %s", result.Type, result.Source, tt.wantType, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"math"
+	"strings"
+)
+
+const (
+	maxSubaddressTags      = 1
+	maxSubaddressTagLength = 32
+	randomMinLength        = 10
+	randomMinEntropy       = 3.3
+	randomMinConsonantRun  = 5
+	randomMaxVowelRatio    = 0.2
+	randomMinClassSwitches = 4
+)
+
+// roleAccounts are compared without separators, so "no-reply" and "no.reply" are covered by "noreply".
+var roleAccounts = map[string]bool{
+	"abuse": true, "admin": true, "administrator": true, "billing": true, "careers": true, "contact": true,
+	"donotreply": true, "help": true, "hostmaster": true, "hr": true, "info": true, "jobs": true,
+	"legal": true, "mailerdaemon": true, "marketing": true, "newsletter": true, "noc": true, "noreply": true,
+	"notifications": true, "office": true, "postmaster": true, "privacy": true, "root": true, "sales": true,
+	"security": true, "support": true, "team": true, "webmaster": true,
+}
+
+func analyzeLocalPart(localPart string) []models.Flag {
+	base, tags := splitSubaddress(localPart)
+	compact := strings.NewReplacer(".", "", "-", "", "_", "").Replace(base)
+
+	var flags []models.Flag
+	if roleAccounts[compact] {
+		flags = append(flags, models.Flag{Code: models.RoleAccountFlag, Detail: fmt.Sprintf("%s is a role account", base)})
+	}
+	if compact != "" && strings.Trim(compact, "0123456789") == "" {
+		flags = append(flags, models.Flag{Code: models.NumericOnlyFlag, Detail: fmt.Sprintf("%d digits", len(compact))})
+	}
+	if detail, ok := looksRandom(compact); ok {
+		flags = append(flags, models.Flag{Code: models.RandomStringFlag, Detail: detail})
+	}
+	if len(tags) > maxSubaddressTags {
+		flags = append(flags, models.Flag{Code: models.ExcessiveSubaddressingFlag, Detail: fmt.Sprintf("%d sub-address tags", len(tags))})
+	} else {
+		for _, tag := range tags {
+			if len(tag) > maxSubaddressTagLength {
+				flags = append(flags, models.Flag{Code: models.ExcessiveSubaddressingFlag, Detail: fmt.Sprintf("sub-address tag of %d characters", len(tag))})
+			}
+		}
+	}
+	return flags
+}
+
+func splitSubaddress(localPart string) (string, []string) {
+	parts := strings.Split(localPart, "+")
+	return parts[0], parts[1:]
+}
+
+func looksRandom(s string) (string, bool) {
+	if len(s) < randomMinLength {
+		return "", false
+	}
+	entropy := shannonEntropy(s)
+	if entropy < randomMinEntropy {
+		return "", false
+	}
+
+	vowels, letters, consonantRun, longestConsonantRun, classSwitches := 0, 0, 0, 0, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if i > 0 && isDigit(c) != isDigit(s[i-1]) {
+			classSwitches++
+		}
+		if !isLetter(c) {
+			consonantRun = 0
+			continue
+		}
+		letters++
+		if strings.IndexByte("aeiouy", c) >= 0 {
+			vowels++
+			consonantRun = 0
+			continue
+		}
+		consonantRun++
+		longestConsonantRun = max(longestConsonantRun, consonantRun)
+	}
+
+	switch {
+	case longestConsonantRun >= randomMinConsonantRun:
+		return fmt.Sprintf("entropy %.2f, %d consonants in a row", entropy, longestConsonantRun), true
+	case letters > 0 && float64(vowels)/float64(letters) < randomMaxVowelRatio:
+		return fmt.Sprintf("entropy %.2f, %d vowels of %d letters", entropy, vowels, letters), true
+	case classSwitches >= randomMinClassSwitches:
+		return fmt.Sprintf("entropy %.2f, %d switches between letters and digits", entropy, classSwitches), true
+	}
+	return "", false
+}
+
+func shannonEntropy(s string) float64 {
+	counts := make(map[byte]int)
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(len(s))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"slices"
+	"testing"
+)
+
+func TestAnalyzeLocalPart(t *testing.T) {
+	tests := []struct {
+		localPart string
+		want      []models.FlagCode
+	}{
+		{"john.smith", nil},
+		{"alexander.hamilton", nil},
+		{"admin", []models.FlagCode{models.RoleAccountFlag}},
+		{"no-reply", []models.FlagCode{models.RoleAccountFlag}},
+		{"no.reply+alerts", []models.FlagCode{models.RoleAccountFlag}},
+		{"support-team", nil},
+		{"1234567", []models.FlagCode{models.NumericOnlyFlag}},
+		{"123.456", []models.FlagCode{models.NumericOnlyFlag}},
+		{"john2024", nil},
+		{"xkqzvbtrwp", []models.FlagCode{models.RandomStringFlag}},
+		{"a1b2c3d4e5f6", []models.FlagCode{models.RandomStringFlag}},
+		{"john+news", nil},
+		{"john+news+promo", []models.FlagCode{models.ExcessiveSubaddressingFlag}},
+		{"john+abcdefghijklmnopqrstuvwxyz0123456789", []models.FlagCode{models.ExcessiveSubaddressingFlag}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.localPart, func(t *testing.T) {
+			var got []models.FlagCode
+			for _, flag := range analyzeLocalPart(tt.localPart) {
+				if flag.Detail == "" {
+					t.Errorf("flag %s has no detail", flag.Code)
+				}
+				got = append(got, flag.Code)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLooksRandom(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"short", false},
+		{"aaaaaaaaaaaa", false},
+		{"christopher", false},
+		{"bcdfghjklmnp", true},
+		{"qwrtzpsdfgh", true},
+		{"x9k2m7q4w8", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			detail, got := looksRandom(tt.s)
+			if got != tt.want {
+				t.Fatalf("got %t (%s), want %t", got, detail, tt.want)
+			}
+		})
+	}
+}
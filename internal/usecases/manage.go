@@ -3,63 +3,538 @@ package usecases
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
+	"maps"
+	"strings"
+	"sync"
+	"time"
 )
 
 type ManageUsecase struct {
-	domainRepo DomainRepository
-	filterRepo FilterRepository
+	accessRepo   AccessRepository
+	domainRepo   DomainRepository
+	filterRepo   FilterRepository
+	providerRepo ProviderRepository
+	auditRepo    AuditRepository
+	transactor   Transactor
+	verdictCache VerdictCache
+	domainCounts *countCache
+
+	importBatchSize int
+	importMaxRows   int
 }
 
-func NewManageUsecase(domainRepo DomainRepository, filterRepo FilterRepository) *ManageUsecase {
+// NewManageUsecase takes an optional verdictCache.
+func NewManageUsecase(accessRepo AccessRepository, domainRepo DomainRepository, filterRepo FilterRepository, providerRepo ProviderRepository, auditRepo AuditRepository, transactor Transactor, verdictCache VerdictCache, countTTL time.Duration, importBatchSize, importMaxRows int) *ManageUsecase {
 	return &ManageUsecase{
-		domainRepo: domainRepo,
-		filterRepo: filterRepo,
+		accessRepo:   accessRepo,
+		domainRepo:   domainRepo,
+		filterRepo:   filterRepo,
+		providerRepo: providerRepo,
+		auditRepo:    auditRepo,
+		transactor:   transactor,
+		verdictCache: verdictCache,
+		domainCounts: &countCache{ttl: countTTL},
+
+		importBatchSize: max(importBatchSize, 1),
+		importMaxRows:   importMaxRows,
 	}
 }
 
-func (mu ManageUsecase) CreateDomain(ctx context.Context, domainName, domainType, domainCoverage string) (*models.Domain, error) {
+func (mu ManageUsecase) CreateDomain(ctx context.Context, domainName, domainType, domainCoverage string, expiresAt *time.Time) (*models.Domain, error) {
+	if err := validateExpiry(expiresAt); err != nil {
+		return nil, err
+	}
+	domainMatch := models.DomainMatchFromString(domainCoverage)
+	domainName, err := normalizeRuleName(domainName, domainMatch)
+	if err != nil {
+		return nil, err
+	}
 	domain := &models.Domain{
-		Name:  domainName,
-		Type:  models.DomainTypeFromString(domainType),
-		Match: models.DomainMatchFromString(domainCoverage),
+		Name:      domainName,
+		Type:      models.DomainTypeFromString(domainType),
+		Match:     domainMatch,
+		ExpiresAt: expiresAt,
+	}
+	if domain.Type == models.UndefinedType {
+		return nil, models.ErrDomainTrustedTypes
+	}
+	if domain.Match == models.UndefinedMatch {
+		return nil, models.ErrDomainCoverage
 	}
-	if err := mu.domainRepo.Create(ctx, domain); err != nil {
-		return nil, err // TODO: how to handle in handler http.StatusConflict or http.StatusInternalServerError?
+	if err := mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := mu.domainRepo.Create(ctx, domain); err != nil {
+			return err // TODO: how to handle in handler http.StatusConflict or http.StatusInternalServerError?
+		}
+		return mu.recordAudit(ctx, models.NewAuditEntry(ctx, "", nil, domain))
+	}); err != nil {
+		return nil, err
 	}
+	mu.domainsChanged(ctx, *domain)
 	return domain, nil
 }
 
-func (mu ManageUsecase) GetDomainByName(ctx context.Context, domainName string) (*models.Domain, error) {
-	return mu.domainRepo.FindByName(ctx, domainName)
+func (mu ManageUsecase) GetDomainByName(ctx context.Context, selector models.DomainSelector) (*models.Domain, error) {
+	return mu.findDomain(ctx, selector)
 }
 
-func (mu ManageUsecase) UpdateDomain(ctx context.Context, domainName string, domainType, domainCoverage string) (*models.Domain, error) {
-	d, err := mu.domainRepo.FindByName(ctx, domainName)
+func (mu ManageUsecase) findDomain(ctx context.Context, selector models.DomainSelector) (*models.Domain, error) {
+	domainType, domainMatch, err := parseRuleFilter(selector.Type, selector.Match)
 	if err != nil {
 		return nil, err
 	}
-	d.Type = models.DomainTypeFromString(domainType)
-	d.Match = models.DomainMatchFromString(domainCoverage)
-	if err := mu.domainRepo.Update(ctx, d); err != nil {
+	return findRule(selector.Name, models.ErrDomainNotFound, func(name string) (*models.Domain, error) {
+		return mu.domainRepo.FindByName(ctx, name, domainType, domainMatch)
+	})
+}
+
+func (mu ManageUsecase) findFilter(ctx context.Context, projectToken string, selector models.DomainSelector) (*models.Filter, error) {
+	filterType, filterMatch, err := parseRuleFilter(selector.Type, selector.Match)
+	if err != nil {
+		return nil, err
+	}
+	return findRule(selector.Name, models.ErrFilterNotFound, func(name string) (*models.Filter, error) {
+		return mu.filterRepo.FindByName(ctx, projectToken, name, filterType, filterMatch)
+	})
+}
+
+func findRule[T any](domainName string, notFound error, find func(name string) (*T, error)) (*T, error) {
+	var err error
+	for _, name := range models.RuleNames(domainName) {
+		var rule *T
+		if rule, err = find(name); !errors.Is(err, notFound) {
+			return rule, err
+		}
+	}
+	return nil, err
+}
+
+func (mu ManageUsecase) UpdateDomain(ctx context.Context, selector models.DomainSelector, domainType, domainCoverage string) (*models.Domain, error) {
+	newType := models.DomainTypeFromString(domainType)
+	if newType == models.UndefinedType {
+		return nil, models.ErrDomainTrustedTypes
+	}
+	domainMatch := models.DomainMatchFromString(domainCoverage)
+	if domainMatch == models.UndefinedMatch {
+		return nil, models.ErrDomainCoverage
+	}
+	d, err := mu.findDomain(ctx, selector)
+	if err != nil {
 		return nil, err
 	}
+	previous := *d
+	if domainMatch != d.Match {
+		if d.Name, err = normalizeRuleName(d.Name, domainMatch); err != nil {
+			return nil, err
+		}
+	}
+	d.Type = newType
+	d.Match = domainMatch
+	if err := mu.updateDomain(ctx, &previous, d); err != nil {
+		return nil, err
+	}
+	mu.domainsChanged(ctx, previous, *d)
+	return d, nil
+}
+
+func (mu ManageUsecase) SetDomainExpiry(ctx context.Context, selector models.DomainSelector, expiresAt *time.Time) (*models.Domain, error) {
+	if err := validateExpiry(expiresAt); err != nil {
+		return nil, err
+	}
+	d, err := mu.findDomain(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+	previous := *d
+	d.ExpiresAt = expiresAt
+	if err := mu.updateDomain(ctx, &previous, d); err != nil {
+		return nil, err
+	}
+	mu.domainsChanged(ctx, *d)
 	return d, nil
 }
 
-func (mu ManageUsecase) DeleteDomain(ctx context.Context, domainName string) error {
-	domain, err := mu.domainRepo.FindByName(ctx, domainName)
+func (mu ManageUsecase) updateDomain(ctx context.Context, previous, d *models.Domain) error {
+	return mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := mu.domainRepo.Update(ctx, previous, d); err != nil {
+			return err
+		}
+		return mu.recordAudit(ctx, models.NewAuditEntry(ctx, "", previous, d))
+	})
+}
+
+func (mu ManageUsecase) DeleteDomain(ctx context.Context, selector models.DomainSelector) error {
+	domain, err := mu.findDomain(ctx, selector)
 	if err != nil {
 		return err
 	}
-	return mu.domainRepo.Delete(ctx, domain)
+	if err := mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := mu.domainRepo.Delete(ctx, domain); err != nil {
+			return err
+		}
+		return mu.recordAudit(ctx, models.NewAuditEntry(ctx, "", domain, nil))
+	}); err != nil {
+		return err
+	}
+	mu.domainsChanged(ctx, *domain)
+	return nil
+}
+
+func (mu ManageUsecase) SearchDomains(ctx context.Context, search models.DomainSearch) (*models.DomainPage, error) {
+	query := models.DomainQuery{
+		Sort:       models.DomainSortFromString(search.Sort),
+		Descending: search.Descending,
+		Limit:      search.Limit,
+	}
+	var err error
+	if query.Type, query.Match, err = parseRuleFilter(search.Type, search.Match); err != nil {
+		return nil, err
+	}
+	if query.Sort == (models.DomainSort{}) {
+		return nil, models.ErrInvalidSort
+	}
+	if search.NameContains != "" {
+		nameContains, err := models.DomainToASCII(search.NameContains)
+		if err != nil {
+			return nil, err
+		}
+		query.NameContains = nameContains
+	}
+	if isInvalidRange(search.CreatedFrom, search.CreatedTo) || isInvalidRange(search.UpdatedFrom, search.UpdatedTo) {
+		return nil, models.ErrInvalidTimeRange
+	}
+	query.CreatedFrom, query.CreatedTo = search.CreatedFrom, search.CreatedTo
+	query.UpdatedFrom, query.UpdatedTo = search.UpdatedFrom, search.UpdatedTo
+	if query.Limit <= 0 {
+		query.Limit = models.DefaultDomainPageSize
+	}
+	query.Limit = min(query.Limit, models.MaxDomainPageSize)
+	if search.Cursor != "" {
+		cursor, err := models.DecodeDomainCursor(search.Cursor, query.Sort, query.Descending)
+		if err != nil {
+			return nil, err
+		}
+		query.Cursor = cursor
+	}
+	return mu.domainRepo.Search(ctx, query)
+}
+
+func parseRuleFilter(domainType, domainMatch string) (models.Type, models.Match, error) {
+	var t models.Type
+	var m models.Match
+	if domainType != "" {
+		if t = models.DomainTypeFromString(domainType); t == models.UndefinedType {
+			return t, m, models.ErrInvalidTypeFilter
+		}
+	}
+	if domainMatch != "" {
+		if m = models.DomainMatchFromString(domainMatch); m == models.UndefinedMatch {
+			return t, m, models.ErrInvalidCoverageFilter
+		}
+	}
+	return t, m, nil
+}
+
+func isInvalidRange(from, to time.Time) bool {
+	return !from.IsZero() && !to.IsZero() && !from.Before(to)
 }
 
 func (mu ManageUsecase) CountDomains(ctx context.Context) (map[models.Type]int, error) {
-	// TODO: add cache
-	return mu.domainRepo.CountDomainTypes(ctx)
+	if counts, ok := mu.domainCounts.get(); ok {
+		return counts, nil
+	}
+	counts, err := mu.domainRepo.CountDomainTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mu.domainCounts.set(counts)
+	return counts, nil
+}
+
+// domainsChanged takes an updated rule both before and after the change, since both decide which verdicts are stale.
+func (mu ManageUsecase) domainsChanged(ctx context.Context, rules ...models.Domain) {
+	mu.domainCounts.reset()
+	if mu.verdictCache != nil {
+		mu.verdictCache.Invalidate(ctx, "", rules...)
+	}
+}
+
+// recordAudit has to run in the transaction of the change, so that a failure rolls the change back.
+func (mu ManageUsecase) recordAudit(ctx context.Context, entries ...models.AuditEntry) error {
+	if err := mu.auditRepo.Create(ctx, entries...); err != nil {
+		return fmt.Errorf("could not record the change in the audit log: %w", err)
+	}
+	return nil
+}
+
+func (mu ManageUsecase) GetAuditLog(ctx context.Context, query models.AuditQuery) (*models.AuditPage, error) {
+	if query.Limit <= 0 {
+		query.Limit = models.DefaultAuditPageSize
+	}
+	query.Limit = min(query.Limit, models.MaxAuditPageSize)
+	return mu.auditRepo.Find(ctx, query)
+}
+
+func (mu ManageUsecase) filtersChanged(ctx context.Context, filter *models.Filter) {
+	if mu.verdictCache != nil {
+		mu.verdictCache.Invalidate(ctx, filter.ProjectToken, filter.Domain)
+	}
+}
+
+func (mu ManageUsecase) CreateFilter(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken, domainName, domainType, domainCoverage string, expiresAt *time.Time) (*models.Filter, error) {
+	if err := mu.checkProjectAccess(ctx, userUUID, userRole, projectToken); err != nil {
+		return nil, err
+	}
+	if err := validateExpiry(expiresAt); err != nil {
+		return nil, err
+	}
+	domainMatch := models.DomainMatchFromString(domainCoverage)
+	domainName, err := normalizeRuleName(domainName, domainMatch)
+	if err != nil {
+		return nil, err
+	}
+	filter := &models.Filter{
+		ProjectToken: projectToken,
+		Domain: models.Domain{
+			Name:      domainName,
+			Type:      models.DomainTypeFromString(domainType),
+			Match:     domainMatch,
+			ExpiresAt: expiresAt,
+		},
+	}
+	if filter.Type == models.UndefinedType {
+		return nil, models.ErrDomainTrustedTypes
+	}
+	if filter.Match == models.UndefinedMatch {
+		return nil, models.ErrDomainCoverage
+	}
+	if err := mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := mu.filterRepo.Create(ctx, filter); err != nil {
+			return err
+		}
+		return mu.recordAudit(ctx, models.NewAuditEntry(ctx, filter.ProjectToken, nil, &filter.Domain))
+	}); err != nil {
+		return nil, err
+	}
+	mu.filtersChanged(ctx, filter)
+	return filter, nil
+}
+
+func (mu ManageUsecase) GetFilters(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken string) ([]models.Filter, error) {
+	if projectToken != "" {
+		if err := mu.checkProjectAccess(ctx, userUUID, userRole, projectToken); err != nil {
+			return nil, err
+		}
+		return mu.filterRepo.FindByProjectToken(ctx, projectToken)
+	}
+	if userRole == models.StaffRole {
+		return mu.filterRepo.FindAll(ctx)
+	}
+	accessList, err := mu.accessRepo.FindByCustomerUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	filters := make([]models.Filter, 0)
+	for _, access := range accessList {
+		projectFilters, err := mu.filterRepo.FindByProjectToken(ctx, access.Token)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, projectFilters...)
+	}
+	return filters, nil
+}
+
+func (mu ManageUsecase) SetFilterExpiry(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken string, selector models.DomainSelector, expiresAt *time.Time) (*models.Filter, error) {
+	if err := mu.checkProjectAccess(ctx, userUUID, userRole, projectToken); err != nil {
+		return nil, err
+	}
+	if err := validateExpiry(expiresAt); err != nil {
+		return nil, err
+	}
+	filter, err := mu.findFilter(ctx, projectToken, selector)
+	if err != nil {
+		return nil, err
+	}
+	previous := filter.Domain
+	filter.ExpiresAt = expiresAt
+	if err := mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := mu.filterRepo.Update(ctx, filter); err != nil {
+			return err
+		}
+		return mu.recordAudit(ctx, models.NewAuditEntry(ctx, projectToken, &previous, &filter.Domain))
+	}); err != nil {
+		return nil, err
+	}
+	mu.filtersChanged(ctx, filter)
+	return filter, nil
+}
+
+// normalizeRuleName also validates the names of the rules that are neither patterns nor registrable, the way every rule is created.
+func normalizeRuleName(name string, match models.Match) (string, error) {
+	name, err := models.NormalizeRuleName(name, match)
+	if err != nil {
+		return "", err
+	}
+	if !match.IsPattern() && match != models.RegistrableMatch {
+		if err := models.ValidateDomainName(name); err != nil {
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+func validateExpiry(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return models.ErrInvalidExpiry
+	}
+	return nil
+}
+
+func (mu ManageUsecase) DeleteFilter(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken string, selector models.DomainSelector) error {
+	if err := mu.checkProjectAccess(ctx, userUUID, userRole, projectToken); err != nil {
+		return err
+	}
+	filter, err := mu.findFilter(ctx, projectToken, selector)
+	if err != nil {
+		return err
+	}
+	if err := mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := mu.filterRepo.Delete(ctx, filter); err != nil {
+			return err
+		}
+		return mu.recordAudit(ctx, models.NewAuditEntry(ctx, filter.ProjectToken, &filter.Domain, nil))
+	}); err != nil {
+		return err
+	}
+	mu.filtersChanged(ctx, filter)
+	return nil
+}
+
+func (mu ManageUsecase) GetProviders(ctx context.Context) ([]models.Provider, error) {
+	return mu.providerRepo.FindAll(ctx)
+}
+
+func (mu ManageUsecase) CreateProvider(ctx context.Context, domainName, canonicalDomain string, ignoreDots bool, tagSeparators string) (*models.Provider, error) {
+	provider := &models.Provider{
+		Domain:          domainName,
+		CanonicalDomain: canonicalDomain,
+		IgnoreDots:      ignoreDots,
+		TagSeparators:   tagSeparators,
+	}
+	if err := validateProvider(provider); err != nil {
+		return nil, err
+	}
+	if err := mu.providerRepo.Create(ctx, provider); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func (mu ManageUsecase) UpdateProvider(ctx context.Context, domainName, canonicalDomain string, ignoreDots bool, tagSeparators string) (*models.Provider, error) {
+	domainName, err := models.DomainToASCII(domainName)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := mu.providerRepo.Get(ctx, domainName)
+	if err != nil {
+		return nil, err
+	}
+	provider.CanonicalDomain = canonicalDomain
+	provider.IgnoreDots = ignoreDots
+	provider.TagSeparators = tagSeparators
+	if err := validateProvider(provider); err != nil {
+		return nil, err
+	}
+	if err := mu.providerRepo.Update(ctx, provider); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func (mu ManageUsecase) DeleteProvider(ctx context.Context, domainName string) error {
+	domainName, err := models.DomainToASCII(domainName)
+	if err != nil {
+		return err
+	}
+	return mu.providerRepo.Delete(ctx, domainName)
+}
+
+func validateProvider(provider *models.Provider) error {
+	var err error
+	if provider.Domain, err = models.DomainToASCII(provider.Domain); err != nil {
+		return err
+	}
+	if err := models.ValidateDomainName(provider.Domain); err != nil {
+		return err
+	}
+	if provider.CanonicalDomain == "" {
+		provider.CanonicalDomain = provider.Domain
+	}
+	if provider.CanonicalDomain, err = models.DomainToASCII(provider.CanonicalDomain); err != nil {
+		return err
+	}
+	if err := models.ValidateDomainName(provider.CanonicalDomain); err != nil {
+		return err
+	}
+	if strings.Trim(provider.TagSeparators, models.AllowedTagSeparators) != "" {
+		return models.ErrInvalidTagSeparators
+	}
+	return nil
+}
+
+func (mu ManageUsecase) checkProjectAccess(ctx context.Context, userUUID uuid.UUID, userRole models.Role, projectToken string) error {
+	if projectToken == "" {
+		return models.ErrProjectNotFound
+	}
+	access, err := mu.accessRepo.Get(ctx, projectToken)
+	if err != nil {
+		if errors.Is(err, models.ErrApiKeyNotFound) {
+			return models.ErrProjectNotFound
+		}
+		return err
+	}
+	if userRole == models.StaffRole {
+		return nil
+	}
+	if access.CustomerUUID == uuid.Nil || access.CustomerUUID != userUUID {
+		return models.ErrProjectAccessDenied
+	}
+	return nil
+}
+
+// countCache holds the domain counts for ttl, it is shared by the copies of the value receiver ManageUsecase.
+type countCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	counts    map[models.Type]int
+	expiresAt time.Time
+}
+
+func (c *countCache) get() (map[models.Type]int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil || !time.Now().Before(c.expiresAt) {
+		return nil, false
+	}
+	return maps.Clone(c.counts), true
+}
+
+func (c *countCache) set(counts map[models.Type]int) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = maps.Clone(counts)
+	c.expiresAt = time.Now().Add(c.ttl)
 }
 
-func (mu ManageUsecase) CreateFilter(ctx context.Context, domainName, domainType, domainCoverage, projectToken string) (models.Filter, error) {
-	return models.Filter{}, errors.New("not implemented")
+func (c *countCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = nil
 }
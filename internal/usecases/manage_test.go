@@ -0,0 +1,428 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func (r *fakeDomainRepo) find(name string, deleted bool) int {
+	for i, rule := range r.rules {
+		if rule.Name == name && (rule.DeletedAt != nil) == deleted {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *fakeDomainRepo) Create(_ context.Context, domain *models.Domain) error {
+	if slices.ContainsFunc(r.rules, domain.Same) {
+		return models.ErrDomainAlreadyExists
+	}
+	r.rules = append(r.rules, *domain)
+	return nil
+}
+
+func (r *fakeDomainRepo) FindByName(_ context.Context, name string, domainType models.Type, domainMatch models.Match) (*models.Domain, error) {
+	var found []models.Domain
+	for _, rule := range r.rules {
+		if rule.Name == name && rule.DeletedAt == nil &&
+			(domainType == (models.Type{}) || rule.Type == domainType) &&
+			(domainMatch == (models.Match{}) || rule.Match == domainMatch) {
+			found = append(found, rule)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, models.ErrDomainNotFound
+	case 1:
+		return &found[0], nil
+	default:
+		return nil, models.ErrDomainAmbiguous
+	}
+}
+
+func (r *fakeDomainRepo) live(rule models.Domain) int {
+	return slices.IndexFunc(r.rules, func(stored models.Domain) bool {
+		return stored.DeletedAt == nil && stored.Same(rule)
+	})
+}
+
+func (r *fakeDomainRepo) Update(_ context.Context, previous, domain *models.Domain) error {
+	i := r.live(*previous)
+	if i < 0 {
+		return models.ErrDomainNotFound
+	}
+	if j := r.live(*domain); j >= 0 && j != i {
+		return models.ErrDomainAlreadyExists
+	}
+	r.rules[i] = *domain
+	return nil
+}
+
+func (r *fakeDomainRepo) Delete(_ context.Context, domain *models.Domain) error {
+	i := r.live(*domain)
+	if i < 0 {
+		return models.ErrDomainNotFound
+	}
+	deletedAt := time.Now()
+	r.rules[i].DeletedAt = &deletedAt
+	return nil
+}
+
+func (r *fakeDomainRepo) Restore(_ context.Context, name string, _ models.Type, _ models.Match) (*models.Domain, error) {
+	i := r.find(name, true)
+	if i < 0 {
+		return nil, models.ErrDomainNotFound
+	}
+	r.rules[i].DeletedAt = nil
+	rule := r.rules[i]
+	return &rule, nil
+}
+
+func (r *fakeAccessRepo) Get(_ context.Context, token string) (*models.Access, error) {
+	if token != r.access.Token {
+		return nil, models.ErrApiKeyNotFound
+	}
+	access := r.access
+	return &access, nil
+}
+
+type fakeAuditRepo struct {
+	AuditRepository
+	entries []models.AuditEntry
+}
+
+func (r *fakeAuditRepo) Create(_ context.Context, entries ...models.AuditEntry) error {
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+// fakeVerdictCache holds the cached domain names and drops them the way VerdictLRU does.
+type fakeVerdictCache struct {
+	VerdictCache
+	names map[string]bool
+}
+
+func (c *fakeVerdictCache) Invalidate(_ context.Context, _ string, rules ...models.Domain) {
+	for name := range c.names {
+		for _, rule := range rules {
+			if rule.Matcher()(name) {
+				delete(c.names, name)
+			}
+		}
+	}
+}
+
+type fakeTransactor struct{}
+
+func (fakeTransactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestManageDomainByStoredName(t *testing.T) {
+	tests := []struct {
+		name   string
+		given  string
+		stored string
+		match  models.Match
+	}{
+		{"regex with uppercase classes", `\D+\.[A-Z]+`, `\D+\.[A-Z]+`, models.RegexMatch},
+		{"domain in uppercase", "Example.COM", "example.com", models.EqualsMatch},
+		{"domain in U-labels", "bücher.de", "xn--bcher-kva.de", models.EqualsMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domainRepo := &fakeDomainRepo{rules: []models.Domain{
+				{Name: `\d+\.[a-z]+`, Type: models.WhitelistType, Match: models.RegexMatch},
+				{Name: tt.stored, Type: models.BlacklistType, Match: tt.match},
+			}}
+			mu := NewManageUsecase(nil, domainRepo, nil, nil, &fakeAuditRepo{}, fakeTransactor{}, nil, time.Minute, 1, 1)
+			ctx := context.Background()
+
+			if d, err := mu.GetDomainByName(ctx, models.DomainSelector{Name: tt.given}); err != nil || d.Name != tt.stored {
+				t.Fatalf("get: got %v, %v, want %q", d, err, tt.stored)
+			}
+			if d, err := mu.UpdateDomain(ctx, models.DomainSelector{Name: tt.given}, models.DisposableType.String(), tt.match.String()); err != nil || d.Type != models.DisposableType {
+				t.Fatalf("update: got %v, %v", d, err)
+			}
+			expiresAt := time.Now().Add(time.Hour)
+			if d, err := mu.SetDomainExpiry(ctx, models.DomainSelector{Name: tt.given}, &expiresAt); err != nil || d.ExpiresAt == nil {
+				t.Fatalf("set expiry: got %v, %v", d, err)
+			}
+			if err := mu.DeleteDomain(ctx, models.DomainSelector{Name: tt.given}); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if d, err := mu.RestoreDomain(ctx, tt.given, "", ""); err != nil || d.Name != tt.stored {
+				t.Fatalf("restore: got %v, %v, want %q", d, err, tt.stored)
+			}
+			if other := domainRepo.rules[0]; other.Type != models.WhitelistType || other.DeletedAt != nil {
+				t.Fatalf("the rule %q was changed too: %+v", other.Name, other)
+			}
+		})
+	}
+}
+
+func TestManageDomainSharedName(t *testing.T) {
+	blacklisted := models.Domain{Name: "shared.example", Type: models.BlacklistType, Match: models.EqualsMatch}
+	whitelisted := models.Domain{Name: "shared.example", Type: models.WhitelistType, Match: models.EqualsMatch}
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{blacklisted, whitelisted}}
+	cache := &fakeVerdictCache{names: map[string]bool{"shared.example": true}}
+	mu := NewManageUsecase(nil, domainRepo, nil, nil, &fakeAuditRepo{}, fakeTransactor{}, cache, time.Minute, 1, 1)
+	ctx := context.Background()
+
+	if _, err := mu.GetDomainByName(ctx, models.DomainSelector{Name: "shared.example"}); !errors.Is(err, models.ErrDomainAmbiguous) {
+		t.Fatalf("get without type and coverage: got %v, want %v", err, models.ErrDomainAmbiguous)
+	}
+	if err := mu.DeleteDomain(ctx, models.DomainSelector{Name: "shared.example"}); !errors.Is(err, models.ErrDomainAmbiguous) {
+		t.Fatalf("delete without type and coverage: got %v, want %v", err, models.ErrDomainAmbiguous)
+	}
+	selector := models.DomainSelector{Name: "shared.example", Type: "blacklist", Match: "equals"}
+	if _, err := mu.UpdateDomain(ctx, selector, "whitelist", "equals"); !errors.Is(err, models.ErrDomainAlreadyExists) {
+		t.Fatalf("update onto the sibling: got %v, want %v", err, models.ErrDomainAlreadyExists)
+	}
+	if _, err := mu.UpdateDomain(ctx, selector, "blacklist", "suffix"); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := mu.DeleteDomain(ctx, models.DomainSelector{Name: "shared.example", Type: "blacklist", Match: "suffix"}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if d, err := mu.GetDomainByName(ctx, models.DomainSelector{Name: "shared.example"}); err != nil || !d.Same(whitelisted) {
+		t.Fatalf("got %v, %v, the sibling rule has to stay as it was", d, err)
+	}
+	if cache.names["shared.example"] {
+		t.Fatal("the verdict of the changed rule is still cached")
+	}
+}
+
+func TestManageDomainValidatesName(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "*spam*", Type: models.BlacklistType, Match: models.GlobMatch},
+	}}
+	mu := NewManageUsecase(nil, domainRepo, nil, nil, &fakeAuditRepo{}, fakeTransactor{}, nil, time.Minute, 1, 1)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		coverage string
+		wantErr  error
+	}{
+		{"valid.example", "equals", nil},
+		{"bad name.example", "equals", models.ErrDomainNotValid},
+		{"-bad.example", "suffix", models.ErrDomainNotValid},
+		{"*.glob.example", "glob", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := mu.CreateDomain(ctx, tt.name, "blacklist", tt.coverage, nil); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	selector := models.DomainSelector{Name: "*spam*", Type: "blacklist", Match: "glob"}
+	if _, err := mu.UpdateDomain(ctx, selector, "blacklist", "equals"); !errors.Is(err, models.ErrDomainNotValid) {
+		t.Fatalf("update a pattern to equals: got %v, want %v", err, models.ErrDomainNotValid)
+	}
+	if _, err := mu.UpdateDomain(ctx, selector, "undefined", "glob"); !errors.Is(err, models.ErrDomainTrustedTypes) {
+		t.Fatalf("update to an undefined type: got %v, want %v", err, models.ErrDomainTrustedTypes)
+	}
+	if _, err := mu.UpdateDomain(ctx, selector, "blacklist", "undefined"); !errors.Is(err, models.ErrDomainCoverage) {
+		t.Fatalf("update to an undefined coverage: got %v, want %v", err, models.ErrDomainCoverage)
+	}
+}
+
+func TestUpdateDomainNormalizesNameForNewCoverage(t *testing.T) {
+	// stored before names were normalized on create
+	legacy := models.Domain{Name: "Legacy.Example", Type: models.BlacklistType, Match: models.EqualsMatch}
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{legacy}}
+	mu := NewManageUsecase(nil, domainRepo, nil, nil, &fakeAuditRepo{}, fakeTransactor{}, nil, time.Minute, 1, 1)
+
+	selector := models.DomainSelector{Name: legacy.Name, Type: "blacklist", Match: "equals"}
+	d, err := mu.UpdateDomain(context.Background(), selector, "blacklist", "suffix")
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if d.Name != "legacy.example" || domainRepo.rules[0].Name != "legacy.example" {
+		t.Fatalf("got %q stored as %q, want the name normalized for the suffix coverage", d.Name, domainRepo.rules[0].Name)
+	}
+}
+
+func TestCheckProjectAccess(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	tests := []struct {
+		name     string
+		customer uuid.UUID
+		userUUID uuid.UUID
+		role     models.Role
+		token    string
+		wantErr  error
+	}{
+		{"owner", owner, owner, models.CustomerRole, "token", nil},
+		{"other customer", owner, other, models.CustomerRole, "token", models.ErrProjectAccessDenied},
+		{"staff", owner, other, models.StaffRole, "token", nil},
+		{"owner not recorded", uuid.Nil, other, models.CustomerRole, "token", models.ErrProjectAccessDenied},
+		{"staff with owner not recorded", uuid.Nil, other, models.StaffRole, "token", nil},
+		{"unknown project", owner, owner, models.CustomerRole, "unknown", models.ErrProjectNotFound},
+		{"no project", owner, owner, models.CustomerRole, "", models.ErrProjectNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessRepo := &fakeAccessRepo{access: models.Access{Token: "token", CustomerUUID: tt.customer}}
+			mu := NewManageUsecase(accessRepo, nil, nil, nil, nil, fakeTransactor{}, nil, time.Minute, 1, 1)
+			if err := mu.checkProjectAccess(context.Background(), tt.userUUID, tt.role, tt.token); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManageInvalidatesVerdicts(t *testing.T) {
+	cache := &fakeVerdictCache{}
+	domainRepo := &fakeDomainRepo{}
+	mu := NewManageUsecase(nil, domainRepo, nil, nil, &fakeAuditRepo{}, fakeTransactor{}, cache, time.Minute, 100, 100)
+	ctx := context.Background()
+	cached := []string{"spam.com", "mail.spam.com", "example.org"}
+
+	tests := []struct {
+		name   string
+		change func() error
+		want   []string
+	}{
+		{"create", func() error {
+			_, err := mu.CreateDomain(ctx, "spam.com", models.BlacklistType.String(), models.SuffixMatch.String(), nil)
+			return err
+		}, []string{"example.org"}},
+		{"update drops the verdicts of the rule before the change", func() error {
+			_, err := mu.UpdateDomain(ctx, models.DomainSelector{Name: "spam.com"}, models.BlacklistType.String(), models.EqualsMatch.String())
+			return err
+		}, []string{"example.org"}},
+		{"delete", func() error {
+			return mu.DeleteDomain(ctx, models.DomainSelector{Name: "spam.com"})
+		}, []string{"mail.spam.com", "example.org"}},
+		{"import", func() error {
+			_, err := mu.ImportDomains(ctx, strings.NewReader("example.org,whitelist,registrable\n"), models.CSVImportFormat.String(), "", "", false)
+			return err
+		}, []string{"spam.com", "mail.spam.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache.names = make(map[string]bool)
+			for _, name := range cached {
+				cache.names[name] = true
+			}
+			if err := tt.change(); err != nil {
+				t.Fatalf("change: %v", err)
+			}
+			var kept []string
+			for _, name := range cached {
+				if cache.names[name] {
+					kept = append(kept, name)
+				}
+			}
+			if !slices.Equal(kept, tt.want) {
+				t.Fatalf("kept the verdicts of %v, want %v", kept, tt.want)
+			}
+		})
+	}
+}
+
+// fakeProjectFilterRepo keeps the filters of the projects in memory.
+type fakeProjectFilterRepo struct {
+	FilterRepository
+	filters []models.Filter
+}
+
+func (r *fakeProjectFilterRepo) FindByName(_ context.Context, projectToken, name string, filterType models.Type, filterMatch models.Match) (*models.Filter, error) {
+	var found []models.Filter
+	for _, filter := range r.filters {
+		if filter.ProjectToken == projectToken && filter.Name == name &&
+			(filterType == (models.Type{}) || filter.Type == filterType) &&
+			(filterMatch == (models.Match{}) || filter.Match == filterMatch) {
+			found = append(found, filter)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, models.ErrFilterNotFound
+	case 1:
+		return &found[0], nil
+	default:
+		return nil, models.ErrFilterAmbiguous
+	}
+}
+
+func (r *fakeProjectFilterRepo) index(filter *models.Filter) int {
+	return slices.IndexFunc(r.filters, func(stored models.Filter) bool {
+		return stored.ProjectToken == filter.ProjectToken && stored.Same(filter.Domain)
+	})
+}
+
+func (r *fakeProjectFilterRepo) Update(_ context.Context, filter *models.Filter) error {
+	i := r.index(filter)
+	if i < 0 {
+		return models.ErrFilterNotFound
+	}
+	r.filters[i] = *filter
+	return nil
+}
+
+func (r *fakeProjectFilterRepo) Delete(_ context.Context, filter *models.Filter) error {
+	i := r.index(filter)
+	if i < 0 {
+		return models.ErrFilterNotFound
+	}
+	r.filters = slices.Delete(r.filters, i, i+1)
+	return nil
+}
+
+func TestManageFiltersSharingName(t *testing.T) {
+	owner := uuid.New()
+	newUsecase := func() (*ManageUsecase, *fakeProjectFilterRepo) {
+		filterRepo := &fakeProjectFilterRepo{filters: []models.Filter{
+			{ProjectToken: "token", Domain: models.Domain{Name: "spam.com", Type: models.BlacklistType, Match: models.EqualsMatch}},
+			{ProjectToken: "token", Domain: models.Domain{Name: "spam.com", Type: models.WhitelistType, Match: models.SuffixMatch}},
+		}}
+		accessRepo := &fakeAccessRepo{access: models.Access{Token: "token", CustomerUUID: owner}}
+		return NewManageUsecase(accessRepo, nil, filterRepo, nil, &fakeAuditRepo{}, fakeTransactor{}, nil, time.Minute, 1, 1), filterRepo
+	}
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("without type and coverage", func(t *testing.T) {
+		mu, filterRepo := newUsecase()
+		selector := models.DomainSelector{Name: "spam.com"}
+		if err := mu.DeleteFilter(ctx, owner, models.CustomerRole, "token", selector); !errors.Is(err, models.ErrFilterAmbiguous) {
+			t.Fatalf("delete: got %v, want %v", err, models.ErrFilterAmbiguous)
+		}
+		if _, err := mu.SetFilterExpiry(ctx, owner, models.CustomerRole, "token", selector, &expiresAt); !errors.Is(err, models.ErrFilterAmbiguous) {
+			t.Fatalf("expiry: got %v, want %v", err, models.ErrFilterAmbiguous)
+		}
+		if len(filterRepo.filters) != 2 {
+			t.Fatalf("got %d filters, want both kept", len(filterRepo.filters))
+		}
+	})
+
+	t.Run("with type and coverage", func(t *testing.T) {
+		mu, filterRepo := newUsecase()
+		selector := models.DomainSelector{Name: "spam.com", Type: models.WhitelistType.String(), Match: models.SuffixMatch.String()}
+		filter, err := mu.SetFilterExpiry(ctx, owner, models.CustomerRole, "token", selector, &expiresAt)
+		if err != nil {
+			t.Fatalf("expiry: %v", err)
+		}
+		if filter.Type != models.WhitelistType || filterRepo.filters[1].ExpiresAt == nil || filterRepo.filters[0].ExpiresAt != nil {
+			t.Fatalf("got the expiry set on %+v, want it on the whitelist filter only", filterRepo.filters)
+		}
+		if err := mu.DeleteFilter(ctx, owner, models.CustomerRole, "token", selector); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if len(filterRepo.filters) != 1 || filterRepo.filters[0].Type != models.BlacklistType {
+			t.Fatalf("got %+v, want the blacklist filter kept", filterRepo.filters)
+		}
+	})
+}
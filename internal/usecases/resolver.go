@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"golang.org/x/sync/errgroup"
+	"sort"
+)
+
+type matchFunc func(ctx context.Context, domainName string) ([]models.Domain, error)
+
+// matchRules expects the match functions in the order of precedence, a tier with at least one candidate wins over the next ones.
+func matchRules(ctx context.Context, domainName string, tieBreak models.TieBreak, matchFuncs ...matchFunc) (*models.Domain, error) {
+	tiers, err := collectRules(ctx, domainName, matchFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	return resolveTiers(tiers, tieBreak), nil
+}
+
+func collectRules(ctx context.Context, domainName string, matchFuncs ...matchFunc) ([][]models.Domain, error) {
+	tiers := make([][]models.Domain, len(matchFuncs))
+	group, ctx := errgroup.WithContext(ctx)
+	for idx, f := range matchFuncs {
+		group.Go(func() error {
+			rules, err := f(ctx, domainName)
+			if err != nil {
+				return err
+			}
+			tiers[idx] = rules
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+func resolveTiers(tiers [][]models.Domain, tieBreak models.TieBreak) *models.Domain {
+	for _, rules := range tiers {
+		if rule := resolveRules(rules, tieBreak); rule != nil {
+			return rule
+		}
+	}
+	return nil
+}
+
+func resolveRules(rules []models.Domain, tieBreak models.TieBreak) *models.Domain {
+	longest := longestRules(sortRules(rules))
+	if len(longest) == 0 {
+		return nil
+	}
+	if hasTypeConflict(longest) {
+		return firstOfType(longest, tieBreak.Type())
+	}
+	return &longest[0]
+}
+
+func sortRules(rules []models.Domain) []models.Domain {
+	sorted := make([]models.Domain, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if len(sorted[i].Name) != len(sorted[j].Name) {
+			return len(sorted[i].Name) > len(sorted[j].Name)
+		}
+		if typeRank(sorted[i].Type) != typeRank(sorted[j].Type) {
+			return typeRank(sorted[i].Type) < typeRank(sorted[j].Type)
+		}
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Match.String() < sorted[j].Match.String()
+	})
+	return sorted
+}
+
+// typeRank puts the rejecting types first, a tie the policy type is not part of falls back to the first rule.
+func typeRank(domainType models.Type) int {
+	switch domainType {
+	case models.BlacklistType:
+		return 0
+	case models.DisposableType:
+		return 1
+	case models.WhitelistType:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func longestRules(sorted []models.Domain) []models.Domain {
+	if len(sorted) == 0 {
+		return nil
+	}
+	longest := sorted[:1]
+	for _, rule := range sorted[1:] {
+		if len(rule.Name) != len(longest[0].Name) {
+			break
+		}
+		longest = append(longest, rule)
+	}
+	return longest
+}
+
+func hasTypeConflict(rules []models.Domain) bool {
+	for _, rule := range rules {
+		if rule.Type != rules[0].Type {
+			return true
+		}
+	}
+	return false
+}
+
+func firstOfType(rules []models.Domain, domainType models.Type) *models.Domain {
+	for i := range rules {
+		if rules[i].Type == domainType {
+			return &rules[i]
+		}
+	}
+	return &rules[0]
+}
+
+func filtersToDomains(filters []models.Filter) []models.Domain {
+	domains := make([]models.Domain, 0, len(filters))
+	for _, filter := range filters {
+		domains = append(domains, filter.Domain)
+	}
+	return domains
+}
@@ -0,0 +1,177 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"testing"
+)
+
+var tierMatches = []models.Match{
+	models.EqualsMatch,
+	models.SuffixMatch,
+	models.RegistrableMatch,
+	models.PrefixMatch,
+	models.ContainsMatch,
+	models.GlobMatch,
+	models.RegexMatch,
+}
+
+func rule(name string, domainType models.Type, match models.Match) models.Domain {
+	return models.Domain{Name: name, Type: domainType, Match: match}
+}
+
+func staticMatch(rules ...models.Domain) matchFunc {
+	return func(context.Context, string) ([]models.Domain, error) {
+		return rules, nil
+	}
+}
+
+func TestMatchRulesTierPrecedence(t *testing.T) {
+	for hi := range tierMatches {
+		for lo := hi + 1; lo < len(tierMatches); lo++ {
+			t.Run(fmt.Sprintf("%s over %s", tierMatches[hi], tierMatches[lo]), func(t *testing.T) {
+				winner := rule("mail.com", models.WhitelistType, tierMatches[hi])
+				loser := rule("longer.mail.com", models.BlacklistType, tierMatches[lo])
+				matchFuncs := make([]matchFunc, len(tierMatches))
+				for idx := range matchFuncs {
+					matchFuncs[idx] = staticMatch()
+				}
+				matchFuncs[hi] = staticMatch(winner)
+				matchFuncs[lo] = staticMatch(loser)
+
+				got, err := matchRules(context.Background(), "longer.mail.com", models.BlacklistTieBreak, matchFuncs...)
+				if err != nil {
+					t.Fatalf("matchRules: %v", err)
+				}
+				if got == nil || *got != winner {
+					t.Fatalf("got %+v, want %+v", got, winner)
+				}
+			})
+		}
+	}
+}
+
+func TestResolveRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []models.Domain
+		tieBreak models.TieBreak
+		want     *models.Domain
+	}{
+		{
+			name:     "no rules",
+			tieBreak: models.BlacklistTieBreak,
+		},
+		{
+			name: "longest name wins",
+			rules: []models.Domain{
+				rule("mail.com", models.BlacklistType, models.SuffixMatch),
+				rule("eu.mail.com", models.WhitelistType, models.SuffixMatch),
+				rule("com", models.BlacklistType, models.SuffixMatch),
+			},
+			tieBreak: models.BlacklistTieBreak,
+			want:     &models.Domain{Name: "eu.mail.com", Type: models.WhitelistType, Match: models.SuffixMatch},
+		},
+		{
+			name: "same type tie picks the first name",
+			rules: []models.Domain{
+				rule("bbb.com", models.BlacklistType, models.ContainsMatch),
+				rule("aaa.com", models.BlacklistType, models.ContainsMatch),
+			},
+			tieBreak: models.WhitelistTieBreak,
+			want:     &models.Domain{Name: "aaa.com", Type: models.BlacklistType, Match: models.ContainsMatch},
+		},
+		{
+			name: "blacklist tie-break",
+			rules: []models.Domain{
+				rule("mail.com", models.WhitelistType, models.SuffixMatch),
+				rule("mail.com", models.BlacklistType, models.SuffixMatch),
+			},
+			tieBreak: models.BlacklistTieBreak,
+			want:     &models.Domain{Name: "mail.com", Type: models.BlacklistType, Match: models.SuffixMatch},
+		},
+		{
+			name: "whitelist tie-break",
+			rules: []models.Domain{
+				rule("mail.com", models.BlacklistType, models.SuffixMatch),
+				rule("mail.com", models.WhitelistType, models.SuffixMatch),
+			},
+			tieBreak: models.WhitelistTieBreak,
+			want:     &models.Domain{Name: "mail.com", Type: models.WhitelistType, Match: models.SuffixMatch},
+		},
+		{
+			name: "whitelist tie-break between names of the same length",
+			rules: []models.Domain{
+				rule("aaa.com", models.BlacklistType, models.ContainsMatch),
+				rule("bbb.com", models.WhitelistType, models.ContainsMatch),
+			},
+			tieBreak: models.WhitelistTieBreak,
+			want:     &models.Domain{Name: "bbb.com", Type: models.WhitelistType, Match: models.ContainsMatch},
+		},
+		{
+			name: "disposable against whitelist under blacklist tie-break",
+			rules: []models.Domain{
+				rule("mail.com", models.WhitelistType, models.SuffixMatch),
+				rule("mail.com", models.DisposableType, models.SuffixMatch),
+			},
+			tieBreak: models.BlacklistTieBreak,
+			want:     &models.Domain{Name: "mail.com", Type: models.DisposableType, Match: models.SuffixMatch},
+		},
+		{
+			name: "disposable against whitelist in reverse order",
+			rules: []models.Domain{
+				rule("mail.com", models.DisposableType, models.SuffixMatch),
+				rule("mail.com", models.WhitelistType, models.SuffixMatch),
+			},
+			tieBreak: models.BlacklistTieBreak,
+			want:     &models.Domain{Name: "mail.com", Type: models.DisposableType, Match: models.SuffixMatch},
+		},
+		{
+			name: "disposable against whitelist of another name",
+			rules: []models.Domain{
+				rule("aaa.com", models.WhitelistType, models.ContainsMatch),
+				rule("bbb.com", models.DisposableType, models.ContainsMatch),
+			},
+			tieBreak: models.BlacklistTieBreak,
+			want:     &models.Domain{Name: "bbb.com", Type: models.DisposableType, Match: models.ContainsMatch},
+		},
+		{
+			name: "disposable against blacklist under whitelist tie-break",
+			rules: []models.Domain{
+				rule("mail.com", models.DisposableType, models.SuffixMatch),
+				rule("mail.com", models.BlacklistType, models.SuffixMatch),
+			},
+			tieBreak: models.WhitelistTieBreak,
+			want:     &models.Domain{Name: "mail.com", Type: models.BlacklistType, Match: models.SuffixMatch},
+		},
+		{
+			name: "whitelist tie-break wins over disposable",
+			rules: []models.Domain{
+				rule("mail.com", models.DisposableType, models.SuffixMatch),
+				rule("mail.com", models.WhitelistType, models.SuffixMatch),
+			},
+			tieBreak: models.WhitelistTieBreak,
+			want:     &models.Domain{Name: "mail.com", Type: models.WhitelistType, Match: models.SuffixMatch},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveRules(tt.rules, tt.tieBreak)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Fatalf("got %+v, want no rule", got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTiersSkipsEmptyTiers(t *testing.T) {
+	want := rule("mail.com", models.BlacklistType, models.PrefixMatch)
+	tiers := [][]models.Domain{nil, {}, {want}, {rule("mail.com.evil", models.WhitelistType, models.ContainsMatch)}}
+	if got := resolveTiers(tiers, models.WhitelistTieBreak); got == nil || *got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
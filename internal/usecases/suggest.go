@@ -0,0 +1,145 @@
+package usecases
+
+import (
+	"context"
+	"golang.org/x/net/publicsuffix"
+	"strings"
+)
+
+const (
+	maxSuggestionDistance  = 2.0
+	minDistanceLengthRatio = 5.0
+	adjacentKeyCost        = 0.5
+	transpositionCost      = 0.75
+)
+
+var popularProviders = []string{
+	"gmail.com", "yahoo.com", "hotmail.com", "outlook.com", "icloud.com", "aol.com", "live.com", "msn.com",
+	"proton.me", "protonmail.com", "gmx.com", "gmx.de", "web.de", "mail.ru", "yandex.ru", "zoho.com",
+	"comcast.net", "verizon.net", "att.net", "qq.com", "163.com", "naver.com", "ukr.net",
+	"yahoo.co.uk", "hotmail.co.uk", "btinternet.com", "hotmail.fr", "orange.fr", "libero.it",
+}
+
+var keyboardRows = []string{"1234567890-", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+var adjacentKeys = func() map[byte]string {
+	adjacent := make(map[byte]string)
+	for row, keys := range keyboardRows {
+		for col := 0; col < len(keys); col++ {
+			var neighbours []byte
+			for _, r := range []int{row - 1, row, row + 1} {
+				if r < 0 || r >= len(keyboardRows) {
+					continue
+				}
+				for _, c := range []int{col - 1, col, col + 1} {
+					if c >= 0 && c < len(keyboardRows[r]) && !(r == row && c == col) {
+						neighbours = append(neighbours, keyboardRows[r][c])
+					}
+				}
+			}
+			adjacent[keys[col]] = string(neighbours)
+		}
+	}
+	return adjacent
+}()
+
+// suggestDomain gives no suggestion for a domain that is a candidate itself,
+// nor a candidate that only differs in a public suffix the domain really has, e.g. hotmail.fr for hotmail.de.
+func (i *InspectUsecase) suggestDomain(ctx context.Context, domainName string) (string, error) {
+	whitelist, err := i.domainRepo.FindSuggestionCandidates(ctx)
+	if err != nil {
+		return "", err
+	}
+	suffix, icann := publicsuffix.PublicSuffix(domainName)
+	label := strings.TrimSuffix(domainName, "."+suffix)
+
+	maxDistance := min(maxSuggestionDistance, float64(len(domainName))/minDistanceLengthRatio)
+	suggestion, bestDistance := "", maxDistance
+	for _, candidates := range [][]string{popularProviders, whitelist} {
+		for _, candidate := range candidates {
+			if candidate == domainName {
+				return "", nil
+			}
+			if abs(len(candidate)-len(domainName)) > int(maxDistance) {
+				continue
+			}
+			if icann && sameLabel(candidate, label) {
+				continue
+			}
+			if letterDistance(domainName, candidate) > bestDistance {
+				continue
+			}
+			if distance := typoDistance(domainName, candidate, bestDistance); distance <= bestDistance && (suggestion == "" || distance < bestDistance) {
+				suggestion, bestDistance = candidate, distance
+			}
+		}
+	}
+	return suggestion, nil
+}
+
+// typoDistance is the optimal string alignment distance with cheaper substitutions of adjacent keys and cheaper transpositions.
+// It stops once the distance exceeds bound and then returns a distance over bound.
+func typoDistance(a, b string, bound float64) float64 {
+	previous2, previous, current := make([]float64, len(b)+1), make([]float64, len(b)+1), make([]float64, len(b)+1)
+	for j := range previous {
+		previous[j] = float64(j)
+	}
+	previousMin := 0.0
+	for i := 1; i <= len(a); i++ {
+		current[0] = float64(i)
+		rowMin := current[0]
+		for j := 1; j <= len(b); j++ {
+			substitution := 0.0
+			if a[i-1] != b[j-1] {
+				substitution = 1
+				if strings.IndexByte(adjacentKeys[a[i-1]], b[j-1]) >= 0 {
+					substitution = adjacentKeyCost
+				}
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+substitution)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previous2[j-2]+transpositionCost)
+			}
+			rowMin = min(rowMin, current[j])
+		}
+		// A cell only builds on the two rows above it, so the distance cannot come back under bound.
+		if rowMin > bound && previousMin > bound {
+			return rowMin
+		}
+		previousMin = rowMin
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(b)]
+}
+
+// letterDistance is a lower bound of typoDistance, an edit costs adjacentKeyCost at least and leaves at most one letter fewer missing on each side.
+func letterDistance(a, b string) float64 {
+	var counts [256]int
+	for i := 0; i < len(a); i++ {
+		counts[a[i]]++
+	}
+	for i := 0; i < len(b); i++ {
+		counts[b[i]]--
+	}
+	missingA, missingB := 0, 0
+	for _, count := range counts {
+		if count > 0 {
+			missingB += count
+		} else {
+			missingA -= count
+		}
+	}
+	return float64(max(missingA, missingB)) * adjacentKeyCost
+}
+
+func sameLabel(domainName, label string) bool {
+	suffix, _ := publicsuffix.PublicSuffix(domainName)
+	return domainName == label+"."+suffix
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
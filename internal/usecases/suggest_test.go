@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"math"
+	"testing"
+)
+
+func TestInspectDataSuggestions(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "gmial.co", Type: models.WhitelistType, Match: models.EqualsMatch},
+		{Name: "gmali.com", Type: models.BlacklistType, Match: models.EqualsMatch},
+	}}
+	dnsResolver := fakeDNSResolver{
+		"hotmail.de": models.MXRouteStatus,
+		"hotmail.es": models.NotExistRouteStatus,
+		"gmial.com":  models.NotExistRouteStatus,
+		"gmaill.com": models.MXRouteStatus,
+		"yahooo.com": models.NoRouteStatus,
+	}
+	usecase := newTestInspectUsecase(domainRepo, dnsResolver)
+
+	tests := []struct {
+		data       string
+		wantErr    error
+		wantDomain string
+	}{
+		{"user@hotmail.de", nil, ""},
+		{"user@hotmail.es", models.ErrDomainNotExist, ""},
+		{"user@gmial.com", models.ErrDomainNotExist, "gmail.com"},
+		{"user@gmial.co", nil, ""},
+		{"user@gmali.com", nil, ""},
+		{"user@gmaill.com", nil, "gmail.com"},
+		{"user@yahooo.com", models.ErrDomainNoMail, "yahoo.com"},
+		{"user@hotmail.con", models.ErrDomainNotExist, "hotmail.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			result, err := usecase.InspectData(context.Background(), tt.data, "", "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			var suggestionErr models.SuggestionError
+			errors.As(err, &suggestionErr)
+			suggested := suggestionErr.SuggestedDomain
+			if result != nil {
+				suggested = result.SuggestedDomain
+			}
+			if suggested != tt.wantDomain {
+				t.Fatalf("got suggestion %q, want %q", suggested, tt.wantDomain)
+			}
+		})
+	}
+}
+
+func TestInspectDataSuggestionsWithoutDNS(t *testing.T) {
+	domainRepo := &fakeDomainRepo{rules: []models.Domain{
+		{Name: "gmali.com", Type: models.BlacklistType, Match: models.EqualsMatch},
+	}}
+	usecase := newTestInspectUsecase(domainRepo, nil)
+
+	tests := []struct {
+		data        string
+		wantDomain  string
+		wantAddress string
+	}{
+		{"user@gmial.com", "gmail.com", "user@gmail.com"},
+		{"gmial.com", "gmail.com", ""},
+		{"user@gmali.com", "", ""},
+		{"user@gmail.com", "", ""},
+		{"user@hotmail.de", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			result, err := usecase.InspectData(context.Background(), tt.data, "", "")
+			if err != nil {
+				t.Fatalf("InspectData: %v", err)
+			}
+			if result.SuggestedDomain != tt.wantDomain || result.SuggestedAddress != tt.wantAddress {
+				t.Fatalf("got suggestion %q, %q, want %q, %q", result.SuggestedDomain, result.SuggestedAddress, tt.wantDomain, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestTypoDistanceBounds(t *testing.T) {
+	pairs := [][2]string{
+		{"gmial.com", "gmail.com"}, {"gmaill.com", "gmail.com"}, {"hotmail.con", "hotmail.com"},
+		{"yahooo.com", "yahoo.com"}, {"hmail.com", "gmail.com"}, {"example.org", "gmail.com"}, {"", "aol.com"},
+	}
+	for _, pair := range pairs {
+		distance := typoDistance(pair[0], pair[1], math.Inf(1))
+		if lower := letterDistance(pair[0], pair[1]); lower > distance {
+			t.Fatalf("%s, %s: letter distance %v over the distance %v", pair[0], pair[1], lower, distance)
+		}
+		for _, bound := range []float64{0, 0.5, 1, maxSuggestionDistance} {
+			bounded := typoDistance(pair[0], pair[1], bound)
+			if distance <= bound && bounded != distance || distance > bound && bounded <= bound {
+				t.Fatalf("%s, %s within %v: got %v, want %v", pair[0], pair[1], bound, bounded, distance)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"context"
+	"github.com/aerosystems/checkmail-service/internal/models"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+func (mu ManageUsecase) ListDeletedDomains(ctx context.Context, cursor string, limit int) (*models.DomainPage, error) {
+	query := models.DomainQuery{Sort: models.DeletedAtSort, Descending: true, Limit: limit}
+	if query.Limit <= 0 {
+		query.Limit = models.DefaultDomainPageSize
+	}
+	query.Limit = min(query.Limit, models.MaxDomainPageSize)
+	if cursor != "" {
+		var err error
+		if query.Cursor, err = models.DecodeDomainCursor(cursor, query.Sort, query.Descending); err != nil {
+			return nil, err
+		}
+	}
+	return mu.domainRepo.SearchDeleted(ctx, query)
+}
+
+// RestoreDomain fails when the same rule was created again since it was deleted,
+// an empty domainType or domainCoverage restores the most recently deleted rule with the name.
+func (mu ManageUsecase) RestoreDomain(ctx context.Context, domainName, domainType, domainCoverage string) (*models.Domain, error) {
+	restoreType, restoreMatch, err := parseRuleFilter(domainType, domainCoverage)
+	if err != nil {
+		return nil, err
+	}
+	var domain *models.Domain
+	if err := mu.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		domain, err = findRule(domainName, models.ErrDomainNotFound, func(name string) (*models.Domain, error) {
+			return mu.domainRepo.Restore(ctx, name, restoreType, restoreMatch)
+		})
+		if err != nil {
+			return err
+		}
+		entry := models.NewAuditEntry(ctx, "", nil, domain)
+		entry.Action = models.RestoreAuditAction
+		return mu.recordAudit(ctx, entry)
+	}); err != nil {
+		return nil, err
+	}
+	mu.domainsChanged(ctx, *domain)
+	return domain, nil
+}
+
+func (mu ManageUsecase) PurgeDeletedDomains(ctx context.Context, before time.Time) (int, error) {
+	return mu.domainRepo.PurgeDeleted(ctx, before)
+}
+
+type TrashPurger struct {
+	log           *logrus.Logger
+	manageUsecase *ManageUsecase
+	retention     time.Duration
+	interval      time.Duration
+}
+
+func NewTrashPurger(log *logrus.Logger, manageUsecase *ManageUsecase, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		log:           log,
+		manageUsecase: manageUsecase,
+		retention:     retention,
+		interval:      interval,
+	}
+}
+
+func (p *TrashPurger) Run(ctx context.Context) error {
+	if p.interval <= 0 || p.retention <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			purged, err := p.manageUsecase.PurgeDeletedDomains(ctx, time.Now().Add(-p.retention))
+			if err != nil {
+				p.log.Errorf("could not purge deleted domains: %v", err)
+			}
+			if purged > 0 {
+				p.log.Infof("purged %d domains deleted more than %s ago", purged, p.retention)
+			}
+		}
+	}
+}